@@ -3,11 +3,19 @@ package app
 import (
 	"context"
 	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"personal-budgeting/be/internal/blob"
 	"personal-budgeting/be/internal/clock"
 	"personal-budgeting/be/internal/db"
 	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/handlers"
 	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/money"
 	"personal-budgeting/be/internal/repositories"
 	"personal-budgeting/be/internal/router"
 	"personal-budgeting/be/internal/services"
@@ -17,6 +25,7 @@ import (
 func New() *router.App {
 	clk := clock.Real{}
 	ids := id.RandomHex{}
+	home := homeCurrencyFromEnv()
 
 	pgCfg := db.LoadPostgresConfigFromEnv()
 	if pgCfg.Enabled() {
@@ -31,20 +40,70 @@ func New() *router.App {
 			if err := dbmodel.AutoMigrate(gdb); err != nil {
 				log.Fatalf("postgres automigrate error: %v", err)
 			} else {
-				catRepo := repositories.NewGormCategoryRepo(gdb)
-				budgetRepo := repositories.NewGormBudgetRepo(gdb)
-				txnRepo := repositories.NewGormTxnRepo(gdb)
+				txManager := repositories.NewGormTransactor(gdb)
+				auditSvc := services.NewAuditService(clk, txManager, repositories.NewGormAuditRepo(gdb))
+				accountRepo := repositories.NewGormAccountRepo(gdb)
+				catRepo := auditSvc.Categories(repositories.NewGormCategoryRepo(gdb))
+				budgetRepo := auditSvc.Budgets(repositories.NewGormBudgetRepo(gdb))
+				txnRepo := auditSvc.Txns(repositories.NewGormTxnRepo(gdb))
+				reportRepo := repositories.NewGormReportRepo(gdb)
+				rateRepo := repositories.NewGormRateRepo(gdb)
+				recurringRepo := repositories.NewGormRecurringRuleRepo(gdb)
+				tagRepo := repositories.NewGormTagRepo(gdb)
+				attachmentRepo := repositories.NewGormAttachmentRepo(gdb)
+				ruleRepo := repositories.NewGormRuleRepo(gdb)
+				payeeRepo := repositories.NewGormPayeeRepo(gdb)
+				userRepo := repositories.NewGormUserRepo(gdb)
+				sessionRepo := repositories.NewGormSessionRepo(gdb)
 
+				authSvc := services.NewAuthService(clk, ids, txManager, userRepo, sessionRepo)
+
+				accountSvc := services.NewAccountService(clk, ids, accountRepo, txnRepo)
 				categorySvc := services.NewCategoryService(clk, ids, catRepo)
-				budgetSvc := services.NewBudgetService(clk, ids, budgetRepo)
-				txnSvc := services.NewTxnService(clk, ids, txnRepo)
-				stateSvc := services.NewStateService(catRepo, budgetRepo, txnRepo)
+				budgetSvc := services.NewBudgetService(clk, ids, txManager, budgetRepo)
+				txnSvc := services.NewTxnService(clk, ids, txManager, txnRepo)
+				txnSvc.OnNewMonth(budgetSvc.ApplyAutoTemplates)
+				transferSvc := services.NewTransferService(clk, ids, txManager, txnRepo)
+				stateSvc := services.NewStateService(txManager, accountRepo, catRepo, budgetRepo, txnRepo, recurringRepo, tagRepo, payeeRepo)
+				recurringSvc := services.NewRecurringService(clk, ids, txManager, recurringRepo, txnSvc)
+				tagSvc := services.NewTagService(clk, ids, tagRepo)
+				ruleSvc := services.NewRuleService(clk, ids, txManager, ruleRepo, catRepo, tagRepo, txnRepo)
+				payeeSvc := services.NewPayeeService(clk, ids, txManager, payeeRepo, txnRepo)
+				blobs, err := blob.NewLocalFS(attachmentsDirFromEnv())
+				if err != nil {
+					log.Fatalf("attachments dir error: %v", err)
+				}
+				attachmentSvc := services.NewAttachmentService(clk, ids, attachmentRepo, blobs)
+				trashSvc := services.NewTrashService(clk, trashRetentionFromEnv(), txManager, catRepo, budgetRepo, txnRepo, attachmentSvc)
+				rateSvc := services.NewRateService(clk, ids, rateRepo)
+				reportSvc := services.NewReportService(reportRepo, catRepo, home)
+				importSvc := services.NewImportService(txManager, catRepo, txnSvc, ruleSvc, payeeSvc)
+				exportSvc := services.NewExportService(catRepo, txnRepo)
+
+				go recurringSvc.Run(context.Background(), recurringIntervalFromEnv())
+				go trashSvc.Run(context.Background(), time.Hour)
 
 				return router.New(router.Deps{
-					Category:    categorySvc,
-					Budget:      budgetSvc,
-					Transaction: txnSvc,
-					State:       stateSvc,
+					HomeCurrency:       home,
+					AttachmentMaxBytes: attachmentMaxBytesFromEnv(),
+					Auth:               authSvc,
+					Account:            accountSvc,
+					Category:           categorySvc,
+					Budget:             budgetSvc,
+					Transaction:        txnSvc,
+					Transfer:           transferSvc,
+					Recurring:          recurringSvc,
+					Tag:                tagSvc,
+					Rule:               ruleSvc,
+					Payee:              payeeSvc,
+					Attachment:         attachmentSvc,
+					Trash:              trashSvc,
+					Audit:              auditSvc,
+					State:              stateSvc,
+					Rate:               rateSvc,
+					Report:             reportSvc,
+					Import:             importSvc,
+					Export:             exportSvc,
 				})
 			}
 		}
@@ -53,3 +112,65 @@ func New() *router.App {
 	log.Fatal("DATABASE_URL (or PG* env vars) is required; memory repo removed")
 	return nil
 }
+
+// homeCurrencyFromEnv reads HOME_CURRENCY, the currency reports convert into.
+func homeCurrencyFromEnv() string {
+	v := strings.ToUpper(strings.TrimSpace(os.Getenv("HOME_CURRENCY")))
+	if v == "" {
+		return models.DefaultCurrency
+	}
+	if _, ok := money.LookupCurrency(v); !ok {
+		log.Fatalf("HOME_CURRENCY %q is not a supported currency", v)
+	}
+	return v
+}
+
+// recurringIntervalFromEnv reads RECURRING_INTERVAL (a Go duration such as "15m"),
+// how often due recurring transactions are materialized.
+func recurringIntervalFromEnv() time.Duration {
+	v := strings.TrimSpace(os.Getenv("RECURRING_INTERVAL"))
+	if v == "" {
+		return time.Hour
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("RECURRING_INTERVAL %q is not a positive duration", v)
+	}
+	return d
+}
+
+// trashRetentionFromEnv reads TRASH_RETENTION (a Go duration such as "168h"), how
+// long deleted transactions, categories and budgets can be restored before they
+// are purged.
+func trashRetentionFromEnv() time.Duration {
+	v := strings.TrimSpace(os.Getenv("TRASH_RETENTION"))
+	if v == "" {
+		return services.DefaultTrashRetention
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("TRASH_RETENTION %q is not a positive duration", v)
+	}
+	return d
+}
+
+// attachmentsDirFromEnv reads ATTACHMENTS_DIR, where uploaded attachments are stored.
+func attachmentsDirFromEnv() string {
+	if v := strings.TrimSpace(os.Getenv("ATTACHMENTS_DIR")); v != "" {
+		return v
+	}
+	return "data/attachments"
+}
+
+// attachmentMaxBytesFromEnv reads ATTACHMENT_MAX_BYTES, the largest accepted upload.
+func attachmentMaxBytesFromEnv() int64 {
+	v := strings.TrimSpace(os.Getenv("ATTACHMENT_MAX_BYTES"))
+	if v == "" {
+		return handlers.DefaultAttachmentMaxBytes
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Fatalf("ATTACHMENT_MAX_BYTES %q is not a positive number", v)
+	}
+	return n
+}
@@ -0,0 +1,34 @@
+// Package auth carries the authenticated user through a request's context.
+// Repositories scope every query to this user.
+package auth
+
+import "context"
+
+type ctxKey struct{}
+
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, userID)
+}
+
+// UserID returns the user bound to ctx, or "" when there is none.
+func UserID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+type actorKey struct{}
+
+// WithActor records who is acting in ctx when it is not the user the data
+// belongs to, such as a background job.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns who is acting in ctx: the actor set with WithActor, or else the
+// user.
+func Actor(ctx context.Context) string {
+	if a, ok := ctx.Value(actorKey{}).(string); ok && a != "" {
+		return a
+	}
+	return UserID(ctx)
+}
@@ -0,0 +1,103 @@
+// Package blob stores opaque files, such as receipt attachments, by key. Keys are
+// chosen by callers and must be plain names ([A-Za-z0-9_-]); the metadata that
+// gives a blob meaning lives in the database.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"personal-budgeting/be/internal/errs"
+)
+
+type Store interface {
+	// Put writes r under key, replacing any blob already there.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open returns errs.ErrNotFound for a missing key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete is a no-op for a missing key.
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalFS keeps each blob as a file in one directory.
+type LocalFS struct {
+	root string
+}
+
+// NewLocalFS creates root if needed.
+func NewLocalFS(root string) (*LocalFS, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalFS{root: root}, nil
+}
+
+var _ Store = (*LocalFS)(nil)
+
+// Put writes to a temporary file first so readers never see a partial blob.
+func (s *LocalFS) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op once renamed
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (s *LocalFS) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errs.ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalFS) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalFS) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", fmt.Errorf("blob: bad key %q", key)
+	}
+	return filepath.Join(s.root, key), nil
+}
+
+func validKey(key string) bool {
+	if key == "" || len(key) > 128 {
+		return false
+	}
+	for _, r := range key {
+		ok := r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -8,49 +8,292 @@ import (
 
 // These are the *database* models (GORM structs).
 // API models that match the frontend live in `internal/models`.
+//
+// Every user-owned table has a UserID column; repositories scope all queries by it.
+// Rows created before accounts existed have UserID "" until the first user claims them.
+
+type User struct {
+	ID           string `gorm:"primaryKey;type:text"`
+	Email        string `gorm:"type:text;not null;uniqueIndex"`
+	PasswordHash string `gorm:"type:text;not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (User) TableName() string { return "users" }
+
+// Session is a bearer token. Only the SHA-256 of the token is stored.
+type Session struct {
+	TokenHash string    `gorm:"primaryKey;type:text"`
+	UserID    string    `gorm:"type:text;not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+func (Session) TableName() string { return "sessions" }
 
 type Category struct {
-	ID          string `gorm:"primaryKey;type:text"`
-	Type        string `gorm:"type:text;not null"`
-	Name        string `gorm:"type:text;not null"`
-	Description string `gorm:"type:text;not null;default:''"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             string  `gorm:"primaryKey;type:text"`
+	UserID         string  `gorm:"type:text;not null;default:'';index"`
+	Type           string  `gorm:"type:text;not null"`
+	Name           string  `gorm:"type:text;not null"`
+	Description    string  `gorm:"type:text;not null;default:''"`
+	ParentID       *string `gorm:"type:text;index"` // no FK: PUT /state inserts categories in any order
+	RolloverPolicy string  `gorm:"type:text;not null;default:'none'"`
+	Version        int64   `gorm:"not null;default:1"` // bumped by every update
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"` // set while the category is in the trash
 }
 
 func (Category) TableName() string { return "categories" }
 
+type Account struct {
+	ID                  string `gorm:"primaryKey;type:text"`
+	UserID              string `gorm:"type:text;not null;default:'';index"`
+	Name                string `gorm:"type:text;not null"`
+	Type                string `gorm:"type:text;not null"`
+	OpeningBalanceCents int64  `gorm:"not null;default:0"`
+	Currency            string `gorm:"type:text;not null;default:'IDR'"`
+	Archived            bool   `gorm:"not null;default:false"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+func (Account) TableName() string { return "accounts" }
+
 type Budget struct {
-	ID         string `gorm:"primaryKey;type:text"`
-	Month      string `gorm:"type:text;not null;index:budgets_month_category_uq,unique"`
-	CategoryID string `gorm:"type:text;not null;index:budgets_month_category_uq,unique;index"`
-	AmountCents int64 `gorm:"not null"`
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID          string `gorm:"primaryKey;type:text"`
+	UserID      string `gorm:"type:text;not null;default:'';index"`
+	Month       string `gorm:"type:text;not null;index:budgets_month_category_uq,unique"`
+	CategoryID  string `gorm:"type:text;not null;index:budgets_month_category_uq,unique;index"`
+	AmountCents int64  `gorm:"not null"`
+	Currency    string `gorm:"type:text;not null;default:'IDR'"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"` // a trashed budget keeps its (month, category) slot
 
 	Category Category `gorm:"foreignKey:CategoryID;references:ID;constraint:OnDelete:RESTRICT"`
 }
 
 func (Budget) TableName() string { return "budgets" }
 
+// Composite indexes back the filtered/keyset listing in GormTxnRepo.Query.
 type Transaction struct {
-	ID         string `gorm:"primaryKey;type:text"`
-	Kind       string `gorm:"type:text;not null"`
-	Date       string `gorm:"type:text;not null;index"`
-	CategoryID string `gorm:"type:text;not null;index"`
-	AmountCents int64 `gorm:"not null"`
-	Note       string `gorm:"type:text;not null;default:''"`
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID          string  `gorm:"primaryKey;type:text;index:transactions_user_date_id_idx,priority:3;index:transactions_user_amount_id_idx,priority:3"`
+	UserID      string  `gorm:"type:text;not null;default:'';index:transactions_user_date_id_idx,priority:1;index:transactions_user_amount_id_idx,priority:1;uniqueIndex:transactions_user_external_id_uq,priority:1"`
+	Kind        string  `gorm:"type:text;not null;index:transactions_kind_date_idx,priority:1"`
+	Date        string  `gorm:"type:text;not null;index;index:transactions_user_date_id_idx,priority:2;index:transactions_kind_date_idx,priority:2;index:transactions_category_date_idx,priority:2;index:transactions_account_date_idx,priority:2"`
+	CategoryID  *string `gorm:"type:text;index;index:transactions_category_date_idx,priority:1"` // NULL for transfer legs
+	AccountID   *string `gorm:"type:text;index:transactions_account_date_idx,priority:1"`
+	PayeeID     *string `gorm:"type:text;index"`
+	AmountCents int64   `gorm:"not null;index:transactions_user_amount_id_idx,priority:2"`
+	Currency    string  `gorm:"type:text;not null;default:'IDR'"`
+	Note        string  `gorm:"type:text;not null;default:''"`
+	ExternalID  *string `gorm:"type:text;uniqueIndex:transactions_user_external_id_uq,priority:2"`
+	TransferID  *string `gorm:"type:text;index"`
+	TransferDir string  `gorm:"column:transfer_direction;type:text;not null;default:''"`
+	Version     int64   `gorm:"not null;default:1"` // bumped by every update
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
 
 	Category Category `gorm:"foreignKey:CategoryID;references:ID;constraint:OnDelete:RESTRICT"`
+	Account  *Account `gorm:"foreignKey:AccountID;references:ID;constraint:OnDelete:RESTRICT"`
+	Payee    *Payee   `gorm:"foreignKey:PayeeID;references:ID;constraint:OnDelete:SET NULL"`
 }
 
 func (Transaction) TableName() string { return "transactions" }
 
+// ExchangeRate rows are per user and unique per (base, quote, date).
+type ExchangeRate struct {
+	ID        string  `gorm:"primaryKey;type:text"`
+	UserID    string  `gorm:"type:text;not null;default:'';uniqueIndex:exchange_rates_pair_date_uq,priority:1"`
+	Base      string  `gorm:"type:text;not null;uniqueIndex:exchange_rates_pair_date_uq,priority:2"`
+	Quote     string  `gorm:"type:text;not null;uniqueIndex:exchange_rates_pair_date_uq,priority:3"`
+	Date      string  `gorm:"type:text;not null;uniqueIndex:exchange_rates_pair_date_uq,priority:4"`
+	Rate      float64 `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (ExchangeRate) TableName() string { return "exchange_rates" }
+
+// RecurringRule has no foreign keys: PUT /state deletes and re-inserts the
+// categories and accounts underneath it, so state validation guards the
+// references instead.
+type RecurringRule struct {
+	ID           string  `gorm:"primaryKey;type:text"`
+	UserID       string  `gorm:"type:text;not null;default:'';index"`
+	Name         string  `gorm:"type:text;not null"`
+	Kind         string  `gorm:"type:text;not null"`
+	CategoryID   string  `gorm:"type:text;not null;index"`
+	AccountID    *string `gorm:"type:text;index"`
+	AmountCents  int64   `gorm:"not null"`
+	Currency     string  `gorm:"type:text;not null;default:'IDR'"`
+	Note         string  `gorm:"type:text;not null;default:''"`
+	Frequency    string  `gorm:"type:text;not null"`
+	DayOfMonth   int     `gorm:"not null;default:0"`
+	IntervalDays int     `gorm:"not null;default:0"`
+	StartDate    string  `gorm:"type:text;not null"`
+	EndDate      string  `gorm:"type:text;not null;default:''"`
+	Paused       bool    `gorm:"not null;default:false"`
+	LastRunDate  string  `gorm:"type:text;not null;default:''"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (RecurringRule) TableName() string { return "recurring_rules" }
+
+// TxnSplit is one category line of a split transaction, in Position order.
+type TxnSplit struct {
+	TxnID       string `gorm:"primaryKey;type:text"`
+	Position    int    `gorm:"primaryKey"`
+	CategoryID  string `gorm:"type:text;not null;index"`
+	AmountCents int64  `gorm:"not null"`
+	Note        string `gorm:"type:text;not null;default:''"`
+
+	Txn      Transaction `gorm:"foreignKey:TxnID;references:ID;constraint:OnDelete:CASCADE"`
+	Category Category    `gorm:"foreignKey:CategoryID;references:ID;constraint:OnDelete:RESTRICT"`
+}
+
+func (TxnSplit) TableName() string { return "txn_splits" }
+
+type Tag struct {
+	ID        string `gorm:"primaryKey;type:text"`
+	UserID    string `gorm:"type:text;not null;default:'';uniqueIndex:tags_user_name_uq,priority:1"`
+	Name      string `gorm:"type:text;not null;uniqueIndex:tags_user_name_uq,priority:2"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (Tag) TableName() string { return "tags" }
+
+// Payee keeps its normalized aliases as a JSON array; PayeeService keeps them,
+// and normalized names, unique per user. Deleting a payee clears it from
+// transactions.
+type Payee struct {
+	ID        string   `gorm:"primaryKey;type:text"`
+	UserID    string   `gorm:"type:text;not null;default:'';index"`
+	Name      string   `gorm:"type:text;not null"`
+	Aliases   []string `gorm:"type:text;not null;serializer:json"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (Payee) TableName() string { return "payees" }
+
+// TxnTag joins transactions and tags; deleting either side removes the link.
+type TxnTag struct {
+	TxnID string `gorm:"primaryKey;type:text"`
+	TagID string `gorm:"primaryKey;type:text;index"`
+
+	Txn Transaction `gorm:"foreignKey:TxnID;references:ID;constraint:OnDelete:CASCADE"`
+	Tag Tag         `gorm:"foreignKey:TagID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+func (TxnTag) TableName() string { return "txn_tags" }
+
+// BudgetTemplate is a named set of budget lines that can be applied to any month.
+type BudgetTemplate struct {
+	ID        string `gorm:"primaryKey;type:text"`
+	UserID    string `gorm:"type:text;not null;default:'';uniqueIndex:budget_templates_user_name_uq,priority:1"`
+	Name      string `gorm:"type:text;not null;uniqueIndex:budget_templates_user_name_uq,priority:2"`
+	AutoApply bool   `gorm:"not null;default:false"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Lines []BudgetTemplateLine `gorm:"foreignKey:TemplateID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
+func (BudgetTemplate) TableName() string { return "budget_templates" }
+
+// BudgetTemplateLine has no foreign key to categories, for the same reason as
+// RecurringRule.
+type BudgetTemplateLine struct {
+	TemplateID  string `gorm:"primaryKey;type:text"`
+	CategoryID  string `gorm:"primaryKey;type:text;index"`
+	AmountCents int64  `gorm:"not null"`
+	Currency    string `gorm:"type:text;not null;default:'IDR'"`
+}
+
+func (BudgetTemplateLine) TableName() string { return "budget_template_lines" }
+
+// Rule has no foreign keys, like RecurringRule; actions referring to a category
+// or tag that is gone are skipped.
+type Rule struct {
+	ID             string `gorm:"primaryKey;type:text"`
+	UserID         string `gorm:"type:text;not null;default:'';index"`
+	Name           string `gorm:"type:text;not null"`
+	Priority       int    `gorm:"not null;default:0"`
+	Paused         bool   `gorm:"not null;default:false"`
+	NoteContains   string `gorm:"type:text;not null;default:''"`
+	NoteRegex      string `gorm:"type:text;not null;default:''"`
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	Kind           string   `gorm:"type:text;not null;default:''"`
+	AccountID      string   `gorm:"type:text;not null;default:'';index"`
+	CategoryID     string   `gorm:"type:text;not null;default:'';index"`
+	TagIDs         []string `gorm:"type:text;not null;serializer:json"`
+	SetNote        string   `gorm:"type:text;not null;default:''"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (Rule) TableName() string { return "rules" }
+
+// Attachment is the metadata of a file in the blob store, keyed by ID. It has
+// no foreign key to transactions, which PUT /state deletes and re-inserts;
+// attachments of transactions that are gone are pruned with their files.
+type Attachment struct {
+	ID          string `gorm:"primaryKey;type:text"`
+	UserID      string `gorm:"type:text;not null;default:'';index"`
+	TxnID       string `gorm:"type:text;not null;index"`
+	FileName    string `gorm:"type:text;not null"`
+	ContentType string `gorm:"type:text;not null"`
+	SizeBytes   int64  `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+func (Attachment) TableName() string { return "attachments" }
+
+// AuditEntry is append-only. The auto-increment ID orders entries written in
+// the same second. Before, After and Diff hold JSON objects; Before is null on
+// create and After on delete.
+type AuditEntry struct {
+	ID         int64          `gorm:"primaryKey;autoIncrement"`
+	UserID     string         `gorm:"type:text;not null;default:'';index:audit_entries_user_entity_idx,priority:1;index:audit_entries_user_created_idx,priority:1"`
+	EntityType string         `gorm:"type:text;not null;index:audit_entries_user_entity_idx,priority:2"`
+	EntityID   string         `gorm:"type:text;not null;index:audit_entries_user_entity_idx,priority:3"`
+	Action     string         `gorm:"type:text;not null"`
+	Actor      string         `gorm:"type:text;not null;default:''"`
+	Before     map[string]any `gorm:"type:text;serializer:json"`
+	After      map[string]any `gorm:"type:text;serializer:json"`
+	Diff       map[string]any `gorm:"type:text;not null;serializer:json"`
+	CreatedAt  time.Time      `gorm:"index:audit_entries_user_created_idx,priority:2"`
+}
+
+func (AuditEntry) TableName() string { return "audit_entries" }
+
 // Ensure GORM sees these models even if only referenced indirectly.
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&Category{}, &Budget{}, &Transaction{})
+	err := db.AutoMigrate(&User{}, &Session{}, &Account{}, &Category{}, &Budget{}, &Transaction{}, &ExchangeRate{}, &RecurringRule{}, &BudgetTemplate{}, &BudgetTemplateLine{}, &Tag{}, &Payee{}, &TxnTag{}, &TxnSplit{}, &Attachment{}, &Rule{}, &AuditEntry{})
+	if err != nil || db.Dialector.Name() != "postgres" {
+		return err
+	}
+	// Full-text search indexes; GORM tags can't describe expression indexes.
+	for _, stmt := range searchIndexes {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-
+// searchIndexes must match the expressions transaction search queries with.
+var searchIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS transactions_note_fts_idx ON transactions USING GIN (to_tsvector('simple', note))",
+	"CREATE INDEX IF NOT EXISTS categories_name_fts_idx ON categories USING GIN (to_tsvector('simple', name))",
+}
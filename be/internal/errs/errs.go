@@ -1,9 +1,35 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrNotFound   = errors.New("not found")
-	ErrConflict   = errors.New("conflict")
-	ErrValidation = errors.New("validation error")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation error")
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrPreconditionFailed means the row changed since the version the caller
+	// last saw.
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
+
+// Problem describes one invalid field. Path points into the request body,
+// e.g. "transactions[3].categoryId".
+type Problem struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError carries every problem found in a request. It matches
+// errors.Is(err, ErrValidation).
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation error: %d problem(s)", len(e.Problems))
+}
+
+func (e *ValidationError) Unwrap() error { return ErrValidation }
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/validate"
+)
+
+type Accounts struct {
+	Svc          *services.AccountService
+	TxnSvc       *services.TxnService
+	RecurringSvc *services.RecurringService
+	RuleSvc      *services.RuleService
+	HomeCurrency string
+}
+
+// List hides archived accounts unless ?includeArchived=true.
+func (h Accounts) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext(), c.QueryBool("includeArchived"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Accounts) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Accounts) Create(c *fiber.Ctx) error {
+	var in services.CreateAccountInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	in.Name = strings.TrimSpace(in.Name)
+	in.Currency = strings.ToUpper(strings.TrimSpace(in.Currency))
+	if in.Name == "" || !in.Type.Valid() {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Currency == "" {
+		in.Currency = h.HomeCurrency
+	}
+	if !fitsCurrency(in.Currency, in.OpeningBalanceCents) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.Create(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+func (h Accounts) Update(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var in services.UpdateAccountInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	if in.Name != nil {
+		trimmed := strings.TrimSpace(*in.Name)
+		if trimmed == "" {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		in.Name = &trimmed
+	}
+	if in.Type != nil && !in.Type.Valid() {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Currency != nil || in.OpeningBalanceCents != nil {
+		if err := h.checkCurrency(c, id, &in); err != nil {
+			return httpjson.WriteError(c, err)
+		}
+	}
+	out, err := h.Svc.Update(c.UserContext(), id, in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// checkCurrency validates a currency or opening-balance change. The currency of
+// an account with transactions or recurring rules is fixed, since they are all
+// in that currency.
+func (h Accounts) checkCurrency(c *fiber.Ctx, id string, in *services.UpdateAccountInput) error {
+	existing, err := h.Svc.Get(c.UserContext(), id)
+	if err != nil {
+		return err
+	}
+	ccy, opening := existing.Currency, existing.OpeningBalanceCents
+	if in.Currency != nil {
+		ccy = strings.ToUpper(strings.TrimSpace(*in.Currency))
+		in.Currency = &ccy
+	}
+	if in.OpeningBalanceCents != nil {
+		opening = *in.OpeningBalanceCents
+	}
+	if !fitsCurrency(ccy, opening) {
+		return errs.ErrValidation
+	}
+	if ccy != existing.Currency {
+		n, err := h.TxnSvc.CountByAccount(c.UserContext(), id)
+		if err != nil {
+			return err
+		}
+		rules, err := h.RecurringSvc.CountByAccount(c.UserContext(), id)
+		if err != nil {
+			return err
+		}
+		if n+rules > 0 {
+			return errs.ErrConflict
+		}
+	}
+	return nil
+}
+
+// Delete refuses accounts that still have transactions, trashed ones included,
+// or recurring rules; archive them instead.
+func (h Accounts) Delete(c *fiber.Ctx) error {
+	id := c.Params("id")
+	n, err := h.TxnSvc.CountByAccount(c.UserContext(), id)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if n > 0 {
+		return httpjson.WriteError(c, errs.ErrConflict)
+	}
+	if n, err = h.RecurringSvc.CountByAccount(c.UserContext(), id); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if n > 0 {
+		return httpjson.WriteError(c, errs.ErrConflict)
+	}
+	if n, err = h.RuleSvc.CountByAccount(c.UserContext(), id); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if n > 0 {
+		return httpjson.WriteError(c, errs.ErrConflict)
+	}
+	if err := h.Svc.Delete(c.UserContext(), id); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Balance returns the running balance over an optional from/to date range.
+func (h Accounts) Balance(c *fiber.Ctx) error {
+	from := strings.TrimSpace(c.Query("from"))
+	to := strings.TrimSpace(c.Query("to"))
+	if (from != "" && !validate.DateKey(from)) || (to != "" && !validate.DateKey(to)) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if from != "" && to != "" && from > to {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.Balance(c.UserContext(), c.Params("id"), from, to)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
@@ -0,0 +1,154 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/blob"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/router"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/testutil"
+)
+
+// testApp sends every request as a registered, logged-in user.
+type testApp struct {
+	*fiber.App
+	token string
+}
+
+func (a *testApp) Test(req *http.Request) (*http.Response, error) {
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+a.token)
+	return a.App.Test(req)
+}
+
+// newTestApp wires the full router against a fresh SQLite database and logs in
+// a test user. The returned ctx is bound to that user for seeding through repositories.
+func newTestApp(t *testing.T) (*testApp, *gorm.DB, context.Context) {
+	t.Helper()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	txManager := repositories.NewGormTransactor(gdb)
+	auditSvc := services.NewAuditService(clk, txManager, repositories.NewGormAuditRepo(gdb))
+	accountRepo := repositories.NewGormAccountRepo(gdb)
+	catRepo := auditSvc.Categories(repositories.NewGormCategoryRepo(gdb))
+	budgetRepo := auditSvc.Budgets(repositories.NewGormBudgetRepo(gdb))
+	txnRepo := auditSvc.Txns(repositories.NewGormTxnRepo(gdb))
+	reportRepo := repositories.NewGormReportRepo(gdb)
+	rateRepo := repositories.NewGormRateRepo(gdb)
+	recurringRepo := repositories.NewGormRecurringRuleRepo(gdb)
+	tagRepo := repositories.NewGormTagRepo(gdb)
+	attachmentRepo := repositories.NewGormAttachmentRepo(gdb)
+	ruleRepo := repositories.NewGormRuleRepo(gdb)
+	payeeRepo := repositories.NewGormPayeeRepo(gdb)
+	userRepo := repositories.NewGormUserRepo(gdb)
+	sessionRepo := repositories.NewGormSessionRepo(gdb)
+
+	authSvc := services.NewAuthService(clk, ids, txManager, userRepo, sessionRepo)
+	accountSvc := services.NewAccountService(clk, ids, accountRepo, txnRepo)
+	categorySvc := services.NewCategoryService(clk, ids, catRepo)
+	budgetSvc := services.NewBudgetService(clk, ids, txManager, budgetRepo)
+	txnSvc := services.NewTxnService(clk, ids, txManager, txnRepo)
+	txnSvc.OnNewMonth(budgetSvc.ApplyAutoTemplates)
+	transferSvc := services.NewTransferService(clk, ids, txManager, txnRepo)
+	stateSvc := services.NewStateService(txManager, accountRepo, catRepo, budgetRepo, txnRepo, recurringRepo, tagRepo, payeeRepo)
+	recurringSvc := services.NewRecurringService(clk, ids, txManager, recurringRepo, txnSvc)
+	tagSvc := services.NewTagService(clk, ids, tagRepo)
+	ruleSvc := services.NewRuleService(clk, ids, txManager, ruleRepo, catRepo, tagRepo, txnRepo)
+	payeeSvc := services.NewPayeeService(clk, ids, txManager, payeeRepo, txnRepo)
+	blobs, err := blob.NewLocalFS(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	attachmentSvc := services.NewAttachmentService(clk, ids, attachmentRepo, blobs)
+	trashSvc := services.NewTrashService(clk, services.DefaultTrashRetention, txManager, catRepo, budgetRepo, txnRepo, attachmentSvc)
+	rateSvc := services.NewRateService(clk, ids, rateRepo)
+	reportSvc := services.NewReportService(reportRepo, catRepo, models.DefaultCurrency)
+	importSvc := services.NewImportService(txManager, catRepo, txnSvc, ruleSvc, payeeSvc)
+	exportSvc := services.NewExportService(catRepo, txnRepo)
+
+	app := router.New(router.Deps{
+		HomeCurrency: models.DefaultCurrency,
+		Auth:         authSvc,
+		Account:      accountSvc,
+		Category:     categorySvc,
+		Budget:       budgetSvc,
+		Transaction:  txnSvc,
+		Transfer:     transferSvc,
+		Recurring:    recurringSvc,
+		Tag:          tagSvc,
+		Rule:         ruleSvc,
+		Payee:        payeeSvc,
+		Attachment:   attachmentSvc,
+		Trash:        trashSvc,
+		Audit:        auditSvc,
+		State:        stateSvc,
+		Rate:         rateSvc,
+		Report:       reportSvc,
+		Import:       importSvc,
+		Export:       exportSvc,
+	})
+	tok := login(t, app, "test@example.com")
+	return &testApp{App: app, token: tok.Token}, gdb, auth.WithUserID(context.Background(), tok.User.ID)
+}
+
+// login registers email and returns a fresh session for it.
+func login(t *testing.T, app *fiber.App, email string) models.AuthToken {
+	t.Helper()
+	creds, _ := json.Marshal(map[string]string{"email": email, "password": "correct horse"})
+	for _, path := range []string{"/api/v1/auth/register", "/api/v1/auth/login"} {
+		req := httptest.NewRequest("POST", path, bytes.NewReader(creds))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		if resp.StatusCode >= 300 {
+			t.Fatalf("POST %s: status %d", path, resp.StatusCode)
+		}
+		if path == "/api/v1/auth/login" {
+			var tok models.AuthToken
+			if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+				t.Fatalf("decode token: %v", err)
+			}
+			return tok
+		}
+	}
+	return models.AuthToken{}
+}
+
+// doJSON sends body (if any) as JSON, decodes the response into out (if any)
+// and returns the status code.
+func (a *testApp) doJSON(t *testing.T, method string, path string, body any, out any) int {
+	t.Helper()
+	var req *http.Request
+	if body != nil {
+		b, _ := json.Marshal(body)
+		req = httptest.NewRequest(method, path, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	resp, err := a.Test(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: decode: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
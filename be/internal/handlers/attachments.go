@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/services"
+)
+
+// DefaultAttachmentMaxBytes caps uploads when Attachments.MaxBytes is unset.
+const DefaultAttachmentMaxBytes = 10 << 20
+
+// attachmentTypes are the content types accepted for upload: receipt photos and
+// PDF invoices. The type is sniffed from the content, not taken from the client.
+var attachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// Attachments handles multipart uploads with a single `file` part.
+type Attachments struct {
+	Svc      *services.AttachmentService
+	TxnSvc   *services.TxnService
+	MaxBytes int64
+}
+
+func (h Attachments) List(c *fiber.Ctx) error {
+	txn, err := h.TxnSvc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.List(c.UserContext(), txn.ID)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Attachments) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Upload returns 413 for files over MaxBytes and 415 for types other than JPEG,
+// PNG, WebP and PDF.
+func (h Attachments) Upload(c *fiber.Ctx) error {
+	txn, err := h.TxnSvc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_file"})
+	}
+	maxBytes := h.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultAttachmentMaxBytes
+	}
+	if fh.Size == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_file"})
+	}
+	if fh.Size > maxBytes {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(httpjson.ErrorResponse{Error: "too_large"})
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_file"})
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_file"})
+	}
+	head = head[:n]
+	ctype, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !attachmentTypes[ctype] {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(httpjson.ErrorResponse{Error: "unsupported_type"})
+	}
+
+	out, err := h.Svc.Create(c.UserContext(), services.CreateAttachmentInput{
+		TxnID:       txn.ID,
+		FileName:    attachmentName(fh.Filename),
+		ContentType: ctype,
+		SizeBytes:   fh.Size,
+		Body:        io.MultiReader(bytes.NewReader(head), f),
+	})
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+// Download streams the content with the stored type and file name.
+func (h Attachments) Download(c *fiber.Ctx) error {
+	a, rc, err := h.Svc.Open(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	c.Set(fiber.HeaderContentType, a.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.SendStream(rc, int(a.SizeBytes))
+}
+
+func (h Attachments) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// attachmentName keeps the base name of an uploaded file, at most 255 bytes.
+func attachmentName(name string) string {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if name == "" || name == "." || name == "/" || !utf8.ValidString(name) {
+		return "attachment"
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
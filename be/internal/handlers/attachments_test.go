@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestAttachments_UploadDownloadAndPrune(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "food", Type: models.CategoryExpense, Name: "Food"})
+
+	var txn models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-05", "categoryId": "food", "amountCents": 50_000_00,
+	}, &txn)
+
+	upload := func(name string, content []byte) (int, models.Attachment) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		fw, _ := w.CreateFormFile("file", name)
+		_, _ = fw.Write(content)
+		_ = w.Close()
+
+		req := httptest.NewRequest("POST", "/api/v1/transactions/"+txn.ID+"/attachments", &buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+		var out models.Attachment
+		if resp.StatusCode == fiber.StatusCreated {
+			_ = json.NewDecoder(resp.Body).Decode(&out)
+		}
+		return resp.StatusCode, out
+	}
+
+	if status, _ := upload("notes.txt", []byte("just some text")); status != fiber.StatusUnsupportedMediaType {
+		t.Fatalf("expected 415 for a text file, got %d", status)
+	}
+	pdf := []byte("%PDF-1.4\n1 0 obj << >> endobj\ntrailer << >>\n%%EOF\n")
+	status, att := upload(`C:\scans\receipt.pdf`, pdf)
+	if status != fiber.StatusCreated || att.FileName != "receipt.pdf" || att.ContentType != "application/pdf" || att.SizeBytes != int64(len(pdf)) {
+		t.Fatalf("upload: %d %+v", status, att)
+	}
+
+	var list []models.Attachment
+	app.doJSON(t, "GET", "/api/v1/transactions/"+txn.ID+"/attachments", nil, &list)
+	if len(list) != 1 || list[0].ID != att.ID {
+		t.Fatalf("unexpected attachments: %+v", list)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/attachments/"+att.ID+"/content", nil))
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusOK || !bytes.Equal(body, pdf) || resp.Header.Get("Content-Type") != "application/pdf" {
+		t.Fatalf("download: %d %q %q", resp.StatusCode, resp.Header.Get("Content-Type"), body)
+	}
+
+	if status := app.doJSON(t, "DELETE", "/api/v1/transactions/"+txn.ID, nil, nil); status != fiber.StatusNoContent {
+		t.Fatalf("delete transaction: %d", status)
+	}
+	if status := app.doJSON(t, "GET", "/api/v1/attachments/"+att.ID, nil, nil); status != fiber.StatusOK {
+		t.Fatalf("expected the attachment to stay while its transaction is in the trash, got %d", status)
+	}
+	if _, err := newTrashPurger(t, gdb).PurgeExpired(ctx); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if status := app.doJSON(t, "GET", "/api/v1/attachments/"+att.ID, nil, nil); status != fiber.StatusNotFound {
+		t.Fatalf("expected the attachment to go with its purged transaction, got %d", status)
+	}
+	var n int64
+	gdb.Table("attachments").Count(&n)
+	if n != 0 {
+		t.Fatalf("expected no attachment rows left, got %d", n)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/validate"
+)
+
+type Audit struct {
+	Svc *services.AuditService
+}
+
+// Feed pages through every change, newest first:
+// GET /audit?from=YYYY-MM-DD&to=YYYY-MM-DD&entityType=&cursor=&limit=
+func (h Audit) Feed(c *fiber.Ctx) error {
+	in, err := parseAuditFeedQuery(c)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Feed(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func parseAuditFeedQuery(c *fiber.Ctx) (services.AuditFeedInput, error) {
+	in := services.AuditFeedInput{
+		From:       strings.TrimSpace(c.Query("from")),
+		To:         strings.TrimSpace(c.Query("to")),
+		EntityType: models.AuditEntity(strings.TrimSpace(c.Query("entityType"))),
+		Cursor:     strings.TrimSpace(c.Query("cursor")),
+	}
+	if (in.From != "" && !validate.DateKey(in.From)) || (in.To != "" && !validate.DateKey(in.To)) {
+		return in, errs.ErrValidation
+	}
+	if in.From != "" && in.To != "" && in.From > in.To {
+		return in, errs.ErrValidation
+	}
+	if in.EntityType != "" && !in.EntityType.Valid() {
+		return in, errs.ErrValidation
+	}
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > services.MaxAuditPageSize {
+			return in, errs.ErrValidation
+		}
+		in.Limit = n
+	}
+	return in, nil
+}
+
+func (h Audit) TransactionHistory(c *fiber.Ctx) error {
+	return h.history(c, models.AuditTransaction)
+}
+
+func (h Audit) CategoryHistory(c *fiber.Ctx) error {
+	return h.history(c, models.AuditCategory)
+}
+
+func (h Audit) BudgetHistory(c *fiber.Ctx) error {
+	return h.history(c, models.AuditBudget)
+}
+
+// history lists the changes of one entity, newest first. An ID with no history
+// is an empty list rather than a 404, since entries outlive what they describe.
+func (h Audit) history(c *fiber.Ctx, entity models.AuditEntity) error {
+	out, err := h.Svc.History(c.UserContext(), entity, c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestAudit_HistoryAndFeed(t *testing.T) {
+	app, _, _ := newTestApp(t)
+
+	var cat models.Category
+	if status := app.doJSON(t, "POST", "/api/v1/categories", map[string]any{"type": "expense", "name": "Food"}, &cat); status != fiber.StatusCreated {
+		t.Fatalf("create category: %d", status)
+	}
+	var txn models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-05", "categoryId": cat.ID, "amountCents": 30_000_00, "note": "lunch",
+	}, &txn)
+	if status := app.doJSON(t, "PATCH", "/api/v1/transactions/"+txn.ID, map[string]any{"amountCents": 45_000_00}, nil); status != fiber.StatusOK {
+		t.Fatalf("update transaction: %d", status)
+	}
+	if status := app.doJSON(t, "DELETE", "/api/v1/transactions/"+txn.ID, nil, nil); status != fiber.StatusNoContent {
+		t.Fatalf("delete transaction: %d", status)
+	}
+
+	var history []models.AuditEntry
+	if status := app.doJSON(t, "GET", "/api/v1/transactions/"+txn.ID+"/history", nil, &history); status != fiber.StatusOK {
+		t.Fatalf("history: %d", status)
+	}
+	if len(history) != 3 || history[0].Action != models.AuditDelete || history[1].Action != models.AuditUpdate || history[2].Action != models.AuditCreate {
+		t.Fatalf("expected delete, update, create; got %+v", history)
+	}
+	create, update, del := history[2], history[1], history[0]
+	if create.Before != nil || create.After["note"] != "lunch" || create.Actor == "" || create.CreatedAt != "2026-01-02T00:00:00Z" {
+		t.Fatalf("unexpected create entry: %+v", create)
+	}
+	if ch, ok := update.Diff["amountCents"]; !ok || ch.From != float64(30_000_00) || ch.To != float64(45_000_00) || len(update.Diff) != 1 {
+		t.Fatalf("expected only amountCents in the update diff, got %+v", update.Diff)
+	}
+	if del.After != nil || del.Before["amountCents"] != float64(45_000_00) {
+		t.Fatalf("unexpected delete entry: %+v", del)
+	}
+
+	var page repositories.AuditPage
+	app.doJSON(t, "GET", "/api/v1/audit?from=2026-01-02&to=2026-01-02&limit=2", nil, &page)
+	if len(page.Items) != 2 || page.NextCursor == "" || page.Items[0].Action != models.AuditDelete {
+		t.Fatalf("unexpected first page: %+v", page)
+	}
+	var rest repositories.AuditPage
+	app.doJSON(t, "GET", "/api/v1/audit?from=2026-01-02&to=2026-01-02&limit=2&cursor="+page.NextCursor, nil, &rest)
+	if len(rest.Items) != 2 || rest.NextCursor != "" || rest.Items[1].EntityType != models.AuditCategory {
+		t.Fatalf("unexpected last page: %+v", rest)
+	}
+	app.doJSON(t, "GET", "/api/v1/audit?entityType=category", nil, &page)
+	if len(page.Items) != 1 || page.Items[0].EntityID != cat.ID {
+		t.Fatalf("expected the category entry alone, got %+v", page)
+	}
+	app.doJSON(t, "GET", "/api/v1/audit?from=2026-01-03", nil, &page)
+	if len(page.Items) != 0 {
+		t.Fatalf("expected nothing after the clock's day, got %+v", page)
+	}
+	for _, q := range []string{"from=2026-13-01", "from=2026-01-03&to=2026-01-02", "entityType=account", "limit=0", "cursor=abc"} {
+		if status := app.doJSON(t, "GET", "/api/v1/audit?"+q, nil, nil); status != fiber.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", q, status)
+		}
+	}
+
+	// Another user sees none of it.
+	other := login(t, app.App, "other@example.com")
+	otherApp := &testApp{App: app.App, token: other.Token}
+	otherApp.doJSON(t, "GET", "/api/v1/transactions/"+txn.ID+"/history", nil, &history)
+	if len(history) != 0 {
+		t.Fatalf("expected another user's history to be empty, got %+v", history)
+	}
+}
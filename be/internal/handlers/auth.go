@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/services"
+)
+
+const minPasswordLen = 8
+
+type Auth struct {
+	Svc *services.AuthService
+}
+
+func (h Auth) Register(c *fiber.Ctx) error {
+	var in services.CredentialsInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	in.Email = services.NormalizeEmail(in.Email)
+	if !strings.Contains(in.Email, "@") || len(in.Password) < minPasswordLen {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.Register(c.Context(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+func (h Auth) Login(c *fiber.Ctx) error {
+	var in services.CredentialsInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	out, err := h.Svc.Login(c.Context(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Auth) Logout(c *fiber.Ctx) error {
+	if err := h.Svc.Logout(c.Context(), bearerToken(c)); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h Auth) Me(c *fiber.Ctx) error {
+	out, err := h.Svc.Me(c.UserContext(), auth.UserID(c.UserContext()))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Require rejects requests without a valid bearer token and binds the caller's
+// user ID to c.UserContext(), which handlers pass down to the repositories.
+func (h Auth) Require(c *fiber.Ctx) error {
+	token := bearerToken(c)
+	if token == "" {
+		return httpjson.WriteError(c, errs.ErrUnauthorized)
+	}
+	userID, err := h.Svc.Authenticate(c.Context(), token)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	c.SetUserContext(auth.WithUserID(c.UserContext(), userID))
+	return c.Next()
+}
+
+func bearerToken(c *fiber.Ctx) string {
+	h := c.Get(fiber.HeaderAuthorization)
+	if len(h) < 7 || !strings.EqualFold(h[:7], "bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(h[7:])
+}
@@ -0,0 +1,56 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestAuth_RequiresTokenAndScopesByUser(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Groceries"})
+
+	resp, err := app.App.Test(httptest.NewRequest("GET", "/api/v1/categories", nil))
+	if err != nil {
+		t.Fatalf("GET without token: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	other := &testApp{App: app.App, token: login(t, app.App, "other@example.com").Token}
+	list := func(a *testApp) []models.Category {
+		resp, err := a.Test(httptest.NewRequest("GET", "/api/v1/categories", nil))
+		if err != nil || resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("GET /categories: %v %v", err, resp)
+		}
+		var out []models.Category
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+	if got := list(app); len(got) != 1 {
+		t.Fatalf("owner should see 1 category, got %d", len(got))
+	}
+	if got := list(other); len(got) != 0 {
+		t.Fatalf("other user should see no categories, got %d", len(got))
+	}
+	resp, _ = other.Test(httptest.NewRequest("DELETE", "/api/v1/categories/cat-food", nil))
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Fatalf("expected 404 deleting another user's category, got %d", resp.StatusCode)
+	}
+
+	resp, _ = other.Test(httptest.NewRequest("POST", "/api/v1/auth/logout", nil))
+	if resp.StatusCode != fiber.StatusNoContent {
+		t.Fatalf("expected 204 on logout, got %d", resp.StatusCode)
+	}
+	resp, _ = other.Test(httptest.NewRequest("GET", "/api/v1/auth/me", nil))
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("expected 401 after logout, got %d", resp.StatusCode)
+	}
+}
@@ -13,12 +13,13 @@ import (
 )
 
 type Budgets struct {
-	Svc     *services.BudgetService
-	CatSvc  *services.CategoryService
+	Svc          *services.BudgetService
+	CatSvc       *services.CategoryService
+	HomeCurrency string
 }
 
 func (h Budgets) List(c *fiber.Ctx) error {
-	out, err := h.Svc.List(c.Context())
+	out, err := h.Svc.List(c.UserContext())
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
@@ -35,10 +36,17 @@ func (h Budgets) Upsert(c *fiber.Ctx) error {
 	// Validation belongs in handlers.
 	in.Month = strings.TrimSpace(in.Month)
 	in.CategoryID = strings.TrimSpace(in.CategoryID)
+	in.Currency = strings.ToUpper(strings.TrimSpace(in.Currency))
+	if in.Currency == "" {
+		in.Currency = h.HomeCurrency
+	}
 	if !validate.MonthKey(in.Month) || in.CategoryID == "" || in.AmountCents < 0 {
 		return httpjson.WriteError(c, errs.ErrValidation)
 	}
-	cat, err := h.CatSvc.Get(c.Context(), in.CategoryID)
+	if !fitsCurrency(in.Currency, in.AmountCents) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	cat, err := h.CatSvc.Get(c.UserContext(), in.CategoryID)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
@@ -46,7 +54,7 @@ func (h Budgets) Upsert(c *fiber.Ctx) error {
 		return httpjson.WriteError(c, errs.ErrValidation)
 	}
 
-	out, err := h.Svc.Upsert(c.Context(), in)
+	out, err := h.Svc.Upsert(c.UserContext(), in)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
@@ -55,8 +63,140 @@ func (h Budgets) Upsert(c *fiber.Ctx) error {
 
 func (h Budgets) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
-	if err := h.Svc.Delete(c.Context(), id); err != nil {
+	if err := h.Svc.Delete(c.UserContext(), id); err != nil {
 		return httpjson.WriteError(c, err)
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// Copy copies one month's budgets into another; mode is "skip" (default) or
+// "overwrite" for budgets the target month already has.
+func (h Budgets) Copy(c *fiber.Ctx) error {
+	var in services.CopyBudgetsInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	in.FromMonth = strings.TrimSpace(in.FromMonth)
+	in.ToMonth = strings.TrimSpace(in.ToMonth)
+	if in.Mode == "" {
+		in.Mode = models.BudgetCopySkip
+	}
+	if !validate.MonthKey(in.FromMonth) || !validate.MonthKey(in.ToMonth) || in.FromMonth == in.ToMonth || !in.Mode.Valid() {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.Copy(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Budgets) ListTemplates(c *fiber.Ctx) error {
+	out, err := h.Svc.ListTemplates(c.UserContext())
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Budgets) GetTemplate(c *fiber.Ctx) error {
+	out, err := h.Svc.GetTemplate(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Budgets) CreateTemplate(c *fiber.Ctx) error {
+	var in services.CreateBudgetTemplateInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Lines == nil {
+		in.Lines = []models.BudgetTemplateLine{}
+	}
+	if err := h.checkLines(c, in.Lines); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.CreateTemplate(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+func (h Budgets) UpdateTemplate(c *fiber.Ctx) error {
+	var in services.UpdateBudgetTemplateInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	if in.Name != nil && strings.TrimSpace(*in.Name) == "" {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Lines != nil {
+		if err := h.checkLines(c, *in.Lines); err != nil {
+			return httpjson.WriteError(c, err)
+		}
+	}
+	out, err := h.Svc.UpdateTemplate(c.UserContext(), c.Params("id"), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Budgets) DeleteTemplate(c *fiber.Ctx) error {
+	if err := h.Svc.DeleteTemplate(c.UserContext(), c.Params("id")); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ApplyTemplate sets a template's budgets on a month, with the same modes as Copy.
+func (h Budgets) ApplyTemplate(c *fiber.Ctx) error {
+	var in services.ApplyTemplateInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	in.Month = strings.TrimSpace(in.Month)
+	if in.Mode == "" {
+		in.Mode = models.BudgetCopySkip
+	}
+	if !validate.MonthKey(in.Month) || !in.Mode.Valid() {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.ApplyTemplate(c.UserContext(), c.Params("id"), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// checkLines applies the Upsert rules to each template line and normalizes its
+// currency. A category may appear only once.
+func (h Budgets) checkLines(c *fiber.Ctx, lines []models.BudgetTemplateLine) error {
+	seen := make(map[string]bool, len(lines))
+	for i := range lines {
+		l := &lines[i]
+		l.CategoryID = strings.TrimSpace(l.CategoryID)
+		l.Currency = strings.ToUpper(strings.TrimSpace(l.Currency))
+		if l.Currency == "" {
+			l.Currency = h.HomeCurrency
+		}
+		if l.CategoryID == "" || seen[l.CategoryID] || l.AmountCents < 0 || !fitsCurrency(l.Currency, l.AmountCents) {
+			return errs.ErrValidation
+		}
+		seen[l.CategoryID] = true
+		cat, err := h.CatSvc.Get(c.UserContext(), l.CategoryID)
+		if err != nil {
+			return err
+		}
+		if cat.Type != models.CategoryExpense {
+			return errs.ErrValidation
+		}
+	}
+	return nil
+}
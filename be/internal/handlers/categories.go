@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,19 +13,38 @@ import (
 )
 
 type Categories struct {
-	Svc       *services.CategoryService
-	BudgetSvc *services.BudgetService
-	TxnSvc    *services.TxnService
+	Svc          *services.CategoryService
+	BudgetSvc    *services.BudgetService
+	TxnSvc       *services.TxnService
+	RecurringSvc *services.RecurringService
+	RuleSvc      *services.RuleService
 }
 
+// List returns a flat list, or nested nodes with ?tree=true.
 func (h Categories) List(c *fiber.Ctx) error {
-	out, err := h.Svc.List(c.Context())
+	if c.QueryBool("tree") {
+		out, err := h.Svc.Tree(c.UserContext())
+		if err != nil {
+			return httpjson.WriteError(c, err)
+		}
+		return c.JSON(out)
+	}
+	out, err := h.Svc.List(c.UserContext())
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
 	return c.JSON(out)
 }
 
+// Get returns one category with its version as the ETag.
+func (h Categories) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return writeVersioned(c, out, out.Version)
+}
+
 func (h Categories) Create(c *fiber.Ctx) error {
 	var in services.CreateCategoryInput
 	if err := c.BodyParser(&in); err != nil {
@@ -38,19 +58,35 @@ func (h Categories) Create(c *fiber.Ctx) error {
 	if in.Type != models.CategoryIncome && in.Type != models.CategoryExpense {
 		return httpjson.WriteError(c, errs.ErrValidation)
 	}
-	out, err := h.Svc.Create(c.Context(), in)
+	if in.RolloverPolicy == "" {
+		in.RolloverPolicy = models.RolloverNone
+	}
+	if !validRollover(in.Type, in.RolloverPolicy) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	in.ParentID = strings.TrimSpace(in.ParentID)
+	if err := h.checkParent(c, "", in.Type, in.ParentID); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Create(c.UserContext(), in)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
 	return c.Status(fiber.StatusCreated).JSON(out)
 }
 
+// Update honors If-Match: a stale version gets 412 with the current category.
 func (h Categories) Update(c *fiber.Ctx) error {
 	id := c.Params("id")
 	var in services.UpdateCategoryInput
 	if err := c.BodyParser(&in); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
 	}
+	version, err := ifMatch(c)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	in.IfVersion = version
 	if in.Name != nil {
 		trimmed := strings.TrimSpace(*in.Name)
 		if trimmed == "" {
@@ -62,18 +98,55 @@ func (h Categories) Update(c *fiber.Ctx) error {
 		trimmed := strings.TrimSpace(*in.Description)
 		in.Description = &trimmed
 	}
-	out, err := h.Svc.Update(c.Context(), id, in)
+	if in.RolloverPolicy != nil || in.ParentID != nil {
+		existing, err := h.Svc.Get(c.UserContext(), id)
+		if err != nil {
+			return httpjson.WriteError(c, err)
+		}
+		if in.RolloverPolicy != nil && !validRollover(existing.Type, *in.RolloverPolicy) {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		if in.ParentID != nil {
+			trimmed := strings.TrimSpace(*in.ParentID)
+			in.ParentID = &trimmed
+			if err := h.checkParent(c, id, existing.Type, trimmed); err != nil {
+				return httpjson.WriteError(c, err)
+			}
+		}
+	}
+	out, err := h.Svc.Update(c.UserContext(), id, in)
+	if errors.Is(err, errs.ErrPreconditionFailed) {
+		return h.stale(c, id)
+	}
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
-	return c.JSON(out)
+	return writeVersioned(c, out, out.Version)
+}
+
+// stale answers a PATCH made against an old version of the category.
+func (h Categories) stale(c *fiber.Ctx, id string) error {
+	current, err := h.Svc.Get(c.UserContext(), id)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return writeStale(c, current, current.Version)
 }
 
 func (h Categories) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
 
 	// Validation/business rule: disallow delete if referenced.
-	budgets, err := h.BudgetSvc.List(c.Context())
+	cats, err := h.Svc.List(c.UserContext())
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	for _, sub := range cats {
+		if sub.ParentID == id {
+			return httpjson.WriteError(c, errs.ErrConflict)
+		}
+	}
+	budgets, err := h.BudgetSvc.List(c.UserContext())
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
@@ -82,16 +155,66 @@ func (h Categories) Delete(c *fiber.Ctx) error {
 			return httpjson.WriteError(c, errs.ErrConflict)
 		}
 	}
-	n, err := h.TxnSvc.CountByCategory(c.Context(), id)
+	n, err := h.TxnSvc.CountByCategory(c.UserContext(), id)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
 	if n > 0 {
 		return httpjson.WriteError(c, errs.ErrConflict)
 	}
+	if n, err = h.RecurringSvc.CountByCategory(c.UserContext(), id); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if n > 0 {
+		return httpjson.WriteError(c, errs.ErrConflict)
+	}
+	if n, err = h.BudgetSvc.CountTemplatesByCategory(c.UserContext(), id); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if n > 0 {
+		return httpjson.WriteError(c, errs.ErrConflict)
+	}
+	if n, err = h.RuleSvc.CountByCategory(c.UserContext(), id); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if n > 0 {
+		return httpjson.WriteError(c, errs.ErrConflict)
+	}
 
-	if err := h.Svc.Delete(c.Context(), id); err != nil {
+	if err := h.Svc.Delete(c.UserContext(), id); err != nil {
 		return httpjson.WriteError(c, err)
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// validRollover allows carrying balances only for expense categories, the ones
+// that have budgets.
+func validRollover(t models.CategoryType, p models.RolloverPolicy) bool {
+	return p.Valid() && (p == models.RolloverNone || t == models.CategoryExpense)
+}
+
+// checkParent requires parentID, when set, to be a category of the same type
+// that is not id itself or one of its descendants.
+func (h Categories) checkParent(c *fiber.Ctx, id string, t models.CategoryType, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	parent, err := h.Svc.Get(c.UserContext(), parentID)
+	if err != nil {
+		return err
+	}
+	if parent.Type != t {
+		return errs.ErrValidation
+	}
+	if id == "" {
+		return nil
+	}
+	cats, err := h.Svc.List(c.UserContext())
+	if err != nil {
+		return err
+	}
+	if services.CreatesCycle(cats, id, parentID) {
+		return errs.ErrValidation
+	}
+	return nil
+}
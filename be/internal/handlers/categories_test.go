@@ -5,36 +5,14 @@ import (
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 
-	"personal-budgeting/be/internal/repositories"
-	"personal-budgeting/be/internal/router"
-	"personal-budgeting/be/internal/services"
-	"personal-budgeting/be/internal/testutil"
+	"personal-budgeting/be/internal/models"
 )
 
 func TestCategoriesHandler_CreateAndList(t *testing.T) {
-	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
-	ids := &testutil.SeqID{}
-
-	gdb := testutil.NewTestGormDB(t)
-	catRepo := repositories.NewGormCategoryRepo(gdb)
-	budgetRepo := repositories.NewGormBudgetRepo(gdb)
-	txnRepo := repositories.NewGormTxnRepo(gdb)
-
-	categorySvc := services.NewCategoryService(clk, ids, catRepo)
-	budgetSvc := services.NewBudgetService(clk, ids, budgetRepo)
-	txnSvc := services.NewTxnService(clk, ids, txnRepo)
-	stateSvc := services.NewStateService(catRepo, budgetRepo, txnRepo)
-
-	app := router.New(router.Deps{
-		Category:    categorySvc,
-		Budget:      budgetSvc,
-		Transaction: txnSvc,
-		State:       stateSvc,
-	})
+	app, _, _ := newTestApp(t)
 
 	body, _ := json.Marshal(map[string]any{
 		"type": "expense",
@@ -59,3 +37,67 @@ func TestCategoriesHandler_CreateAndList(t *testing.T) {
 		t.Fatalf("expected 200, got %d", resp2.StatusCode)
 	}
 }
+
+func TestCategoriesHandler_Hierarchy(t *testing.T) {
+	app, _, _ := newTestApp(t)
+
+	var food, groceries, salary models.Category
+	app.doJSON(t, "POST", "/api/v1/categories", map[string]any{"type": "expense", "name": "Food"}, &food)
+	if status := app.doJSON(t, "POST", "/api/v1/categories", map[string]any{"type": "expense", "name": "Groceries", "parentId": food.ID}, &groceries); status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	app.doJSON(t, "POST", "/api/v1/categories", map[string]any{"type": "income", "name": "Salary"}, &salary)
+
+	if status := app.doJSON(t, "PATCH", "/api/v1/categories/"+groceries.ID, map[string]any{"parentId": salary.ID}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for a parent of another type, got %d", status)
+	}
+	if status := app.doJSON(t, "PATCH", "/api/v1/categories/"+food.ID, map[string]any{"parentId": groceries.ID}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for a cycle, got %d", status)
+	}
+	if status := app.doJSON(t, "DELETE", "/api/v1/categories/"+food.ID, nil, nil); status != fiber.StatusConflict {
+		t.Fatalf("expected 409 deleting a parent, got %d", status)
+	}
+
+	var tree []models.CategoryNode
+	app.doJSON(t, "GET", "/api/v1/categories?tree=true", nil, &tree)
+	if len(tree) != 2 || tree[0].ID != food.ID || len(tree[0].Children) != 1 || tree[0].Children[0].ID != groceries.ID {
+		t.Fatalf("unexpected tree: %+v", tree)
+	}
+
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-05", "categoryId": groceries.ID, "amountCents": 50_000_00,
+	}, nil)
+	var sum models.Summary
+	app.doJSON(t, "GET", "/api/v1/summary?month=2026-01", nil, &sum)
+	if sum.ExpenseCents != 50_000_00 || len(sum.Categories) != 2 {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+	for _, ct := range sum.Categories {
+		if ct.TotalCents != 50_000_00 || ct.Count != 1 {
+			t.Fatalf("expected Food to roll up Groceries: %+v", sum.Categories)
+		}
+	}
+}
+
+func TestCategoriesHandler_IfMatch(t *testing.T) {
+	app, _, _ := newTestApp(t)
+	var cat models.Category
+	app.doJSON(t, "POST", "/api/v1/categories", map[string]any{"type": "expense", "name": "Food"}, &cat)
+
+	var got models.Category
+	if status := app.doJSON(t, "GET", "/api/v1/categories/"+cat.ID, nil, &got); status != fiber.StatusOK || got.Version != 1 {
+		t.Fatalf("get: %d %+v", status, got)
+	}
+	var renamed models.Category
+	if status, tag := patchIfMatch(t, app, "/api/v1/categories/"+cat.ID, `"1"`, map[string]any{"name": "Groceries"}, &renamed); status != fiber.StatusOK || tag != `"2"` {
+		t.Fatalf("first write: %d %s", status, tag)
+	}
+	var stale struct {
+		Error   string          `json:"error"`
+		Current models.Category `json:"current"`
+	}
+	status, tag := patchIfMatch(t, app, "/api/v1/categories/"+cat.ID, `"1"`, map[string]any{"name": "Eating out"}, &stale)
+	if status != fiber.StatusPreconditionFailed || tag != `"2"` || stale.Current.Name != "Groceries" || stale.Current.Version != 2 {
+		t.Fatalf("expected 412 with the current category, got %d %s %+v", status, tag, stale)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+)
+
+// Transactions and categories carry a version that every update bumps. GET and
+// PATCH send it as the ETag, and a PATCH with If-Match only applies while the
+// row is still at that version, so two tabs cannot silently overwrite each
+// other.
+
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch returns the version the If-Match header asks for, 0 when the header
+// is absent or "*". Weak tags (W/"3"), which proxies may turn ours into, count
+// as the version they name; a header that isn't one of our tags is
+// errs.ErrValidation.
+func ifMatch(c *fiber.Ctx) (int64, error) {
+	v := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	v = strings.TrimPrefix(v, "W/")
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return 0, errs.ErrValidation
+	}
+	n, err := strconv.ParseInt(v[1:len(v)-1], 10, 64)
+	if err != nil || n <= 0 {
+		return 0, errs.ErrValidation
+	}
+	return n, nil
+}
+
+// writeVersioned sends out with its ETag.
+func writeVersioned(c *fiber.Ctx, out any, version int64) error {
+	c.Set(fiber.HeaderETag, etag(version))
+	return c.JSON(out)
+}
+
+// writeStale rejects a write made against an old version with 412, holding the
+// current one so the client can offer a merge.
+func writeStale(c *fiber.Ctx, current any, version int64) error {
+	c.Set(fiber.HeaderETag, etag(version))
+	return c.Status(fiber.StatusPreconditionFailed).JSON(httpjson.ErrorResponse{Error: "precondition_failed", Current: current})
+}
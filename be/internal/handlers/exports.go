@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bufio"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/money"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/validate"
+)
+
+type Exports struct {
+	Svc *services.ExportService
+}
+
+// Transactions streams an export. Query params: format (csv|jsonl), from, to,
+// kind, categoryId, and for CSV delimiter (single character) and decimal (comma|dot).
+func (h Exports) Transactions(c *fiber.Ctx) error {
+	in := services.ExportInput{
+		Format:  services.ExportFormat(strings.TrimSpace(c.Query("format", "csv"))),
+		Decimal: money.DecimalStyle(strings.TrimSpace(c.Query("decimal", "comma"))),
+		Filter: repositories.TxnFilter{
+			From:       strings.TrimSpace(c.Query("from")),
+			To:         strings.TrimSpace(c.Query("to")),
+			Kind:       models.TransactionKind(strings.TrimSpace(c.Query("kind"))),
+			CategoryID: strings.TrimSpace(c.Query("categoryId")),
+		},
+	}
+	if in.Format != services.ExportCSV && in.Format != services.ExportJSONL {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if !in.Decimal.Valid() {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if (in.Filter.From != "" && !validate.DateKey(in.Filter.From)) || (in.Filter.To != "" && !validate.DateKey(in.Filter.To)) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Filter.Kind != "" && !in.Filter.Kind.Valid() {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if d := c.Query("delimiter"); d != "" {
+		r, size := utf8.DecodeRuneInString(d)
+		if size != len(d) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		in.Delimiter = r
+	}
+
+	filename := "transactions.csv"
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	if in.Format == services.ExportJSONL {
+		filename = "transactions.jsonl"
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	}
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+
+	// The stream writer runs after this handler returns, so it must not touch c.
+	ctx := c.UserContext()
+	svc := h.Svc
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := svc.Export(ctx, w, in); err != nil {
+			// Headers are already sent; all we can do is log and cut the body short.
+			log.Printf("export failed: %v", err)
+		}
+		_ = w.Flush()
+	})
+	return nil
+}
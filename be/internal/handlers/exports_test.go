@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestExportsHandler_StreamsCSV(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Groceries"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-salary", Type: models.CategoryIncome, Name: "Salary"})
+	_, _ = txnRepo.Create(ctx, models.Txn{ID: "t1", Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "cat-food", AmountCents: 150000_50, Note: "Indomaret"})
+	_, _ = txnRepo.Create(ctx, models.Txn{ID: "t2", Kind: models.KindIncome, Date: "2026-01-25", CategoryID: "cat-salary", AmountCents: 10_000_000_00})
+	_, _ = txnRepo.Create(ctx, models.Txn{ID: "t3", Kind: models.KindExpense, Date: "2026-02-01", CategoryID: "cat-food", AmountCents: 10_00})
+
+	req := httptest.NewRequest("GET", "/api/v1/exports/transactions?from=2026-01-01&to=2026-01-31&delimiter=;", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("GET /exports/transactions: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	want := "date;kind;category;amount;currency;note;id\n" +
+		"2026-01-05;expense;Groceries;-150000,50;IDR;Indomaret;t1\n" +
+		"2026-01-25;income;Salary;10000000,00;IDR;;t2\n"
+	if string(body) != want {
+		t.Fatalf("unexpected CSV:\n%s", body)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/exports/transactions?format=jsonl&kind=expense", nil)
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("GET jsonl: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"categoryName":"Groceries"`) || !strings.Contains(lines[0], `"amount":"150000.50"`) {
+		t.Fatalf("unexpected JSONL:\n%s", body)
+	}
+}
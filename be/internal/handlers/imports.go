@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/imports"
+	"personal-budgeting/be/internal/services"
+)
+
+// Imports handles multipart uploads: a `file` part plus JSON form fields
+// `mapping` (format specific) and `options` (services.ImportOptions).
+type Imports struct {
+	Svc          *services.ImportService
+	HomeCurrency string
+}
+
+func (h Imports) PreviewCSV(c *fiber.Ctx) error { return h.csv(c, false) }
+
+func (h Imports) CommitCSV(c *fiber.Ctx) error { return h.csv(c, true) }
+
+func (h Imports) csv(c *fiber.Ctx, commit bool) error {
+	var m imports.CSVMapping
+	if err := json.Unmarshal([]byte(c.FormValue("mapping")), &m); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_mapping"})
+	}
+	body, err := readUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_file"})
+	}
+	defer body.Close()
+
+	cands, err := imports.ParseCSV(body, m)
+	if err != nil {
+		if errors.Is(err, imports.ErrBadMapping) {
+			return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_mapping"})
+		}
+		return httpjson.WriteError(c, err)
+	}
+	return h.run(c, cands, commit)
+}
+
+func (h Imports) PreviewOFX(c *fiber.Ctx) error { return h.ofx(c, false) }
+
+func (h Imports) CommitOFX(c *fiber.Ctx) error { return h.ofx(c, true) }
+
+// ofx accepts OFX and QFX statements; there is no column mapping.
+func (h Imports) ofx(c *fiber.Ctx, commit bool) error {
+	body, err := readUpload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_file"})
+	}
+	defer body.Close()
+
+	cands, err := imports.ParseOFX(body)
+	if err != nil {
+		if errors.Is(err, imports.ErrBadOFX) {
+			return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_file"})
+		}
+		return httpjson.WriteError(c, err)
+	}
+	return h.run(c, cands, commit)
+}
+
+func (h Imports) run(c *fiber.Ctx, cands []imports.Candidate, commit bool) error {
+	var opts services.ImportOptions
+	if raw := c.FormValue("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+		}
+	}
+	opts.Currency = strings.ToUpper(strings.TrimSpace(opts.Currency))
+	if opts.Currency == "" {
+		opts.Currency = h.HomeCurrency
+	}
+	if !knownCurrency(opts.Currency) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	run := h.Svc.Preview
+	if commit {
+		run = h.Svc.Commit
+	}
+	out, err := run(c.UserContext(), cands, opts)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func readUpload(c *fiber.Ctx) (io.ReadCloser, error) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return fh.Open()
+}
@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestImportsHandler_CSVPreviewAndCommit(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Groceries"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-salary", Type: models.CategoryIncome, Name: "Salary"})
+
+	csvData := "Tanggal;Keterangan;Jumlah;Kategori\n" +
+		"05/01/2026;Indomaret;-150.000;groceries\n" +
+		"25/01/2026;Gaji;10.000.000;\n" +
+		"31/02/2026;Bad date;-10;Groceries\n"
+	mapping := `{"hasHeader":true,"delimiter":";","date":"Tanggal","dateFormat":"DD/MM/YYYY","amount":"Jumlah","note":"Keterangan","category":"Kategori"}`
+	options := `{"defaultIncomeCategoryId":"cat-salary"}`
+
+	send := func(path string) models.ImportResult {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		fw, _ := w.CreateFormFile("file", "bank.csv")
+		_, _ = fw.Write([]byte(csvData))
+		_ = w.WriteField("mapping", mapping)
+		_ = w.WriteField("options", options)
+		_ = w.Close()
+
+		req := httptest.NewRequest("POST", path, &buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("POST %s: expected 200, got %d", path, resp.StatusCode)
+		}
+		var out models.ImportResult
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return out
+	}
+
+	preview := send("/api/v1/imports/csv/preview")
+	if !preview.DryRun || preview.Valid != 2 || preview.Invalid != 1 || preview.Imported != 0 {
+		t.Fatalf("unexpected preview: %+v", preview)
+	}
+	if r := preview.Rows[0]; r.Kind != models.KindExpense || r.AmountCents != 150000_00 || r.CategoryID != "cat-food" || r.Date != "2026-01-05" {
+		t.Fatalf("unexpected first row: %+v", r)
+	}
+	if r := preview.Rows[2]; r.Status != models.ImportRowInvalid || len(r.Errors) == 0 {
+		t.Fatalf("expected invalid third row, got %+v", r)
+	}
+
+	committed := send("/api/v1/imports/csv/commit")
+	if committed.DryRun || committed.Imported != 2 {
+		t.Fatalf("unexpected commit: %+v", committed)
+	}
+	var n int64
+	gdb.Table("transactions").Count(&n)
+	if n != 2 {
+		t.Fatalf("expected 2 stored transactions, got %d", n)
+	}
+}
+
+func TestImportsHandler_OFXReimportSkipsDuplicates(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-misc", Type: models.CategoryExpense, Name: "Misc"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-in", Type: models.CategoryIncome, Name: "Other income"})
+
+	ofx := `<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKACCTFROM><ACCTID>99</BANKACCTFROM><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20260105<TRNAMT>-10.00<FITID>F1<NAME>Kopi</STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20260106<TRNAMT>50.00<FITID>F2<NAME>Refund</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+
+	commit := func() models.ImportResult {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		fw, _ := w.CreateFormFile("file", "statement.qfx")
+		_, _ = fw.Write([]byte(ofx))
+		_ = w.WriteField("options", `{"defaultExpenseCategoryId":"cat-misc","defaultIncomeCategoryId":"cat-in"}`)
+		_ = w.Close()
+		req := httptest.NewRequest("POST", "/api/v1/imports/ofx/commit", &buf)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+		resp, err := app.Test(req)
+		if err != nil || resp.StatusCode != fiber.StatusOK {
+			t.Fatalf("POST /imports/ofx/commit: %v (status %d)", err, resp.StatusCode)
+		}
+		var out models.ImportResult
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return out
+	}
+
+	if first := commit(); first.Imported != 2 {
+		t.Fatalf("expected 2 imported, got %+v", first)
+	}
+	second := commit()
+	if second.Imported != 0 || second.Duplicates != 2 {
+		t.Fatalf("expected re-import to skip both rows, got %+v", second)
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/services"
+)
+
+const maxPayeeName = 100
+
+type Payees struct {
+	Svc *services.PayeeService
+}
+
+func (h Payees) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext())
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Payees) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Match returns the payee raw ?text= normalizes to, or 404.
+func (h Payees) Match(c *fiber.Ctx) error {
+	text := strings.TrimSpace(c.Query("text"))
+	if text == "" {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	m, err := h.Svc.Matcher(c.UserContext())
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	p, ok := m.Match(text)
+	if !ok {
+		return httpjson.WriteError(c, errs.ErrNotFound)
+	}
+	return c.JSON(p)
+}
+
+// Create normalizes aliases; a name or alias another payee already has is a 409.
+func (h Payees) Create(c *fiber.Ctx) error {
+	var in services.CreatePayeeInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	if !payeeName(in.Name) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	var ok bool
+	if in.Aliases, ok = payeeAliases(in.Aliases); !ok {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.Create(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+func (h Payees) Update(c *fiber.Ctx) error {
+	var in services.UpdatePayeeInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	if in.Name != nil && !payeeName(*in.Name) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Aliases != nil {
+		aliases, ok := payeeAliases(*in.Aliases)
+		if !ok {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		in.Aliases = &aliases
+	}
+	out, err := h.Svc.Update(c.UserContext(), c.Params("id"), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Delete leaves the payee's transactions without a payee.
+func (h Payees) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+type mergePayeesInput struct {
+	PayeeIDs []string `json:"payeeIds"`
+}
+
+// Merge folds the payees in payeeIds into this one and returns it.
+func (h Payees) Merge(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var in mergePayeesInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	sources := make([]string, 0, len(in.PayeeIDs))
+	for _, src := range in.PayeeIDs {
+		src = strings.TrimSpace(src)
+		if src == "" || src == id {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		if !slices.Contains(sources, src) {
+			sources = append(sources, src)
+		}
+	}
+	if len(sources) == 0 {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.Merge(c.UserContext(), id, sources)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func payeeName(s string) bool {
+	s = strings.TrimSpace(s)
+	return s != "" && utf8.RuneCountInString(s) <= maxPayeeName
+}
+
+// payeeAliases normalizes raw aliases; one that normalizes to nothing, such as
+// a bare number, is unusable.
+func payeeAliases(raw []string) ([]string, bool) {
+	out := make([]string, 0, len(raw))
+	for _, a := range raw {
+		norm := services.NormalizePayee(a)
+		if norm == "" || utf8.RuneCountInString(norm) > maxPayeeName {
+			return nil, false
+		}
+		out = append(out, norm)
+	}
+	return out, true
+}
@@ -0,0 +1,103 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestPayees_MatchReportAndMerge(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "food", Type: models.CategoryExpense, Name: "Food"})
+
+	var indomaret, alfamart, alfamidi models.Payee
+	if status := app.doJSON(t, "POST", "/api/v1/payees", map[string]any{"name": "Indomaret", "aliases": []string{"INDOMARET 123", "IDM"}}, &indomaret); status != fiber.StatusCreated {
+		t.Fatalf("create payee: %d", status)
+	}
+	if len(indomaret.Aliases) != 1 || indomaret.Aliases[0] != "idm" {
+		t.Fatalf("expected aliases to be normalized and the name's own dropped, got %+v", indomaret.Aliases)
+	}
+	app.doJSON(t, "POST", "/api/v1/payees", map[string]any{"name": "Alfamart"}, &alfamart)
+	app.doJSON(t, "POST", "/api/v1/payees", map[string]any{"name": "Alfa Midi", "aliases": []string{"alfamidi"}}, &alfamidi)
+	if status := app.doJSON(t, "POST", "/api/v1/payees", map[string]any{"name": "Other", "aliases": []string{"idm"}}, nil); status != fiber.StatusConflict {
+		t.Fatalf("expected 409 for a taken alias, got %d", status)
+	}
+	if status := app.doJSON(t, "POST", "/api/v1/payees", map[string]any{"name": "Other", "aliases": []string{"#123"}}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for an alias without words, got %d", status)
+	}
+
+	txns := map[string]models.Txn{}
+	for _, in := range []struct {
+		note   string
+		amount int64
+	}{
+		{"INDOMARET 123", 50_000_00},
+		{"Indomaret Kemang", 30_000_00},
+		{"idm kemang", 20_000_00},
+		{"ALFAMIDI 7", 40_000_00},
+		{"Alfamart", 10_000_00},
+		{"Coffee", 5_000_00},
+	} {
+		var out models.Txn
+		app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+			"kind": "expense", "date": "2026-01-05", "categoryId": "food", "amountCents": in.amount, "note": in.note,
+		}, &out)
+		txns[in.note] = out
+	}
+	for note, want := range map[string]string{
+		"INDOMARET 123": indomaret.ID, "Indomaret Kemang": indomaret.ID, "idm kemang": indomaret.ID,
+		"ALFAMIDI 7": alfamidi.ID, "Alfamart": alfamart.ID, "Coffee": "",
+	} {
+		if got := txns[note].PayeeID; got != want {
+			t.Fatalf("payee of %q: got %q, want %q", note, got, want)
+		}
+	}
+
+	var matched models.Payee
+	if status := app.doJSON(t, "GET", "/api/v1/payees/match?text=ALFA%20MIDI%20KEMANG", nil, &matched); status != fiber.StatusOK || matched.ID != alfamidi.ID {
+		t.Fatalf("match: %d %+v", status, matched)
+	}
+
+	var rep models.PayeeReport
+	app.doJSON(t, "GET", "/api/v1/reports/payees?month=2026-01&limit=2", nil, &rep)
+	if len(rep.Payees) != 2 || rep.Payees[0].PayeeID != indomaret.ID || rep.Payees[0].TotalCents != 100_000_00 || rep.Payees[0].Count != 3 ||
+		rep.Payees[1].PayeeID != alfamidi.ID {
+		t.Fatalf("unexpected payee report: %+v", rep.Payees)
+	}
+
+	var merged models.Payee
+	if status := app.doJSON(t, "POST", "/api/v1/payees/"+alfamart.ID+"/merge", map[string]any{"payeeIds": []string{alfamidi.ID}}, &merged); status != fiber.StatusOK {
+		t.Fatalf("merge: %d", status)
+	}
+	if len(merged.Aliases) != 2 || merged.Aliases[0] != "alfa midi" || merged.Aliases[1] != "alfamidi" {
+		t.Fatalf("expected the merged payee's name and aliases as aliases, got %+v", merged.Aliases)
+	}
+	if status := app.doJSON(t, "GET", "/api/v1/payees/"+alfamidi.ID, nil, nil); status != fiber.StatusNotFound {
+		t.Fatalf("expected the merged payee to be gone, got %d", status)
+	}
+	var page struct {
+		Items []models.Txn `json:"items"`
+	}
+	app.doJSON(t, "GET", "/api/v1/transactions?payeeId="+alfamart.ID, nil, &page)
+	if len(page.Items) != 2 {
+		t.Fatalf("expected both transactions under the merged payee, got %+v", page.Items)
+	}
+
+	if status := app.doJSON(t, "DELETE", "/api/v1/payees/"+indomaret.ID, nil, nil); status != fiber.StatusNoContent {
+		t.Fatalf("delete payee: %d", status)
+	}
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+	got, _ := txnRepo.Get(ctx, txns["idm kemang"].ID)
+	if got.ID == "" || got.PayeeID != "" || got.Version != txns["idm kemang"].Version+1 {
+		t.Fatalf("expected the transaction to stay without a payee at a new version, got %+v", got)
+	}
+	var history []models.AuditEntry
+	app.doJSON(t, "GET", "/api/v1/transactions/"+got.ID+"/history", nil, &history)
+	if len(history) == 0 || history[0].Diff["payeeId"].From != indomaret.ID || history[0].Diff["payeeId"].To != nil {
+		t.Fatalf("expected clearing the payee to be audited, got %+v", history)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"math"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/money"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/validate"
+)
+
+type Rates struct {
+	Svc *services.RateService
+}
+
+// List accepts optional base, quote, from and to filters.
+func (h Rates) List(c *fiber.Ctx) error {
+	f := repositories.RateFilter{
+		Base:  strings.ToUpper(strings.TrimSpace(c.Query("base"))),
+		Quote: strings.ToUpper(strings.TrimSpace(c.Query("quote"))),
+		From:  strings.TrimSpace(c.Query("from")),
+		To:    strings.TrimSpace(c.Query("to")),
+	}
+	if (f.From != "" && !validate.DateKey(f.From)) || (f.To != "" && !validate.DateKey(f.To)) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.List(c.UserContext(), f)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Rates) Upsert(c *fiber.Ctx) error {
+	var in services.UpsertRateInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	in.Date = strings.TrimSpace(in.Date)
+	in.Base = strings.ToUpper(strings.TrimSpace(in.Base))
+	in.Quote = strings.ToUpper(strings.TrimSpace(in.Quote))
+	if !validate.DateKey(in.Date) || !knownCurrency(in.Base) || !knownCurrency(in.Quote) || in.Base == in.Quote {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if !(in.Rate > 0) || math.IsInf(in.Rate, 0) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.Upsert(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Rates) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func knownCurrency(code string) bool {
+	_, ok := money.LookupCurrency(code)
+	return ok
+}
+
+// fitsCurrency reports whether cents is a known currency's valid amount,
+// e.g. IDR amounts must be whole rupiah.
+func fitsCurrency(code string, cents int64) bool {
+	ccy, ok := money.LookupCurrency(code)
+	return ok && ccy.ValidCents(cents)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/services"
+)
+
+type Recurring struct {
+	Svc          *services.RecurringService
+	CatSvc       *services.CategoryService
+	AccSvc       *services.AccountService
+	HomeCurrency string
+}
+
+func (h Recurring) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext())
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Recurring) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Recurring) Create(c *fiber.Ctx) error {
+	var in services.CreateRecurringRuleInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	in.StartDate = strings.TrimSpace(in.StartDate)
+	in.EndDate = strings.TrimSpace(in.EndDate)
+	in.Currency = strings.ToUpper(strings.TrimSpace(in.Currency))
+	in.AccountID = strings.TrimSpace(in.AccountID)
+	if in.AccountID != "" {
+		acct, err := activeAccount(c, h.AccSvc, in.AccountID)
+		if err != nil {
+			return httpjson.WriteError(c, err)
+		}
+		if in.Currency == "" {
+			in.Currency = acct.Currency
+		}
+		if in.Currency != acct.Currency {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+	}
+	if in.Currency == "" {
+		in.Currency = h.HomeCurrency
+	}
+	if err := h.checkRule(c, in.Rule()); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Create(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+func (h Recurring) Update(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var in services.UpdateRecurringRuleInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	existing, err := h.Svc.Get(c.UserContext(), id)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if err := h.checkRule(c, in.Apply(existing)); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Update(c.UserContext(), id, in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Recurring) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Preview returns the next `count` occurrence dates (default 10).
+func (h Recurring) Preview(c *fiber.Ctx) error {
+	count := 10
+	if v := strings.TrimSpace(c.Query("count")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > services.MaxRecurringPreview {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		count = n
+	}
+	out, err := h.Svc.Preview(c.UserContext(), c.Params("id"), count)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// checkRule validates a complete rule: template fields as for a new
+// transaction, plus a well-formed schedule.
+func (h Recurring) checkRule(c *fiber.Ctx, r models.RecurringRule) error {
+	if r.Name == "" || r.CategoryID == "" || r.AmountCents <= 0 {
+		return errs.ErrValidation
+	}
+	if r.Kind != models.KindIncome && r.Kind != models.KindExpense {
+		return errs.ErrValidation
+	}
+	if !fitsCurrency(r.Currency, r.AmountCents) {
+		return errs.ErrValidation
+	}
+	cat, err := h.CatSvc.Get(c.UserContext(), r.CategoryID)
+	if err != nil {
+		return err
+	}
+	if string(cat.Type) != string(r.Kind) {
+		return errs.ErrValidation
+	}
+	if !services.ValidRecurringSchedule(r) {
+		return errs.ErrValidation
+	}
+	return nil
+}
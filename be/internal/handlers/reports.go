@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/money"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/validate"
+)
+
+type Reports struct {
+	Svc *services.ReportService
+}
+
+// Summary accepts either `month=YYYY-MM` or both `from` and `to` (YYYY-MM-DD).
+// Every report also takes an optional `currency` to convert into.
+func (h Reports) Summary(c *fiber.Ctx) error {
+	rng, err := parseReportRange(c)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if rng.Currency, err = queryCurrency(c); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Summary(c.UserContext(), rng)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Tags takes the same range and currency params as Summary.
+func (h Reports) Tags(c *fiber.Ctx) error {
+	rng, err := parseReportRange(c)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if rng.Currency, err = queryCurrency(c); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Tags(c.UserContext(), rng)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Payees takes the same range and currency params as Summary, plus limit.
+func (h Reports) Payees(c *fiber.Ctx) error {
+	rng, err := parseReportRange(c)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if rng.Currency, err = queryCurrency(c); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	limit := 0
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > services.MaxPayeeReportSize {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+	}
+	out, err := h.Svc.Payees(c.UserContext(), rng, limit)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Reports) BudgetVsActual(c *fiber.Ctx) error {
+	month := strings.TrimSpace(c.Query("month"))
+	if !validate.MonthKey(month) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	ccy, err := queryCurrency(c)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.BudgetVsActual(c.UserContext(), month, ccy)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Trends accepts `from`/`to` months (YYYY-MM), optional comma-separated `categoryId`
+// and an optional rolling-average `window` in months.
+func (h Reports) Trends(c *fiber.Ctx) error {
+	in := services.TrendInput{
+		From: strings.TrimSpace(c.Query("from")),
+		To:   strings.TrimSpace(c.Query("to")),
+	}
+	if !validate.MonthKey(in.From) || !validate.MonthKey(in.To) || in.From > in.To {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if monthsBetween(in.From, in.To) > services.MaxTrendMonths {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	for _, id := range strings.Split(c.Query("categoryId"), ",") {
+		if id = strings.TrimSpace(id); id != "" && !slices.Contains(in.CategoryIDs, id) {
+			in.CategoryIDs = append(in.CategoryIDs, id)
+		}
+	}
+	if v := strings.TrimSpace(c.Query("window")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 24 {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		in.Window = n
+	}
+	var err error
+	if in.Currency, err = queryCurrency(c); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Trends(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// queryCurrency reads the optional `currency` override; empty means the home currency.
+func queryCurrency(c *fiber.Ctx) (string, error) {
+	ccy := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+	if ccy == "" {
+		return "", nil
+	}
+	if _, ok := money.LookupCurrency(ccy); !ok {
+		return "", errs.ErrValidation
+	}
+	return ccy, nil
+}
+
+// monthsBetween counts the months in [from, to]; both must be valid month keys.
+func monthsBetween(from, to string) int {
+	a, _ := time.Parse("2006-01", from)
+	b, _ := time.Parse("2006-01", to)
+	return (b.Year()-a.Year())*12 + int(b.Month()-a.Month()) + 1
+}
+
+func parseReportRange(c *fiber.Ctx) (services.ReportRange, error) {
+	rng := services.ReportRange{
+		Month: strings.TrimSpace(c.Query("month")),
+		From:  strings.TrimSpace(c.Query("from")),
+		To:    strings.TrimSpace(c.Query("to")),
+	}
+	if rng.Month != "" {
+		if !validate.MonthKey(rng.Month) || rng.From != "" || rng.To != "" {
+			return rng, errs.ErrValidation
+		}
+		return rng, nil
+	}
+	if !validate.DateKey(rng.From) || !validate.DateKey(rng.To) || rng.From > rng.To {
+		return rng, errs.ErrValidation
+	}
+	return rng, nil
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/validate"
+)
+
+type Rules struct {
+	Svc    *services.RuleService
+	CatSvc *services.CategoryService
+	AccSvc *services.AccountService
+	TagSvc *services.TagService
+}
+
+// List returns rules in the order they run: priority ascending, then name.
+func (h Rules) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext())
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Rules) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Rules) Create(c *fiber.Ctx) error {
+	var in services.CreateRuleInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if err := h.checkRule(c, &in.Conditions, &in.Actions); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Create(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+func (h Rules) Update(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var in services.UpdateRuleInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	if in.Name != nil && strings.TrimSpace(*in.Name) == "" {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Conditions != nil || in.Actions != nil {
+		existing, err := h.Svc.Get(c.UserContext(), id)
+		if err != nil {
+			return httpjson.WriteError(c, err)
+		}
+		cond, act := existing.Conditions, existing.Actions
+		if in.Conditions != nil {
+			cond = *in.Conditions
+		}
+		if in.Actions != nil {
+			act = *in.Actions
+		}
+		if err := h.checkRule(c, &cond, &act); err != nil {
+			return httpjson.WriteError(c, err)
+		}
+		if in.Conditions != nil {
+			in.Conditions = &cond
+		}
+		if in.Actions != nil {
+			in.Actions = &act
+		}
+	}
+	out, err := h.Svc.Update(c.UserContext(), id, in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Rules) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Preview is a dry run over existing transactions: it lists what Apply would
+// change. The body may limit the run to from/to dates.
+func (h Rules) Preview(c *fiber.Ctx) error {
+	return h.run(c, h.Svc.Preview)
+}
+
+// Apply runs the rule over existing transactions, even when it is paused, and
+// reports what it changed.
+func (h Rules) Apply(c *fiber.Ctx) error {
+	return h.run(c, h.Svc.ApplyToHistory)
+}
+
+func (h Rules) run(c *fiber.Ctx, run func(ctx context.Context, id string, in services.RunRuleInput) (models.RuleApplyResult, error)) error {
+	var in services.RunRuleInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&in); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+		}
+	}
+	in.From, in.To = strings.TrimSpace(in.From), strings.TrimSpace(in.To)
+	if (in.From != "" && !validate.DateKey(in.From)) || (in.To != "" && !validate.DateKey(in.To)) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.From != "" && in.To != "" && in.From > in.To {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := run(c.UserContext(), c.Params("id"), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// checkRule normalizes a rule's conditions and actions in place. A rule needs at
+// least one condition and one action, and what it refers to must exist.
+func (h Rules) checkRule(c *fiber.Ctx, cond *models.RuleConditions, act *models.RuleActions) error {
+	cond.NoteContains = strings.TrimSpace(cond.NoteContains)
+	cond.AccountID = strings.TrimSpace(cond.AccountID)
+	act.CategoryID = strings.TrimSpace(act.CategoryID)
+	act.SetNote = strings.TrimSpace(act.SetNote)
+
+	if cond.NoteContains == "" && cond.NoteRegex == "" && cond.MinAmountCents == nil && cond.MaxAmountCents == nil &&
+		cond.Kind == "" && cond.AccountID == "" {
+		return errs.ErrValidation
+	}
+	if act.CategoryID == "" && len(act.TagIDs) == 0 && act.SetNote == "" {
+		return errs.ErrValidation
+	}
+	if cond.NoteRegex != "" {
+		if _, err := services.CompileRuleRegex(cond.NoteRegex); err != nil {
+			return errs.ErrValidation
+		}
+	}
+	if (cond.MinAmountCents != nil && *cond.MinAmountCents < 0) || (cond.MaxAmountCents != nil && *cond.MaxAmountCents < 0) {
+		return errs.ErrValidation
+	}
+	if cond.MinAmountCents != nil && cond.MaxAmountCents != nil && *cond.MinAmountCents > *cond.MaxAmountCents {
+		return errs.ErrValidation
+	}
+	if cond.Kind != "" && cond.Kind != models.KindIncome && cond.Kind != models.KindExpense {
+		return errs.ErrValidation
+	}
+	if cond.AccountID != "" {
+		if _, err := h.AccSvc.Get(c.UserContext(), cond.AccountID); err != nil {
+			if errors.Is(err, errs.ErrNotFound) {
+				return errs.ErrValidation
+			}
+			return err
+		}
+	}
+	if act.CategoryID != "" {
+		cat, err := h.CatSvc.Get(c.UserContext(), act.CategoryID)
+		if errors.Is(err, errs.ErrNotFound) {
+			return errs.ErrValidation
+		}
+		if err != nil {
+			return err
+		}
+		if cond.Kind != "" && !kindFitsCategory(cond.Kind, cat) {
+			return errs.ErrValidation
+		}
+	}
+	return checkTags(c, h.TagSvc, &act.TagIDs)
+}
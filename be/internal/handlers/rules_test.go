@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestRules_CreatePreviewAndApply(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "food", Type: models.CategoryExpense, Name: "Food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "misc", Type: models.CategoryExpense, Name: "Misc"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "groceries", Type: models.CategoryExpense, Name: "Groceries"})
+
+	var coffee models.Tag
+	app.doJSON(t, "POST", "/api/v1/tags", map[string]any{"name": "coffee"}, &coffee)
+
+	// Existing history, categorized before the rule existed.
+	var old models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-03", "categoryId": "misc", "amountCents": 45_000_00, "note": "STARBUCKS #123",
+	}, &old)
+
+	if status := app.doJSON(t, "POST", "/api/v1/rules", map[string]any{
+		"name": "Bad", "conditions": map[string]any{"noteRegex": "("}, "actions": map[string]any{"categoryId": "food"},
+	}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad regex, got %d", status)
+	}
+	if status := app.doJSON(t, "POST", "/api/v1/rules", map[string]any{
+		"name": "No actions", "conditions": map[string]any{"noteContains": "x"},
+	}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for a rule without actions, got %d", status)
+	}
+
+	var rule models.Rule
+	if status := app.doJSON(t, "POST", "/api/v1/rules", map[string]any{
+		"name":       "Coffee",
+		"conditions": map[string]any{"noteRegex": `^starbucks\s*#(\d+)`, "kind": "expense", "maxAmountCents": 200_000_00},
+		"actions":    map[string]any{"categoryId": "food", "tagIds": []string{coffee.ID}, "setNote": "Starbucks store $1"},
+	}, &rule); status != fiber.StatusCreated {
+		t.Fatalf("create rule: %d", status)
+	}
+	// A lower priority runs first, so it wins the category.
+	app.doJSON(t, "POST", "/api/v1/rules", map[string]any{
+		"name": "Everything small", "priority": -1,
+		"conditions": map[string]any{"maxAmountCents": 100_000_00}, "actions": map[string]any{"categoryId": "groceries"},
+	}, nil)
+
+	var created models.Txn
+	if status := app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-05", "amountCents": 150_000_00, "note": "Starbucks #77",
+	}, &created); status != fiber.StatusCreated {
+		t.Fatalf("create without a category: %d", status)
+	}
+	if created.CategoryID != "food" || created.Note != "Starbucks store 77" || len(created.TagIDs) != 1 || created.TagIDs[0] != coffee.ID {
+		t.Fatalf("rules not applied on create: %+v", created)
+	}
+	var small models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-05", "amountCents": 50_000_00, "note": "starbucks #1",
+	}, &small)
+	if small.CategoryID != "groceries" || small.Note != "Starbucks store 1" {
+		t.Fatalf("expected the first rule's category and the only note rewrite, got %+v", small)
+	}
+	var explicit models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-05", "categoryId": "misc", "amountCents": 150_000_00, "note": "Starbucks #9",
+	}, &explicit)
+	if explicit.CategoryID != "misc" {
+		t.Fatalf("rules must not override a chosen category, got %q", explicit.CategoryID)
+	}
+
+	var res models.RuleApplyResult
+	if status := app.doJSON(t, "POST", "/api/v1/rules/"+rule.ID+"/preview", map[string]any{"to": "2026-01-04"}, &res); status != fiber.StatusOK {
+		t.Fatalf("preview: %d", status)
+	}
+	if !res.DryRun || res.Matched != 1 || len(res.Changes) != 1 || res.Changes[0].TxnID != old.ID ||
+		res.Changes[0].NewCategoryID != "food" || res.Changes[0].NewNote != "Starbucks store 123" {
+		t.Fatalf("unexpected preview: %+v", res)
+	}
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+	still, _ := txnRepo.Get(ctx, old.ID)
+	if still.CategoryID != "misc" {
+		t.Fatalf("preview must not change anything, got %+v", still)
+	}
+
+	if status := app.doJSON(t, "POST", "/api/v1/rules/"+rule.ID+"/apply", nil, &res); status != fiber.StatusOK {
+		t.Fatalf("apply: %d", status)
+	}
+	// Notes rewritten on create no longer match the regex.
+	if res.DryRun || res.Matched != 1 || len(res.Changes) != 1 {
+		t.Fatalf("unexpected apply: %+v", res)
+	}
+	still, _ = txnRepo.Get(ctx, old.ID)
+	if still.CategoryID != "food" || still.Note != "Starbucks store 123" || len(still.TagIDs) != 1 {
+		t.Fatalf("apply did not update history: %+v", still)
+	}
+
+	app.doJSON(t, "DELETE", "/api/v1/transactions/"+small.ID, nil, nil)
+	if status := app.doJSON(t, "DELETE", "/api/v1/categories/groceries", nil, nil); status != fiber.StatusConflict {
+		t.Fatalf("expected 409 deleting a category a rule uses, got %d", status)
+	}
+}
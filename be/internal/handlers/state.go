@@ -10,10 +10,12 @@ import (
 
 type State struct {
 	Svc *services.StateService
+	// AttachmentSvc prunes attachments of transactions the new state drops.
+	AttachmentSvc *services.AttachmentService
 }
 
 func (h State) Get(c *fiber.Ctx) error {
-	out, err := h.Svc.Get(c.Context())
+	out, err := h.Svc.Get(c.UserContext())
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
@@ -25,9 +27,10 @@ func (h State) Replace(c *fiber.Ctx) error {
 	if err := c.BodyParser(&st); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
 	}
-	if err := h.Svc.Replace(c.Context(), st); err != nil {
+	if err := h.Svc.Replace(c.UserContext(), st); err != nil {
 		return httpjson.WriteError(c, err)
 	}
+	h.AttachmentSvc.Prune(c.UserContext())
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
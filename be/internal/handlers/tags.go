@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/services"
+)
+
+const maxTagName = 64
+
+type Tags struct {
+	Svc *services.TagService
+}
+
+func (h Tags) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext())
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Create stores the name lowercased; a duplicate name is a 409.
+func (h Tags) Create(c *fiber.Ctx) error {
+	var in services.CreateTagInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	var ok bool
+	if in.Name, ok = tagName(in.Name); !ok {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	out, err := h.Svc.Create(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+func (h Tags) Update(c *fiber.Ctx) error {
+	var in services.UpdateTagInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	if in.Name != nil {
+		name, ok := tagName(*in.Name)
+		if !ok {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		in.Name = &name
+	}
+	out, err := h.Svc.Update(c.UserContext(), c.Params("id"), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// Delete also removes the tag from its transactions.
+func (h Tags) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// tagName normalizes a tag name and reports whether it is usable.
+func tagName(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return s, s != "" && utf8.RuneCountInString(s) <= maxTagName
+}
+
+// checkTags trims and de-duplicates tag IDs in place and requires each to be
+// one of the user's tags.
+func checkTags(c *fiber.Ctx, svc *services.TagService, ids *[]string) error {
+	out := make([]string, 0, len(*ids))
+	for _, id := range *ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return errs.ErrValidation
+		}
+		if slices.Contains(out, id) {
+			continue
+		}
+		if _, err := svc.Get(c.UserContext(), id); err != nil {
+			return err
+		}
+		out = append(out, id)
+	}
+	*ids = out
+	return nil
+}
@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestTags_FilterAndReport(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "food", Type: models.CategoryExpense, Name: "Food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "hotel", Type: models.CategoryExpense, Name: "Hotel"})
+
+	var trip, gift models.Tag
+	if status := app.doJSON(t, "POST", "/api/v1/tags", map[string]any{"name": " Trip-Bali-2026 "}, &trip); status != fiber.StatusCreated || trip.Name != "trip-bali-2026" {
+		t.Fatalf("create tag: %d %+v", status, trip)
+	}
+	app.doJSON(t, "POST", "/api/v1/tags", map[string]any{"name": "gift"}, &gift)
+
+	var dinner, room models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-05", "categoryId": "food", "amountCents": 200_000_00, "tagIds": []string{trip.ID, trip.ID},
+	}, &dinner)
+	if len(dinner.TagIDs) != 1 || dinner.TagIDs[0] != trip.ID {
+		t.Fatalf("expected one tag on the transaction, got %+v", dinner.TagIDs)
+	}
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-06", "categoryId": "hotel", "amountCents": 1_500_000_00,
+	}, &room)
+	if status := app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-06", "categoryId": "food", "amountCents": 1_00, "tagIds": []string{"nope"},
+	}, nil); status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown tag, got %d", status)
+	}
+	if status := app.doJSON(t, "PATCH", "/api/v1/transactions/"+room.ID, map[string]any{"tagIds": []string{trip.ID, gift.ID}}, &room); status != fiber.StatusOK || len(room.TagIDs) != 2 {
+		t.Fatalf("tag transaction: %d %+v", status, room)
+	}
+
+	var page struct {
+		Items []models.Txn `json:"items"`
+	}
+	app.doJSON(t, "GET", "/api/v1/transactions?tagId="+gift.ID, nil, &page)
+	if len(page.Items) != 1 || page.Items[0].ID != room.ID {
+		t.Fatalf("unexpected tag filter result: %+v", page.Items)
+	}
+
+	var rep models.TagReport
+	app.doJSON(t, "GET", "/api/v1/reports/tags?month=2026-01", nil, &rep)
+	if len(rep.Tags) != 2 || rep.Tags[0].TagID != trip.ID || rep.Tags[0].TotalCents != 1_700_000_00 || rep.Tags[0].Count != 2 {
+		t.Fatalf("unexpected tag report: %+v", rep.Tags)
+	}
+
+	if status := app.doJSON(t, "DELETE", "/api/v1/tags/"+trip.ID, nil, nil); status != fiber.StatusNoContent {
+		t.Fatalf("delete tag: %d", status)
+	}
+	app.doJSON(t, "GET", "/api/v1/transactions?tagId="+gift.ID, nil, &page)
+	if got := page.Items[0].TagIDs; len(got) != 1 || got[0] != gift.ID {
+		t.Fatalf("expected the deleted tag to be gone, got %+v", got)
+	}
+}
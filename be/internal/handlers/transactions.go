@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -8,6 +10,7 @@ import (
 	"personal-budgeting/be/internal/errs"
 	"personal-budgeting/be/internal/httpjson"
 	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
 	"personal-budgeting/be/internal/services"
 	"personal-budgeting/be/internal/validate"
 )
@@ -15,45 +18,207 @@ import (
 type Transactions struct {
 	Svc    *services.TxnService
 	CatSvc *services.CategoryService
+	AccSvc *services.AccountService
+	TagSvc *services.TagService
+	// TransferSvc takes over edits and deletes of transfer legs so both stay in step.
+	TransferSvc *services.TransferService
+	// RuleSvc fills in the category, note and tags of new transactions.
+	RuleSvc *services.RuleService
+	// PayeeSvc matches new transactions without a payee to one by their note.
+	PayeeSvc *services.PayeeService
+	// HomeCurrency applies to new transactions without an account or explicit currency.
+	HomeCurrency string
 }
 
+// List supports filtering and keyset pagination via query params:
+// month, from, to, kind, categoryId, accountId, payeeId, tagId, minAmountCents, maxAmountCents, q (note contains),
+// sort (date_desc|date_asc|amount_desc|amount_asc), cursor, limit.
 func (h Transactions) List(c *fiber.Ctx) error {
-	out, err := h.Svc.List(c.Context())
+	in, err := parseListTxnQuery(c)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Query(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func parseListTxnQuery(c *fiber.Ctx) (services.ListTxnInput, error) {
+	in := services.ListTxnInput{
+		Month:        strings.TrimSpace(c.Query("month")),
+		From:         strings.TrimSpace(c.Query("from")),
+		To:           strings.TrimSpace(c.Query("to")),
+		Kind:         models.TransactionKind(strings.TrimSpace(c.Query("kind"))),
+		CategoryID:   strings.TrimSpace(c.Query("categoryId")),
+		AccountID:    strings.TrimSpace(c.Query("accountId")),
+		PayeeID:      strings.TrimSpace(c.Query("payeeId")),
+		TagID:        strings.TrimSpace(c.Query("tagId")),
+		NoteContains: strings.TrimSpace(c.Query("q")),
+		Sort:         repositories.TxnSort(strings.TrimSpace(c.Query("sort"))),
+		Cursor:       strings.TrimSpace(c.Query("cursor")),
+	}
+	if in.Month != "" && !validate.MonthKey(in.Month) {
+		return in, errs.ErrValidation
+	}
+	if (in.From != "" && !validate.DateKey(in.From)) || (in.To != "" && !validate.DateKey(in.To)) {
+		return in, errs.ErrValidation
+	}
+	if in.Kind != "" && !in.Kind.Valid() {
+		return in, errs.ErrValidation
+	}
+	if in.Sort != "" && !in.Sort.Valid() {
+		return in, errs.ErrValidation
+	}
+	var err error
+	if in.MinAmountCents, err = queryInt64(c, "minAmountCents"); err != nil {
+		return in, err
+	}
+	if in.MaxAmountCents, err = queryInt64(c, "maxAmountCents"); err != nil {
+		return in, err
+	}
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > services.MaxTxnPageSize {
+			return in, errs.ErrValidation
+		}
+		in.Limit = n
+	}
+	return in, nil
+}
+
+// Search ranks transactions by words in their note and category name. It takes
+// q (required) plus the month, from, to and kind filters of List, and limit.
+func (h Transactions) Search(c *fiber.Ctx) error {
+	in := services.SearchTxnInput{
+		Query: strings.TrimSpace(c.Query("q")),
+		Month: strings.TrimSpace(c.Query("month")),
+		From:  strings.TrimSpace(c.Query("from")),
+		To:    strings.TrimSpace(c.Query("to")),
+		Kind:  models.TransactionKind(strings.TrimSpace(c.Query("kind"))),
+	}
+	if len(services.SearchTerms(in.Query)) == 0 {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Month != "" && !validate.MonthKey(in.Month) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if (in.From != "" && !validate.DateKey(in.From)) || (in.To != "" && !validate.DateKey(in.To)) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if in.Kind != "" && !in.Kind.Valid() {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if v := strings.TrimSpace(c.Query("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > services.MaxTxnSearchSize {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+		in.Limit = n
+	}
+	out, err := h.Svc.Search(c.UserContext(), in)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
 	return c.JSON(out)
 }
 
+// queryInt64 reads an optional integer query param; absent means nil.
+func queryInt64(c *fiber.Ctx, key string) (*int64, error) {
+	v := strings.TrimSpace(c.Query(key))
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, errs.ErrValidation
+	}
+	return &n, nil
+}
+
+// Get returns one transaction with its version as the ETag.
+func (h Transactions) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return writeVersioned(c, out, out.Version)
+}
+
 func (h Transactions) Create(c *fiber.Ctx) error {
 	var in services.CreateTxnInput
 	if err := c.BodyParser(&in); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
 	}
 
-	// Validation belongs in handlers.
+	// Payees and rules see the transaction as entered and only fill in what it
+	// leaves open.
 	in.CategoryID = strings.TrimSpace(in.CategoryID)
+	in.AccountID = strings.TrimSpace(in.AccountID)
+	in.PayeeID = strings.TrimSpace(in.PayeeID)
+	in.Note = strings.TrimSpace(in.Note)
+	if err := h.resolvePayee(c, &in); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if err := applyRules(c, h.RuleSvc, &in); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+
+	// Validation belongs in handlers.
+	if len(in.Splits) > 0 {
+		in.CategoryID = strings.TrimSpace(in.Splits[0].CategoryID)
+	}
 	if in.Kind != models.KindIncome && in.Kind != models.KindExpense {
 		return httpjson.WriteError(c, errs.ErrValidation)
 	}
 	if !validate.DateKey(in.Date) || in.CategoryID == "" || in.AmountCents <= 0 {
 		return httpjson.WriteError(c, errs.ErrValidation)
 	}
-	cat, err := h.CatSvc.Get(c.Context(), in.CategoryID)
+	cat, err := h.CatSvc.Get(c.UserContext(), in.CategoryID)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
-	if (in.Kind == models.KindIncome && cat.Type != models.CategoryIncome) || (in.Kind == models.KindExpense && cat.Type != models.CategoryExpense) {
+	if !kindFitsCategory(in.Kind, cat) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	in.Currency = strings.ToUpper(strings.TrimSpace(in.Currency))
+	if in.AccountID != "" {
+		acct, err := activeAccount(c, h.AccSvc, in.AccountID)
+		if err != nil {
+			return httpjson.WriteError(c, err)
+		}
+		// A transaction is always in its account's currency.
+		if in.Currency == "" {
+			in.Currency = acct.Currency
+		}
+		if in.Currency != acct.Currency {
+			return httpjson.WriteError(c, errs.ErrValidation)
+		}
+	}
+	if in.Currency == "" {
+		in.Currency = h.HomeCurrency
+	}
+	if !fitsCurrency(in.Currency, in.AmountCents) {
 		return httpjson.WriteError(c, errs.ErrValidation)
 	}
+	if len(in.Splits) > 0 {
+		if err := h.checkSplits(c, in.Kind, in.Currency, in.AmountCents, in.Splits); err != nil {
+			return httpjson.WriteError(c, err)
+		}
+	}
+	if err := checkTags(c, h.TagSvc, &in.TagIDs); err != nil {
+		return httpjson.WriteError(c, err)
+	}
 
-	out, err := h.Svc.Create(c.Context(), in)
+	out, err := h.Svc.Create(c.UserContext(), in)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
 	return c.Status(fiber.StatusCreated).JSON(out)
 }
 
+// Update honors If-Match: a stale version gets 412 with the current transaction.
 func (h Transactions) Update(c *fiber.Ctx) error {
 	id := c.Params("id")
 	var in services.UpdateTxnInput
@@ -62,12 +227,25 @@ func (h Transactions) Update(c *fiber.Ctx) error {
 	}
 
 	// Validate patch + enforce kind/category compatibility.
-	existing, err := h.Svc.Get(c.Context(), id)
+	existing, err := h.Svc.Get(c.UserContext(), id)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	version, err := ifMatch(c)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
+	if version != 0 && version != existing.Version {
+		return writeStale(c, existing, existing.Version)
+	}
+	in.IfVersion = version
+	if existing.Kind == models.KindTransfer {
+		return h.updateTransferLeg(c, existing, in)
+	}
 	nextKind := existing.Kind
 	nextCatID := existing.CategoryID
+	nextAccountID := existing.AccountID
+	nextAmount := existing.AmountCents
 
 	if in.Kind != nil {
 		if *in.Kind != models.KindIncome && *in.Kind != models.KindExpense {
@@ -88,37 +266,268 @@ func (h Transactions) Update(c *fiber.Ctx) error {
 		nextCatID = trimmed
 		in.CategoryID = &trimmed
 	}
+	if in.AccountID != nil {
+		trimmed := strings.TrimSpace(*in.AccountID)
+		if trimmed != "" && trimmed != existing.AccountID {
+			if err := h.checkAccount(c, trimmed); err != nil {
+				return httpjson.WriteError(c, err)
+			}
+		}
+		nextAccountID = trimmed
+		in.AccountID = &trimmed
+	}
+	if in.PayeeID != nil {
+		trimmed := strings.TrimSpace(*in.PayeeID)
+		if trimmed != "" && trimmed != existing.PayeeID {
+			if err := h.checkPayee(c, trimmed); err != nil {
+				return httpjson.WriteError(c, err)
+			}
+		}
+		in.PayeeID = &trimmed
+	}
 	if in.AmountCents != nil {
 		if *in.AmountCents <= 0 {
 			return httpjson.WriteError(c, errs.ErrValidation)
 		}
+		nextAmount = *in.AmountCents
+	}
+	if in.Currency != nil {
+		ccy := strings.ToUpper(strings.TrimSpace(*in.Currency))
+		in.Currency = &ccy
+	}
+	if err := h.checkCurrency(c, existing, nextAccountID, nextAmount, &in); err != nil {
+		return httpjson.WriteError(c, err)
 	}
 	if in.Note != nil {
 		trimmed := strings.TrimSpace(*in.Note)
 		in.Note = &trimmed
 	}
+	if in.TagIDs != nil {
+		if err := checkTags(c, h.TagSvc, in.TagIDs); err != nil {
+			return httpjson.WriteError(c, err)
+		}
+	}
+
+	// A split transaction's lines must still add up after the patch; its
+	// category follows the first line, so it can't be set on its own.
+	nextSplits := existing.Splits
+	if in.Splits != nil {
+		nextSplits = *in.Splits
+	} else if len(nextSplits) > 0 && in.CategoryID != nil {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	if len(nextSplits) > 0 {
+		nextCcy := existing.Currency
+		if in.Currency != nil {
+			nextCcy = *in.Currency
+		}
+		if err := h.checkSplits(c, nextKind, nextCcy, nextAmount, nextSplits); err != nil {
+			return httpjson.WriteError(c, err)
+		}
+		nextCatID = nextSplits[0].CategoryID
+		if in.Splits != nil {
+			in.CategoryID = &nextCatID
+		}
+	}
 
-	cat, err := h.CatSvc.Get(c.Context(), nextCatID)
+	cat, err := h.CatSvc.Get(c.UserContext(), nextCatID)
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
-	if (nextKind == models.KindIncome && cat.Type != models.CategoryIncome) || (nextKind == models.KindExpense && cat.Type != models.CategoryExpense) {
+	if !kindFitsCategory(nextKind, cat) {
 		return httpjson.WriteError(c, errs.ErrValidation)
 	}
 
-	out, err := h.Svc.Update(c.Context(), id, in)
+	out, err := h.Svc.Update(c.UserContext(), id, in)
+	if errors.Is(err, errs.ErrPreconditionFailed) {
+		return h.stale(c, id)
+	}
 	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
-	return c.JSON(out)
+	return writeVersioned(c, out, out.Version)
+}
+
+// stale answers a PATCH that lost a race with another write.
+func (h Transactions) stale(c *fiber.Ctx, id string) error {
+	current, err := h.Svc.Get(c.UserContext(), id)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return writeStale(c, current, current.Version)
 }
 
+// Delete moves the transaction, or both legs of a transfer, to the trash. Its
+// attachments stay until it is purged.
 func (h Transactions) Delete(c *fiber.Ctx) error {
 	id := c.Params("id")
-	if err := h.Svc.Delete(c.Context(), id); err != nil {
+	existing, err := h.Svc.Get(c.UserContext(), id)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if existing.Kind == models.KindTransfer {
+		err = h.TransferSvc.Delete(c.UserContext(), existing.TransferID)
+	} else {
+		err = h.Svc.Delete(c.UserContext(), id)
+	}
+	if err != nil {
 		return httpjson.WriteError(c, err)
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// updateTransferLeg maps a PATCH on one leg onto its transfer. Kind, category,
+// payee, tags and splits don't apply to transfers; the account belongs to this leg's side.
+func (h Transactions) updateTransferLeg(c *fiber.Ctx, leg models.Txn, in services.UpdateTxnInput) error {
+	if in.Kind != nil || in.CategoryID != nil || in.PayeeID != nil || in.TagIDs != nil || in.Splits != nil {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	patch := services.UpdateTransferInput{Date: in.Date, AmountCents: in.AmountCents, Note: in.Note, LegID: leg.ID, IfLegVersion: in.IfVersion}
+	if leg.TransferDirection == models.TransferOut {
+		patch.FromAccountID = in.AccountID
+	} else {
+		patch.ToAccountID = in.AccountID
+	}
+	if _, err := updateTransfer(c, h.TransferSvc, h.AccSvc, leg.TransferID, patch); err != nil {
+		if errors.Is(err, errs.ErrPreconditionFailed) {
+			return h.stale(c, leg.ID)
+		}
+		return httpjson.WriteError(c, err)
+	}
+	out, err := h.Svc.Get(c.UserContext(), leg.ID)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return writeVersioned(c, out, out.Version)
+}
 
+// checkCurrency resolves the currency a patched transaction ends up in. Moving
+// to another account adopts that account's currency unless one is given, and the
+// result must match the account and fit the amount's minor units.
+func (h Transactions) checkCurrency(c *fiber.Ctx, existing models.Txn, accountID string, amount int64, in *services.UpdateTxnInput) error {
+	next := existing.Currency
+	if in.Currency != nil {
+		next = *in.Currency
+	}
+	if accountID != "" && (in.Currency != nil || accountID != existing.AccountID) {
+		acct, err := h.AccSvc.Get(c.UserContext(), accountID)
+		if err != nil {
+			return err
+		}
+		if in.Currency == nil {
+			next = acct.Currency
+			in.Currency = &next
+		}
+		if next != acct.Currency {
+			return errs.ErrValidation
+		}
+	}
+	if !fitsCurrency(next, amount) {
+		return errs.ErrValidation
+	}
+	return nil
+}
+
+// checkSplits validates the lines of a split transaction in place: at least two
+// of them, each positive and in a category matching kind, adding up to amount.
+func (h Transactions) checkSplits(c *fiber.Ctx, kind models.TransactionKind, ccy string, amount int64, lines []models.TxnSplit) error {
+	if len(lines) < 2 {
+		return errs.ErrValidation
+	}
+	cats := map[string]models.Category{}
+	var sum int64
+	for i := range lines {
+		l := &lines[i]
+		l.CategoryID = strings.TrimSpace(l.CategoryID)
+		l.Note = strings.TrimSpace(l.Note)
+		if l.CategoryID == "" || l.AmountCents <= 0 || !fitsCurrency(ccy, l.AmountCents) {
+			return errs.ErrValidation
+		}
+		sum += l.AmountCents
+		cat, ok := cats[l.CategoryID]
+		if !ok {
+			var err error
+			if cat, err = h.CatSvc.Get(c.UserContext(), l.CategoryID); err != nil {
+				return err
+			}
+			cats[l.CategoryID] = cat
+		}
+		if !kindFitsCategory(kind, cat) {
+			return errs.ErrValidation
+		}
+	}
+	if sum != amount {
+		return errs.ErrValidation
+	}
+	return nil
+}
+
+// resolvePayee checks a chosen payee, or else matches one by the note.
+func (h Transactions) resolvePayee(c *fiber.Ctx, in *services.CreateTxnInput) error {
+	if in.PayeeID != "" {
+		return h.checkPayee(c, in.PayeeID)
+	}
+	m, err := h.PayeeSvc.Matcher(c.UserContext())
+	if err != nil {
+		return err
+	}
+	if p, ok := m.Match(in.Note); ok {
+		in.PayeeID = p.ID
+	}
+	return nil
+}
+
+// checkPayee requires payeeID to be one of the user's payees.
+func (h Transactions) checkPayee(c *fiber.Ctx, payeeID string) error {
+	_, err := h.PayeeSvc.Get(c.UserContext(), payeeID)
+	if errors.Is(err, errs.ErrNotFound) {
+		return errs.ErrValidation
+	}
+	return err
+}
+
+// applyRules runs the user's rules over a new transaction in place.
+func applyRules(c *fiber.Ctx, svc *services.RuleService, in *services.CreateTxnInput) error {
+	eng, err := svc.Engine(c.UserContext())
+	if err != nil {
+		return err
+	}
+	t := eng.Apply(models.Txn{
+		Kind:        in.Kind,
+		CategoryID:  in.CategoryID,
+		AccountID:   in.AccountID,
+		AmountCents: in.AmountCents,
+		Note:        in.Note,
+		TagIDs:      in.TagIDs,
+		Splits:      in.Splits,
+	})
+	in.CategoryID, in.Note, in.TagIDs = t.CategoryID, t.Note, t.TagIDs
+	return nil
+}
+
+// kindFitsCategory reports whether a transaction of kind may use cat.
+func kindFitsCategory(kind models.TransactionKind, cat models.Category) bool {
+	return (kind == models.KindIncome && cat.Type == models.CategoryIncome) || (kind == models.KindExpense && cat.Type == models.CategoryExpense)
+}
+
+// checkAccount allows attaching a transaction only to an existing, active account.
+func (h Transactions) checkAccount(c *fiber.Ctx, accountID string) error {
+	_, err := activeAccount(c, h.AccSvc, accountID)
+	return err
+}
+
+// activeAccount loads an account a transaction may be attached to. Unknown and
+// archived accounts are validation errors.
+func activeAccount(c *fiber.Ctx, svc *services.AccountService, accountID string) (models.Account, error) {
+	acct, err := svc.Get(c.UserContext(), accountID)
+	if errors.Is(err, errs.ErrNotFound) {
+		return models.Account{}, errs.ErrValidation
+	}
+	if err != nil {
+		return models.Account{}, err
+	}
+	if acct.Archived {
+		return models.Account{}, errs.ErrValidation
+	}
+	return acct, nil
+}
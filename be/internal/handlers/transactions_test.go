@@ -0,0 +1,219 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestTransactions_Splits(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "groceries", Type: models.CategoryExpense, Name: "Groceries"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "household", Type: models.CategoryExpense, Name: "Household"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "salary", Type: models.CategoryIncome, Name: "Salary"})
+
+	if status := app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-03-02", "amountCents": 100_00,
+		"splits": []map[string]any{{"categoryId": "groceries", "amountCents": 60_00}, {"categoryId": "household", "amountCents": 30_00}},
+	}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 when lines don't add up, got %d", status)
+	}
+	if status := app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-03-02", "amountCents": 100_00,
+		"splits": []map[string]any{{"categoryId": "groceries", "amountCents": 60_00}, {"categoryId": "salary", "amountCents": 40_00}},
+	}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for an income line on an expense, got %d", status)
+	}
+
+	var receipt models.Txn
+	if status := app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-03-02", "amountCents": 100_00,
+		"splits": []map[string]any{{"categoryId": "groceries", "amountCents": 70_00}, {"categoryId": "household", "amountCents": 30_00, "note": " soap "}},
+	}, &receipt); status != fiber.StatusCreated {
+		t.Fatalf("create split: %d", status)
+	}
+	if receipt.CategoryID != "groceries" || len(receipt.Splits) != 2 || receipt.Splits[1].Note != "soap" {
+		t.Fatalf("unexpected split transaction: %+v", receipt)
+	}
+
+	var page struct {
+		Items []models.Txn `json:"items"`
+	}
+	app.doJSON(t, "GET", "/api/v1/transactions?categoryId=household", nil, &page)
+	if len(page.Items) != 1 || page.Items[0].ID != receipt.ID {
+		t.Fatalf("expected the split transaction under its second line's category, got %+v", page.Items)
+	}
+
+	var sum models.Summary
+	app.doJSON(t, "GET", "/api/v1/summary?month=2026-03", nil, &sum)
+	totals := map[string]int64{}
+	for _, ct := range sum.Categories {
+		totals[ct.CategoryID] = ct.TotalCents
+	}
+	if sum.ExpenseCents != 100_00 || totals["groceries"] != 70_00 || totals["household"] != 30_00 {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+
+	if status := app.doJSON(t, "PATCH", "/api/v1/transactions/"+receipt.ID, map[string]any{"amountCents": 120_00}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for an amount that no longer matches the lines, got %d", status)
+	}
+	if status := app.doJSON(t, "DELETE", "/api/v1/categories/household", nil, nil); status != fiber.StatusConflict {
+		t.Fatalf("expected 409 deleting a category used by a split line, got %d", status)
+	}
+	var unsplit models.Txn
+	if status := app.doJSON(t, "PATCH", "/api/v1/transactions/"+receipt.ID, map[string]any{"splits": []any{}}, &unsplit); status != fiber.StatusOK || len(unsplit.Splits) != 0 || unsplit.CategoryID != "groceries" {
+		t.Fatalf("un-split: %d %+v", status, unsplit)
+	}
+	app.doJSON(t, "GET", "/api/v1/summary?month=2026-03", nil, &sum)
+	if len(sum.Categories) != 1 || sum.Categories[0].TotalCents != 100_00 {
+		t.Fatalf("expected the whole amount back under one category, got %+v", sum.Categories)
+	}
+}
+
+func TestTransactions_Search(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "gadgets", Type: models.CategoryExpense, Name: "Electronics"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "food", Type: models.CategoryExpense, Name: "Food"})
+
+	var headphones, snacks, cable models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-04-10", "categoryId": "gadgets", "amountCents": 750_000_00, "note": "Tokopedia order: <b>headphones</b>",
+	}, &headphones)
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-04-12", "categoryId": "food", "amountCents": 80_000_00, "note": "Snacks from Tokopedia",
+	}, &snacks)
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-06-01", "categoryId": "gadgets", "amountCents": 50_000_00, "note": "USB cable",
+	}, &cable)
+
+	var res struct {
+		Items []struct {
+			Transaction models.Txn `json:"transaction"`
+			Snippet     string     `json:"snippet"`
+		} `json:"items"`
+	}
+	if status := app.doJSON(t, "GET", "/api/v1/transactions/search?q=tokopedia+electronics", nil, &res); status != fiber.StatusOK {
+		t.Fatalf("search: %d", status)
+	}
+	if len(res.Items) != 3 || res.Items[0].Transaction.ID != headphones.ID {
+		t.Fatalf("expected the order matching both words first, got %+v", res.Items)
+	}
+	if got := res.Items[0].Snippet; got != "<mark>Tokopedia</mark> order: &lt;b&gt;headphones&lt;/b&gt;" {
+		t.Fatalf("unexpected snippet %q", got)
+	}
+	// Single-word matches tie and fall back to newest first.
+	if got := res.Items[1].Snippet; res.Items[1].Transaction.ID != cable.ID || got != "<mark>Electronics</mark>" {
+		t.Fatalf("expected a category-name snippet for the cable, got %+v", res.Items[1])
+	}
+
+	app.doJSON(t, "GET", "/api/v1/transactions/search?q=Tokopedia&month=2026-04&kind=expense&limit=1", nil, &res)
+	if len(res.Items) != 1 || res.Items[0].Transaction.ID != snacks.ID {
+		t.Fatalf("expected the newest match within the filters, got %+v", res.Items)
+	}
+	if status := app.doJSON(t, "GET", "/api/v1/transactions/search?q=+%21%21", nil, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for a query without words, got %d", status)
+	}
+}
+
+// patchIfMatch sends a JSON PATCH with an If-Match header and returns the
+// status, the ETag and the decoded body.
+func patchIfMatch(t *testing.T, app *testApp, path string, tag string, body any, out any) (int, string) {
+	t.Helper()
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest("PATCH", path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(fiber.HeaderIfMatch, tag)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("PATCH %s: %v", path, err)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("PATCH %s: decode: %v", path, err)
+	}
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderETag)
+}
+
+func TestTransactions_IfMatch(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "groceries", Type: models.CategoryExpense, Name: "Groceries"})
+
+	var txn models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-03-02", "categoryId": "groceries", "amountCents": 100_00,
+	}, &txn)
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/transactions/"+txn.ID, nil))
+	if err != nil || resp.StatusCode != fiber.StatusOK || resp.Header.Get(fiber.HeaderETag) != `"1"` {
+		t.Fatalf("expected ETag \"1\" on GET, got %v %v", resp.Header.Get(fiber.HeaderETag), err)
+	}
+
+	// The first tab saves against version 1; the second, still on 1, is refused.
+	var first models.Txn
+	status, tag := patchIfMatch(t, app, "/api/v1/transactions/"+txn.ID, `"1"`, map[string]any{"note": "first tab"}, &first)
+	if status != fiber.StatusOK || tag != `"2"` || first.Version != 2 {
+		t.Fatalf("first write: %d %s %+v", status, tag, first)
+	}
+	var stale struct {
+		httpjson.ErrorResponse
+		Current models.Txn `json:"current"`
+	}
+	status, tag = patchIfMatch(t, app, "/api/v1/transactions/"+txn.ID, `"1"`, map[string]any{"note": "second tab"}, &stale)
+	if status != fiber.StatusPreconditionFailed || tag != `"2"` || stale.Error != "precondition_failed" || stale.Current.Note != "first tab" {
+		t.Fatalf("expected 412 with the first tab's version, got %d %s %+v", status, tag, stale)
+	}
+	if status, _ = patchIfMatch(t, app, "/api/v1/transactions/"+txn.ID, `W/"1"`, map[string]any{"note": "x"}, &stale); status != fiber.StatusPreconditionFailed {
+		t.Fatalf("stale weak tag: expected 412, got %d", status)
+	}
+	for _, bad := range []string{"2", `"x"`, `"2`, `"2", "3"`} {
+		var e httpjson.ErrorResponse
+		if status, _ = patchIfMatch(t, app, "/api/v1/transactions/"+txn.ID, bad, map[string]any{"note": "x"}, &e); status != fiber.StatusBadRequest {
+			t.Fatalf("If-Match %s: expected 400, got %d", bad, status)
+		}
+	}
+	// A proxy may weaken the tag; it still names the current version.
+	status, tag = patchIfMatch(t, app, "/api/v1/transactions/"+txn.ID, `W/"2"`, map[string]any{"note": "first tab again"}, &first)
+	if status != fiber.StatusOK || tag != `"3"` {
+		t.Fatalf("weak current tag: %d %s", status, tag)
+	}
+
+	// A repository write with a version that moved on is refused too, which is
+	// what closes the race between the handler's check and the update.
+	note := "racing"
+	if _, err := repositories.NewGormTxnRepo(gdb).Update(ctx, txn.ID, repositories.TxnPatch{Note: &note, IfVersion: 1}); !errors.Is(err, errs.ErrPreconditionFailed) {
+		t.Fatalf("expected a stale repository update to fail, got %v", err)
+	}
+
+	// Without If-Match the write goes through as before.
+	if status := app.doJSON(t, "PATCH", "/api/v1/transactions/"+txn.ID, map[string]any{"note": "no header"}, &first); status != fiber.StatusOK || first.Version != 4 {
+		t.Fatalf("unconditional write: %d %+v", status, first)
+	}
+	if status, _ = patchIfMatch(t, app, "/api/v1/transactions/"+txn.ID, "*", map[string]any{"note": "any"}, &first); status != fiber.StatusOK || first.Version != 5 {
+		t.Fatalf("If-Match *: %d %+v", status, first)
+	}
+}
+
+func TestTransactions_ClientCannotSetExternalID(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	_, _ = repositories.NewGormCategoryRepo(gdb).Create(ctx, models.Category{ID: "groceries", Type: models.CategoryExpense, Name: "Groceries"})
+
+	var txn models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-03-02", "categoryId": "groceries", "amountCents": 100_00, "externalId": "ofx:bca:123",
+	}, &txn)
+	got, err := repositories.NewGormTxnRepo(gdb).Get(ctx, txn.ID)
+	if err != nil || got.ExternalID != "" {
+		t.Fatalf("expected the client's externalId to be ignored, got %+v %v", got, err)
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/validate"
+)
+
+type Transfers struct {
+	Svc    *services.TransferService
+	AccSvc *services.AccountService
+}
+
+func (h Transfers) Get(c *fiber.Ctx) error {
+	out, err := h.Svc.Get(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Transfers) Create(c *fiber.Ctx) error {
+	var in services.CreateTransferInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	in.FromAccountID = strings.TrimSpace(in.FromAccountID)
+	in.ToAccountID = strings.TrimSpace(in.ToAccountID)
+	if !validate.DateKey(in.Date) || in.AmountCents <= 0 || in.FromAccountID == "" || in.ToAccountID == "" {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	ccy, err := checkTransferAccounts(c, h.AccSvc, in.FromAccountID, in.ToAccountID, models.Transfer{})
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	if !fitsCurrency(ccy, in.AmountCents) {
+		return httpjson.WriteError(c, errs.ErrValidation)
+	}
+	in.Currency = ccy
+	out, err := h.Svc.Create(c.UserContext(), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(out)
+}
+
+func (h Transfers) Update(c *fiber.Ctx) error {
+	var in services.UpdateTransferInput
+	if err := c.BodyParser(&in); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(httpjson.ErrorResponse{Error: "bad_json"})
+	}
+	out, err := updateTransfer(c, h.Svc, h.AccSvc, c.Params("id"), in)
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+func (h Transfers) Delete(c *fiber.Ctx) error {
+	if err := h.Svc.Delete(c.UserContext(), c.Params("id")); err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// updateTransfer validates a patch against the current transfer and applies it.
+// PATCH /transactions/:id on either leg ends up here too.
+func updateTransfer(c *fiber.Ctx, svc *services.TransferService, accSvc *services.AccountService, transferID string, in services.UpdateTransferInput) (models.Transfer, error) {
+	current, err := svc.Get(c.UserContext(), transferID)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	if in.Date != nil && !validate.DateKey(*in.Date) {
+		return models.Transfer{}, errs.ErrValidation
+	}
+	if in.AmountCents != nil && *in.AmountCents <= 0 {
+		return models.Transfer{}, errs.ErrValidation
+	}
+	from, to := current.FromAccountID, current.ToAccountID
+	if in.FromAccountID != nil {
+		from = strings.TrimSpace(*in.FromAccountID)
+		in.FromAccountID = &from
+	}
+	if in.ToAccountID != nil {
+		to = strings.TrimSpace(*in.ToAccountID)
+		in.ToAccountID = &to
+	}
+	if from == "" || to == "" {
+		return models.Transfer{}, errs.ErrValidation
+	}
+	ccy, err := checkTransferAccounts(c, accSvc, from, to, current)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	amount := current.AmountCents
+	if in.AmountCents != nil {
+		amount = *in.AmountCents
+	}
+	if !fitsCurrency(ccy, amount) {
+		return models.Transfer{}, errs.ErrValidation
+	}
+	if ccy != current.Currency {
+		in.Currency = &ccy
+	}
+	return svc.Update(c.UserContext(), transferID, in)
+}
+
+// checkTransferAccounts requires two different accounts in the same currency
+// and returns that currency. Accounts new to the transfer must also be active;
+// ones it already uses may have been archived since.
+func checkTransferAccounts(c *fiber.Ctx, accSvc *services.AccountService, fromID string, toID string, current models.Transfer) (string, error) {
+	if fromID == toID {
+		return "", errs.ErrValidation
+	}
+	load := func(id string) (models.Account, error) {
+		if id == current.FromAccountID || id == current.ToAccountID {
+			acct, err := accSvc.Get(c.UserContext(), id)
+			if errors.Is(err, errs.ErrNotFound) {
+				return models.Account{}, errs.ErrValidation
+			}
+			return acct, err
+		}
+		return activeAccount(c, accSvc, id)
+	}
+	from, err := load(fromID)
+	if err != nil {
+		return "", err
+	}
+	to, err := load(toID)
+	if err != nil {
+		return "", err
+	}
+	if from.Currency != to.Currency {
+		return "", errs.ErrValidation
+	}
+	return from.Currency, nil
+}
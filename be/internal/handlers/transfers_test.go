@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+func TestTransfers_PairStaysConsistent(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	accRepo := repositories.NewGormAccountRepo(gdb)
+	_, _ = accRepo.Create(ctx, models.Account{ID: "savings", Name: "Savings", Type: models.AccountBank, OpeningBalanceCents: 1000_00})
+	_, _ = accRepo.Create(ctx, models.Account{ID: "checking", Name: "Checking", Type: models.AccountBank})
+	_, _ = accRepo.Create(ctx, models.Account{ID: "usd", Name: "USD", Type: models.AccountBank, Currency: "USD"})
+
+	var tr models.Transfer
+	status := app.doJSON(t, "POST", "/api/v1/transfers", map[string]any{
+		"fromAccountId": "savings", "toAccountId": "checking", "date": "2026-01-10", "amountCents": 300_00,
+	}, &tr)
+	if status != fiber.StatusCreated || tr.OutTxnID == "" || tr.InTxnID == "" {
+		t.Fatalf("create transfer: %d %+v", status, tr)
+	}
+	if status := app.doJSON(t, "POST", "/api/v1/transfers", map[string]any{
+		"fromAccountId": "savings", "toAccountId": "usd", "date": "2026-01-10", "amountCents": 1_00,
+	}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 for cross-currency transfer, got %d", status)
+	}
+
+	var sum models.Summary
+	app.doJSON(t, "GET", "/api/v1/summary?month=2026-01", nil, &sum)
+	if sum.IncomeCents != 0 || sum.ExpenseCents != 0 || len(sum.Categories) != 0 {
+		t.Fatalf("transfers must not count as income or expense: %+v", sum)
+	}
+
+	// Editing one leg moves the other with it.
+	var leg models.Txn
+	if status := app.doJSON(t, "PATCH", "/api/v1/transactions/"+tr.InTxnID, map[string]any{"amountCents": 400_00}, &leg); status != fiber.StatusOK {
+		t.Fatalf("patch leg: %d", status)
+	}
+	if status := app.doJSON(t, "PATCH", "/api/v1/transactions/"+tr.InTxnID, map[string]any{"categoryId": "x"}, nil); status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 setting a category on a transfer leg, got %d", status)
+	}
+	app.doJSON(t, "GET", "/api/v1/transfers/"+tr.ID, nil, &tr)
+	if tr.AmountCents != 400_00 {
+		t.Fatalf("expected both legs updated, got %+v", tr)
+	}
+	var bal models.AccountBalance
+	app.doJSON(t, "GET", "/api/v1/accounts/savings/balance", nil, &bal)
+	if bal.EndBalanceCents != 600_00 {
+		t.Fatalf("expected savings at 600.00, got %d", bal.EndBalanceCents)
+	}
+
+	// Deleting one leg deletes the transfer.
+	if status := app.doJSON(t, "DELETE", "/api/v1/transactions/"+tr.OutTxnID, nil, nil); status != fiber.StatusNoContent {
+		t.Fatalf("delete leg: %d", status)
+	}
+	var n int64
+	gdb.Table("transactions").Where("deleted_at IS NULL").Count(&n)
+	if n != 0 {
+		t.Fatalf("expected both legs gone, %d rows left", n)
+	}
+	if status := app.doJSON(t, "GET", "/api/v1/transfers/"+tr.ID, nil, nil); status != fiber.StatusNotFound {
+		t.Fatalf("expected transfer gone, got %d", status)
+	}
+	// Restoring one leg from the trash brings the whole transfer back.
+	if status := app.doJSON(t, "POST", "/api/v1/trash/transactions/"+tr.InTxnID+"/restore", nil, nil); status != fiber.StatusOK {
+		t.Fatalf("restore leg: %d", status)
+	}
+	if status := app.doJSON(t, "GET", "/api/v1/transfers/"+tr.ID, nil, nil); status != fiber.StatusOK {
+		t.Fatalf("expected transfer back, got %d", status)
+	}
+}
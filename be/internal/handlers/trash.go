@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"personal-budgeting/be/internal/httpjson"
+	"personal-budgeting/be/internal/services"
+)
+
+type Trash struct {
+	Svc *services.TrashService
+}
+
+// List returns deleted transactions, categories and budgets that have not been
+// purged yet.
+func (h Trash) List(c *fiber.Ctx) error {
+	out, err := h.Svc.List(c.UserContext())
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// RestoreTransaction is a 409 while the transaction's category is deleted.
+// Restoring either leg of a transfer restores both.
+func (h Trash) RestoreTransaction(c *fiber.Ctx) error {
+	out, err := h.Svc.RestoreTxn(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// RestoreCategory is a 409 while the category's parent is deleted.
+func (h Trash) RestoreCategory(c *fiber.Ctx) error {
+	out, err := h.Svc.RestoreCategory(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
+
+// RestoreBudget is a 409 while the budget's category is deleted.
+func (h Trash) RestoreBudget(c *fiber.Ctx) error {
+	out, err := h.Svc.RestoreBudget(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return httpjson.WriteError(c, err)
+	}
+	return c.JSON(out)
+}
@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/blob"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/services"
+	"personal-budgeting/be/internal/testutil"
+)
+
+// newTrashPurger returns a TrashService whose clock is a day past the default
+// retention, so everything trashed so far is due for purging.
+func newTrashPurger(t *testing.T, gdb *gorm.DB) *services.TrashService {
+	t.Helper()
+	blobs, err := blob.NewLocalFS(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	clk := testutil.FixedClock{T: time.Now().Add(services.DefaultTrashRetention + 24*time.Hour)}
+	attachments := services.NewAttachmentService(clk, &testutil.SeqID{}, repositories.NewGormAttachmentRepo(gdb), blobs)
+	return services.NewTrashService(clk, services.DefaultTrashRetention, repositories.NewGormTransactor(gdb),
+		repositories.NewGormCategoryRepo(gdb), repositories.NewGormBudgetRepo(gdb), repositories.NewGormTxnRepo(gdb), attachments)
+}
+
+func TestTrash_RestoreChecksReferencesAndPurge(t *testing.T) {
+	app, gdb, ctx := newTestApp(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "food", Type: models.CategoryExpense, Name: "Food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "snacks", Type: models.CategoryExpense, Name: "Snacks", ParentID: "food"})
+
+	var txn models.Txn
+	app.doJSON(t, "POST", "/api/v1/transactions", map[string]any{
+		"kind": "expense", "date": "2026-01-05", "categoryId": "snacks", "amountCents": 30_000_00,
+	}, &txn)
+	var budget models.Budget
+	app.doJSON(t, "PUT", "/api/v1/budgets", map[string]any{"month": "2026-01", "categoryId": "food", "amountCents": 500_000_00}, &budget)
+
+	if status := app.doJSON(t, "DELETE", "/api/v1/transactions/"+txn.ID, nil, nil); status != fiber.StatusNoContent {
+		t.Fatalf("delete transaction: %d", status)
+	}
+	var sum models.Summary
+	app.doJSON(t, "GET", "/api/v1/summary?month=2026-01", nil, &sum)
+	if sum.ExpenseCents != 0 || len(sum.Categories) != 0 {
+		t.Fatalf("expected reports to skip the trashed transaction, got %+v", sum)
+	}
+	app.doJSON(t, "DELETE", "/api/v1/budgets/"+budget.ID, nil, nil)
+	for _, id := range []string{"snacks", "food"} {
+		if status := app.doJSON(t, "DELETE", "/api/v1/categories/"+id, nil, nil); status != fiber.StatusNoContent {
+			t.Fatalf("delete category %s: %d", id, status)
+		}
+	}
+
+	var trash models.Trash
+	app.doJSON(t, "GET", "/api/v1/trash", nil, &trash)
+	if len(trash.Transactions) != 1 || trash.Transactions[0].DeletedAt == "" || len(trash.Categories) != 2 || len(trash.Budgets) != 1 {
+		t.Fatalf("unexpected trash: %+v", trash)
+	}
+
+	// Each restore needs what it refers to back first.
+	for _, path := range []string{"transactions/" + txn.ID, "categories/snacks", "budgets/" + budget.ID} {
+		if status := app.doJSON(t, "POST", "/api/v1/trash/"+path+"/restore", nil, nil); status != fiber.StatusConflict {
+			t.Fatalf("restore %s: expected 409, got %d", path, status)
+		}
+	}
+	for _, path := range []string{"categories/food", "categories/snacks", "transactions/" + txn.ID, "budgets/" + budget.ID} {
+		if status := app.doJSON(t, "POST", "/api/v1/trash/"+path+"/restore", nil, nil); status != fiber.StatusOK {
+			t.Fatalf("restore %s: %d", path, status)
+		}
+	}
+	if status := app.doJSON(t, "POST", "/api/v1/trash/budgets/"+budget.ID+"/restore", nil, nil); status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 restoring what is not in the trash, got %d", status)
+	}
+	app.doJSON(t, "GET", "/api/v1/summary?month=2026-01", nil, &sum)
+	if sum.ExpenseCents != 30_000_00 {
+		t.Fatalf("expected the restored transaction back in reports, got %+v", sum)
+	}
+	app.doJSON(t, "GET", "/api/v1/trash", nil, &trash)
+	if len(trash.Transactions)+len(trash.Categories)+len(trash.Budgets) != 0 {
+		t.Fatalf("expected an empty trash, got %+v", trash)
+	}
+
+	app.doJSON(t, "DELETE", "/api/v1/transactions/"+txn.ID, nil, nil)
+	app.doJSON(t, "DELETE", "/api/v1/budgets/"+budget.ID, nil, nil)
+	app.doJSON(t, "DELETE", "/api/v1/categories/snacks", nil, nil)
+	n, err := newTrashPurger(t, gdb).PurgeExpired(ctx)
+	if err != nil || n != 3 {
+		t.Fatalf("purge: %d %v", n, err)
+	}
+	var left int64
+	gdb.Table("transactions").Count(&left)
+	if left != 0 {
+		t.Fatalf("expected purged transactions to be gone, %d rows left", left)
+	}
+	app.doJSON(t, "GET", "/api/v1/trash", nil, &trash)
+	if len(trash.Transactions)+len(trash.Categories)+len(trash.Budgets) != 0 {
+		t.Fatalf("expected an empty trash after purging, got %+v", trash)
+	}
+}
@@ -9,17 +9,27 @@ import (
 )
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error    string         `json:"error"`
+	Problems []errs.Problem `json:"problems,omitempty"`
+	// Current is the server's version of what a stale write targeted.
+	Current any `json:"current,omitempty"`
 }
 
 func WriteError(c *fiber.Ctx, err error) error {
+	var verr *errs.ValidationError
 	switch {
+	case errors.As(err, &verr):
+		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "validation", Problems: verr.Problems})
 	case errors.Is(err, errs.ErrValidation):
 		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{Error: "validation"})
+	case errors.Is(err, errs.ErrUnauthorized):
+		return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{Error: "unauthorized"})
 	case errors.Is(err, errs.ErrNotFound):
 		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{Error: "not_found"})
 	case errors.Is(err, errs.ErrConflict):
 		return c.Status(fiber.StatusConflict).JSON(ErrorResponse{Error: "conflict"})
+	case errors.Is(err, errs.ErrPreconditionFailed):
+		return c.Status(fiber.StatusPreconditionFailed).JSON(ErrorResponse{Error: "precondition_failed"})
 	default:
 		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{Error: "internal"})
 	}
@@ -0,0 +1,19 @@
+// Package imports turns external files (bank CSV exports, statements) into
+// transaction candidates. Parsers never touch the database; resolving categories,
+// validating and committing is done by services.ImportService.
+package imports
+
+import "personal-budgeting/be/internal/models"
+
+// Candidate is one parsed row. Errors holds per-row parse problems; a candidate
+// with errors is reported in previews but never committed.
+type Candidate struct {
+	Row         int // 1-based line/entry number in the source file
+	Kind        models.TransactionKind
+	Date        string // YYYY-MM-DD
+	AmountCents int64  // always positive; direction is carried by Kind
+	Note        string
+	Category    string // raw category name or ID from the file, if mapped
+	ExternalID  string // stable source identifier used to skip re-imports
+	Errors      []string
+}
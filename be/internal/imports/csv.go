@@ -0,0 +1,275 @@
+package imports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/money"
+)
+
+// SignConvention says how a CSV row's direction (income vs expense) is encoded.
+type SignConvention string
+
+const (
+	// SignNegativeIsExpense: one signed amount column, negative = money out.
+	SignNegativeIsExpense SignConvention = "negativeIsExpense"
+	// SignNegativeIsIncome: one signed amount column, negative = money in (card statements).
+	SignNegativeIsIncome SignConvention = "negativeIsIncome"
+	// SignDebitCredit: separate debit (out) and credit (in) amount columns.
+	SignDebitCredit SignConvention = "debitCredit"
+	// SignKindColumn: unsigned amount plus a column naming the direction (e.g. "DB"/"CR").
+	SignKindColumn SignConvention = "kindColumn"
+)
+
+// CSVMapping maps CSV columns onto transaction fields. Column references are
+// header names (case-insensitive) when HasHeader is set, otherwise 0-based indexes.
+type CSVMapping struct {
+	HasHeader  bool               `json:"hasHeader"`
+	Delimiter  string             `json:"delimiter,omitempty"` // default ","
+	Date       string             `json:"date"`
+	DateFormat string             `json:"dateFormat,omitempty"` // e.g. "DD/MM/YYYY"; default "YYYY-MM-DD"
+	Amount     string             `json:"amount,omitempty"`
+	Debit      string             `json:"debit,omitempty"`
+	Credit     string             `json:"credit,omitempty"`
+	Kind       string             `json:"kind,omitempty"`
+	Sign       SignConvention     `json:"sign,omitempty"`    // default negativeIsExpense
+	Decimal    money.DecimalStyle `json:"decimal,omitempty"` // default comma (id-ID)
+	Note       string             `json:"note,omitempty"`
+	Category   string             `json:"category,omitempty"`
+}
+
+var ErrBadMapping = errors.New("invalid csv mapping")
+
+// ParseCSV reads every data row of r using m. Structural problems (unreadable CSV,
+// unknown columns) fail the whole parse; bad values are reported per row.
+func ParseCSV(r io.Reader, m CSVMapping) ([]Candidate, error) {
+	m = m.withDefaults()
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	layout, err := dateLayout(m.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(r)
+	cr.Comma = []rune(m.Delimiter)[0]
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	row := 0
+	var cols csvColumns
+	if m.HasHeader {
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadMapping, err)
+		}
+		row++
+		if cols, err = m.resolve(header); err != nil {
+			return nil, err
+		}
+	} else if cols, err = m.resolve(nil); err != nil {
+		return nil, err
+	}
+
+	var out []Candidate
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		row++
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrBadMapping, row, err)
+		}
+		if blankRecord(rec) {
+			continue
+		}
+		out = append(out, parseCSVRecord(row, rec, cols, m, layout))
+	}
+	return out, nil
+}
+
+// csvColumns holds resolved column indexes; -1 means unmapped.
+type csvColumns struct {
+	date, amount, debit, credit, kind, note, category int
+}
+
+func (m CSVMapping) withDefaults() CSVMapping {
+	if m.Delimiter == "" {
+		m.Delimiter = ","
+	}
+	if m.DateFormat == "" {
+		m.DateFormat = "YYYY-MM-DD"
+	}
+	if m.Sign == "" {
+		m.Sign = SignNegativeIsExpense
+	}
+	if m.Decimal == "" {
+		m.Decimal = money.DecimalComma
+	}
+	return m
+}
+
+func (m CSVMapping) validate() error {
+	if len([]rune(m.Delimiter)) != 1 || !m.Decimal.Valid() || strings.TrimSpace(m.Date) == "" {
+		return ErrBadMapping
+	}
+	switch m.Sign {
+	case SignNegativeIsExpense, SignNegativeIsIncome:
+		if m.Amount == "" {
+			return ErrBadMapping
+		}
+	case SignDebitCredit:
+		if m.Debit == "" || m.Credit == "" {
+			return ErrBadMapping
+		}
+	case SignKindColumn:
+		if m.Amount == "" || m.Kind == "" {
+			return ErrBadMapping
+		}
+	default:
+		return ErrBadMapping
+	}
+	return nil
+}
+
+func (m CSVMapping) resolve(header []string) (csvColumns, error) {
+	find := func(ref string) (int, error) {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			return -1, nil
+		}
+		if header == nil {
+			n, err := strconv.Atoi(ref)
+			if err != nil || n < 0 {
+				return -1, fmt.Errorf("%w: column %q is not an index", ErrBadMapping, ref)
+			}
+			return n, nil
+		}
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), ref) {
+				return i, nil
+			}
+		}
+		return -1, fmt.Errorf("%w: column %q not found", ErrBadMapping, ref)
+	}
+	var c csvColumns
+	var err error
+	for _, f := range []struct {
+		dst *int
+		ref string
+	}{
+		{&c.date, m.Date}, {&c.amount, m.Amount}, {&c.debit, m.Debit}, {&c.credit, m.Credit},
+		{&c.kind, m.Kind}, {&c.note, m.Note}, {&c.category, m.Category},
+	} {
+		if *f.dst, err = find(f.ref); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+func parseCSVRecord(row int, rec []string, cols csvColumns, m CSVMapping, layout string) Candidate {
+	cell := func(i int) string {
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+	c := Candidate{Row: row, Note: cell(cols.note), Category: cell(cols.category)}
+
+	if d, err := time.Parse(layout, cell(cols.date)); err != nil {
+		c.Errors = append(c.Errors, fmt.Sprintf("date %q does not match %s", cell(cols.date), m.DateFormat))
+	} else {
+		c.Date = d.Format("2006-01-02")
+	}
+
+	var signed int64
+	var err error
+	switch m.Sign {
+	case SignDebitCredit:
+		debit, credit := cell(cols.debit), cell(cols.credit)
+		switch {
+		case debit != "" && credit == "":
+			signed, err = money.ParseCents(debit, m.Decimal)
+			signed = -abs(signed)
+		case credit != "" && debit == "":
+			signed, err = money.ParseCents(credit, m.Decimal)
+			signed = abs(signed)
+		default:
+			err = errors.New("exactly one of debit or credit must be set")
+		}
+	case SignKindColumn:
+		signed, err = money.ParseCents(cell(cols.amount), m.Decimal)
+		if err == nil {
+			switch parseKindCell(cell(cols.kind)) {
+			case models.KindIncome:
+				signed = abs(signed)
+			case models.KindExpense:
+				signed = -abs(signed)
+			default:
+				err = fmt.Errorf("unknown kind %q", cell(cols.kind))
+			}
+		}
+	default:
+		signed, err = money.ParseCents(cell(cols.amount), m.Decimal)
+		if m.Sign == SignNegativeIsIncome {
+			signed = -signed
+		}
+	}
+	switch {
+	case err != nil:
+		c.Errors = append(c.Errors, fmt.Sprintf("amount: %v", err))
+	case signed == 0:
+		c.Errors = append(c.Errors, "amount must not be zero")
+	case signed < 0:
+		c.Kind, c.AmountCents = models.KindExpense, -signed
+	default:
+		c.Kind, c.AmountCents = models.KindIncome, signed
+	}
+	return c
+}
+
+// parseKindCell recognizes common English and Indonesian bank direction labels.
+func parseKindCell(v string) models.TransactionKind {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "income", "in", "credit", "cr", "kredit", "k", "c", "+":
+		return models.KindIncome
+	case "expense", "out", "debit", "debet", "db", "d", "-":
+		return models.KindExpense
+	}
+	return ""
+}
+
+// dateLayout converts a YYYY/MM/DD-style pattern into a Go time layout.
+func dateLayout(format string) (string, error) {
+	r := strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02")
+	layout := r.Replace(strings.ToUpper(format))
+	if !strings.Contains(layout, "06") || !strings.Contains(layout, "01") || !strings.Contains(layout, "02") {
+		return "", fmt.Errorf("%w: date format %q", ErrBadMapping, format)
+	}
+	return layout, nil
+}
+
+func blankRecord(rec []string) bool {
+	for _, v := range rec {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,129 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/money"
+)
+
+var ErrBadOFX = errors.New("invalid ofx")
+
+// ofxTagRe matches one tag and the text that follows it up to the next tag.
+// It handles both SGML OFX 1.x (unclosed leaf elements) and XML OFX 2.x.
+var ofxTagRe = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)>([^<]*)`)
+
+// ParseOFX reads STMTTRN entries from an OFX/QFX bank or card statement.
+// Each candidate's ExternalID is derived from the account and FITID so that
+// importing the same statement twice skips rows that already exist.
+func ParseOFX(r io.Reader) ([]Candidate, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	body := string(raw)
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, ErrBadOFX
+	}
+
+	var (
+		out    []Candidate
+		acctID string
+		cur    map[string]string // fields of the STMTTRN being read, nil outside one
+	)
+	flush := func() {
+		if cur != nil {
+			out = append(out, ofxCandidate(len(out)+1, acctID, cur))
+			cur = nil
+		}
+	}
+	for _, m := range ofxTagRe.FindAllStringSubmatch(body[start:], -1) {
+		closing, tag, text := m[1] == "/", strings.ToUpper(m[2]), strings.TrimSpace(m[3])
+		switch {
+		case tag == "STMTTRN":
+			flush()
+			if !closing {
+				cur = map[string]string{}
+			}
+		case tag == "BANKTRANLIST" && closing:
+			flush()
+		case closing:
+			// XML closing tag of a leaf element; the value was captured on open.
+		case tag == "ACCTID" && cur == nil:
+			acctID = text
+		case cur != nil:
+			cur[tag] = decodeOFXText(text)
+		}
+	}
+	flush()
+	return out, nil
+}
+
+func ofxCandidate(row int, acctID string, f map[string]string) Candidate {
+	c := Candidate{Row: row}
+
+	if fitID := f["FITID"]; fitID != "" {
+		c.ExternalID = "ofx:" + acctID + ":" + fitID
+	} else {
+		c.Errors = append(c.Errors, "missing FITID")
+	}
+
+	posted := f["DTPOSTED"]
+	if len(posted) < 8 {
+		c.Errors = append(c.Errors, fmt.Sprintf("invalid DTPOSTED %q", posted))
+	} else if d, err := time.Parse("20060102", posted[:8]); err != nil {
+		c.Errors = append(c.Errors, fmt.Sprintf("invalid DTPOSTED %q", posted))
+	} else {
+		c.Date = d.Format("2006-01-02")
+	}
+
+	amt, err := money.ParseCents(f["TRNAMT"], money.DecimalDot)
+	if err != nil {
+		// Some banks emit locale-formatted amounts ("-15000,50").
+		amt, err = money.ParseCents(f["TRNAMT"], money.DecimalComma)
+	}
+	switch {
+	case err != nil:
+		c.Errors = append(c.Errors, fmt.Sprintf("invalid TRNAMT %q", f["TRNAMT"]))
+	case amt == 0:
+		c.Errors = append(c.Errors, "TRNAMT must not be zero")
+	default:
+		c.AmountCents = abs(amt)
+		// DEBIT/CREDIT are authoritative even when a bank leaves TRNAMT unsigned;
+		// every other TRNTYPE (POS, ATM, FEE, XFER, ...) follows the sign.
+		switch strings.ToUpper(f["TRNTYPE"]) {
+		case "DEBIT":
+			c.Kind = models.KindExpense
+		case "CREDIT":
+			c.Kind = models.KindIncome
+		default:
+			if amt < 0 {
+				c.Kind = models.KindExpense
+			} else {
+				c.Kind = models.KindIncome
+			}
+		}
+	}
+
+	name, memo := f["NAME"], f["MEMO"]
+	switch {
+	case name == "":
+		c.Note = memo
+	case memo == "" || strings.EqualFold(name, memo):
+		c.Note = name
+	default:
+		c.Note = name + " " + memo
+	}
+	return c
+}
+
+func decodeOFXText(s string) string {
+	r := strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'")
+	return r.Replace(s)
+}
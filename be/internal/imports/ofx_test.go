@@ -0,0 +1,86 @@
+package imports
+
+import (
+	"strings"
+	"testing"
+
+	"personal-budgeting/be/internal/models"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>IDR
+<BANKACCTFROM><BANKID>014<ACCTID>1234567890<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20260101<DTEND>20260131
+<STMTTRN>
+<TRNTYPE>POS
+<DTPOSTED>20260105120000[+7:WIB]
+<TRNAMT>-150000.50
+<FITID>A001
+<NAME>INDOMARET KEMANG
+<MEMO>Card 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260125
+<TRNAMT>10000000.00
+<FITID>A002
+<NAME>Salary &amp; bonus
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260126
+<TRNAMT>25000
+<NAME>No id
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>`
+
+func TestParseOFX_SGML(t *testing.T) {
+	cands, err := ParseOFX(strings.NewReader(sgmlStatement))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(cands) != 3 {
+		t.Fatalf("expected 3 candidates, got %d", len(cands))
+	}
+	c := cands[0]
+	if c.Kind != models.KindExpense || c.AmountCents != 150000_50 || c.Date != "2026-01-05" ||
+		c.ExternalID != "ofx:1234567890:A001" || c.Note != "INDOMARET KEMANG Card 1234" || len(c.Errors) != 0 {
+		t.Fatalf("unexpected first candidate: %+v", c)
+	}
+	if c := cands[1]; c.Kind != models.KindIncome || c.Note != "Salary & bonus" {
+		t.Fatalf("unexpected second candidate: %+v", c)
+	}
+	if c := cands[2]; c.Kind != models.KindExpense || len(c.Errors) != 1 {
+		t.Fatalf("expected unsigned DEBIT with missing FITID error, got %+v", c)
+	}
+}
+
+func TestParseOFX_XML(t *testing.T) {
+	doc := `<?xml version="1.0"?><?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+<CCACCTFROM><ACCTID>4111</ACCTID></CCACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20260203</DTPOSTED><TRNAMT>-12.34</TRNAMT><FITID>X1</FITID><NAME>Coffee</NAME></STMTTRN>
+</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>`
+	cands, err := ParseOFX(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(cands) != 1 || cands[0].ExternalID != "ofx:4111:X1" || cands[0].AmountCents != 12_34 || cands[0].Note != "Coffee" {
+		t.Fatalf("unexpected candidates: %+v", cands)
+	}
+}
+
+func TestParseOFX_RejectsNonOFX(t *testing.T) {
+	if _, err := ParseOFX(strings.NewReader("date,amount\n")); err == nil {
+		t.Fatalf("expected error")
+	}
+}
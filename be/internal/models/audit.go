@@ -0,0 +1,46 @@
+package models
+
+// The audit log is append-only: every create, update, delete and restore of a
+// category, budget or transaction adds an entry, and entries outlive the
+// entity, purging included.
+
+type AuditEntity string
+
+const (
+	AuditCategory    AuditEntity = "category"
+	AuditBudget      AuditEntity = "budget"
+	AuditTransaction AuditEntity = "transaction"
+)
+
+func (e AuditEntity) Valid() bool {
+	return e == AuditCategory || e == AuditBudget || e == AuditTransaction
+}
+
+type AuditAction string
+
+const (
+	AuditCreate  AuditAction = "create"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+)
+
+// AuditEntry records one change. Before and After are the entity as the API
+// returns it; Before is absent on create and After on delete. Diff holds every
+// field that changed between the two except updatedAt and version.
+type AuditEntry struct {
+	ID         int64                  `json:"id"` // increases with every entry
+	EntityType AuditEntity            `json:"entityType"`
+	EntityID   string                 `json:"entityId"`
+	Action     AuditAction            `json:"action"`
+	Actor      string                 `json:"actor"` // the user ID, or the background job that made the change
+	Before     map[string]any         `json:"before,omitempty"`
+	After      map[string]any         `json:"after,omitempty"`
+	Diff       map[string]AuditChange `json:"diff"`
+	CreatedAt  string                 `json:"createdAt"`
+}
+
+type AuditChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
@@ -0,0 +1,35 @@
+package models
+
+type ImportRowStatus string
+
+const (
+	ImportRowOK        ImportRowStatus = "ok"        // would be imported (preview)
+	ImportRowInvalid   ImportRowStatus = "invalid"   // has errors; never imported
+	ImportRowDuplicate ImportRowStatus = "duplicate" // external ID already imported; skipped
+	ImportRowImported  ImportRowStatus = "imported"  // committed
+)
+
+type ImportRow struct {
+	Row         int             `json:"row"`
+	Kind        TransactionKind `json:"kind,omitempty"`
+	Date        string          `json:"date,omitempty"`
+	AmountCents int64           `json:"amountCents"`
+	Note        string          `json:"note,omitempty"`
+	CategoryID  string          `json:"categoryId,omitempty"`
+	PayeeID     string          `json:"payeeId,omitempty"`
+	TagIDs      []string        `json:"tagIds,omitempty"` // added by rules
+	ExternalID  string          `json:"externalId,omitempty"`
+	Status      ImportRowStatus `json:"status"`
+	Errors      []string        `json:"errors,omitempty"`
+	TxnID       string          `json:"txnId,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun     bool        `json:"dryRun"`
+	Total      int         `json:"total"`
+	Valid      int         `json:"valid"`
+	Invalid    int         `json:"invalid"`
+	Duplicates int         `json:"duplicates"`
+	Imported   int         `json:"imported"`
+	Rows       []ImportRow `json:"rows"`
+}
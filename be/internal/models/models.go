@@ -12,15 +12,93 @@ type TransactionKind string
 const (
 	KindIncome  TransactionKind = "income"
 	KindExpense TransactionKind = "expense"
+	// KindTransfer marks one leg of a transfer between two accounts. Transfer legs
+	// have no category and are left out of income/expense reporting.
+	KindTransfer TransactionKind = "transfer"
 )
 
+func (k TransactionKind) Valid() bool {
+	switch k {
+	case KindIncome, KindExpense, KindTransfer:
+		return true
+	}
+	return false
+}
+
+type TransferDirection string
+
+const (
+	TransferOut TransferDirection = "out"
+	TransferIn  TransferDirection = "in"
+)
+
+type AccountType string
+
+const (
+	AccountCash       AccountType = "cash"
+	AccountBank       AccountType = "bank"
+	AccountEWallet    AccountType = "ewallet"
+	AccountCreditCard AccountType = "credit_card"
+	AccountOther      AccountType = "other"
+)
+
+func (t AccountType) Valid() bool {
+	switch t {
+	case AccountCash, AccountBank, AccountEWallet, AccountCreditCard, AccountOther:
+		return true
+	}
+	return false
+}
+
+// DefaultCurrency is the currency of rows stored without one (everything
+// predating multi-currency support) and the default home currency.
+const DefaultCurrency = "IDR"
+
+// CurrencyOrDefault fills in the currency of rows written without one.
+func CurrencyOrDefault(code string) string {
+	if code == "" {
+		return DefaultCurrency
+	}
+	return code
+}
+
+// RolloverPolicy says what happens to an expense category's unspent (or
+// overspent) budget at the end of a month.
+type RolloverPolicy string
+
+const (
+	RolloverNone RolloverPolicy = "none"
+	// RolloverCarryPositive carries leftovers forward; overspending is forgiven.
+	RolloverCarryPositive RolloverPolicy = "carry_positive"
+	// RolloverCarryBoth also carries overspending, reducing next month's amount.
+	RolloverCarryBoth RolloverPolicy = "carry_both"
+)
+
+func (p RolloverPolicy) Valid() bool {
+	switch p {
+	case RolloverNone, RolloverCarryPositive, RolloverCarryBoth:
+		return true
+	}
+	return false
+}
+
 type Category struct {
-	ID          string       `json:"id"`
-	Type        CategoryType `json:"type"`
-	Name        string       `json:"name"`
-	Description string       `json:"description,omitempty"`
-	CreatedAt   string       `json:"createdAt"`
-	UpdatedAt   string       `json:"updatedAt"`
+	ID             string         `json:"id"`
+	Type           CategoryType   `json:"type"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description,omitempty"`
+	ParentID       string         `json:"parentId,omitempty"` // same type as the parent
+	RolloverPolicy RolloverPolicy `json:"rolloverPolicy"`
+	Version        int64          `json:"version"` // the ETag of GET and PATCH /categories/:id
+	CreatedAt      string         `json:"createdAt"`
+	UpdatedAt      string         `json:"updatedAt"`
+	DeletedAt      string         `json:"deletedAt,omitempty"` // only set in the trash
+}
+
+// CategoryNode is a category with its subcategories, for GET /categories?tree=true.
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
 }
 
 type Budget struct {
@@ -28,19 +106,252 @@ type Budget struct {
 	Month       string `json:"month"` // YYYY-MM
 	CategoryID  string `json:"categoryId"`
 	AmountCents int64  `json:"amountCents"`
+	Currency    string `json:"currency"`
 	CreatedAt   string `json:"createdAt"`
 	UpdatedAt   string `json:"updatedAt"`
+	DeletedAt   string `json:"deletedAt,omitempty"` // only set in the trash
+}
+
+// BudgetCopyMode decides what happens to budgets already set in the target month.
+type BudgetCopyMode string
+
+const (
+	BudgetCopySkip      BudgetCopyMode = "skip"
+	BudgetCopyOverwrite BudgetCopyMode = "overwrite"
+)
+
+func (m BudgetCopyMode) Valid() bool {
+	return m == BudgetCopySkip || m == BudgetCopyOverwrite
+}
+
+// BudgetTemplate is a named set of budgets. AutoApply templates are applied,
+// skipping existing budgets, when a month gets its first transaction.
+type BudgetTemplate struct {
+	ID        string               `json:"id"`
+	Name      string               `json:"name"`
+	AutoApply bool                 `json:"autoApply"`
+	Lines     []BudgetTemplateLine `json:"lines"`
+	CreatedAt string               `json:"createdAt"`
+	UpdatedAt string               `json:"updatedAt"`
+}
+
+type BudgetTemplateLine struct {
+	CategoryID  string `json:"categoryId"`
+	AmountCents int64  `json:"amountCents"`
+	Currency    string `json:"currency"`
+}
+
+// BudgetApplyResult reports a bulk copy or template application. Budgets holds
+// the target month's budgets afterwards.
+type BudgetApplyResult struct {
+	Month   string   `json:"month"`
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Budgets []Budget `json:"budgets"`
+}
+
+type Account struct {
+	ID                  string      `json:"id"`
+	Name                string      `json:"name"`
+	Type                AccountType `json:"type"`
+	OpeningBalanceCents int64       `json:"openingBalanceCents"`
+	Currency            string      `json:"currency"` // ISO 4217, e.g. IDR
+	Archived            bool        `json:"archived"`
+	CreatedAt           string      `json:"createdAt"`
+	UpdatedAt           string      `json:"updatedAt"`
 }
 
 type Txn struct {
+	ID                string            `json:"id"`
+	Kind              TransactionKind   `json:"kind"`
+	Date              string            `json:"date"` // YYYY-MM-DD
+	CategoryID        string            `json:"categoryId"`
+	AccountID         string            `json:"accountId,omitempty"`
+	PayeeID           string            `json:"payeeId,omitempty"`
+	AmountCents       int64             `json:"amountCents"`
+	Currency          string            `json:"currency"`
+	Note              string            `json:"note,omitempty"`
+	ExternalID        string            `json:"externalId,omitempty"` // source identifier for imported rows (e.g. OFX FITID)
+	TransferID        string            `json:"transferId,omitempty"` // shared by both legs of a transfer
+	TransferDirection TransferDirection `json:"transferDirection,omitempty"`
+	TagIDs            []string          `json:"tagIds,omitempty"`
+	// Splits divides the amount across categories. When present the lines sum to
+	// AmountCents and CategoryID is the first line's category.
+	Splits    []TxnSplit `json:"splits,omitempty"`
+	Version   int64      `json:"version"` // the ETag of GET and PATCH /transactions/:id
+	CreatedAt string     `json:"createdAt"`
+	UpdatedAt string     `json:"updatedAt"`
+	DeletedAt string     `json:"deletedAt,omitempty"` // only set in the trash
+}
+
+type TxnSplit struct {
+	CategoryID  string `json:"categoryId"`
+	AmountCents int64  `json:"amountCents"`
+	Note        string `json:"note,omitempty"`
+}
+
+// Tag labels transactions across categories, e.g. "trip-bali-2026". Names are
+// lowercase and unique per user.
+type Tag struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// Payee is the canonical merchant or counterparty behind raw note text such as
+// "INDOMARET 123". Aliases are stored normalized and, together with the
+// normalized name, unique per user.
+type Payee struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Aliases   []string `json:"aliases"`
+	CreatedAt string   `json:"createdAt"`
+	UpdatedAt string   `json:"updatedAt"`
+}
+
+// Attachment describes a file, such as a receipt photo or PDF invoice, attached
+// to a transaction. The content is downloaded separately.
+type Attachment struct {
+	ID          string `json:"id"`
+	TxnID       string `json:"transactionId"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	SizeBytes   int64  `json:"sizeBytes"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// Transfer is the API view of a transfer's two transaction legs.
+type Transfer struct {
+	ID            string `json:"id"`
+	Date          string `json:"date"` // YYYY-MM-DD
+	AmountCents   int64  `json:"amountCents"`
+	Currency      string `json:"currency"`
+	Note          string `json:"note,omitempty"`
+	FromAccountID string `json:"fromAccountId"`
+	ToAccountID   string `json:"toAccountId"`
+	OutTxnID      string `json:"outTxnId"`
+	InTxnID       string `json:"inTxnId"`
+}
+
+// ExchangeRate says one unit of Base is worth Rate units of Quote from Date
+// until the next rate for the same pair.
+type ExchangeRate struct {
+	ID        string  `json:"id"`
+	Date      string  `json:"date"` // YYYY-MM-DD
+	Base      string  `json:"base"`
+	Quote     string  `json:"quote"`
+	Rate      float64 `json:"rate"`
+	CreatedAt string  `json:"createdAt"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+type RecurringFrequency string
+
+const (
+	// FreqMonthly repeats on DayOfMonth; short months use their last day.
+	FreqMonthly RecurringFrequency = "monthly"
+	// FreqWeekly repeats on the weekday of StartDate.
+	FreqWeekly RecurringFrequency = "weekly"
+	// FreqYearly repeats on the month and day of StartDate (29 February becomes the 28th).
+	FreqYearly RecurringFrequency = "yearly"
+	// FreqEveryNDays repeats every IntervalDays days from StartDate.
+	FreqEveryNDays RecurringFrequency = "every_n_days"
+	// FreqLastBusinessDay is the last Monday to Friday of each month.
+	FreqLastBusinessDay RecurringFrequency = "last_business_day"
+)
+
+func (f RecurringFrequency) Valid() bool {
+	switch f {
+	case FreqMonthly, FreqWeekly, FreqYearly, FreqEveryNDays, FreqLastBusinessDay:
+		return true
+	}
+	return false
+}
+
+// RecurringRule is a transaction template plus a schedule. Each occurrence
+// becomes a transaction with ExternalID "recurring:<rule id>:<date>".
+type RecurringRule struct {
 	ID          string          `json:"id"`
-	Kind        TransactionKind `json:"kind"`
-	Date        string          `json:"date"` // YYYY-MM-DD
+	Name        string          `json:"name"`
+	Kind        TransactionKind `json:"kind"` // income or expense
 	CategoryID  string          `json:"categoryId"`
+	AccountID   string          `json:"accountId,omitempty"`
 	AmountCents int64           `json:"amountCents"`
+	Currency    string          `json:"currency"`
 	Note        string          `json:"note,omitempty"`
-	CreatedAt   string          `json:"createdAt"`
-	UpdatedAt   string          `json:"updatedAt"`
+
+	Frequency    RecurringFrequency `json:"frequency"`
+	DayOfMonth   int                `json:"dayOfMonth,omitempty"`   // monthly only, 1-31
+	IntervalDays int                `json:"intervalDays,omitempty"` // every_n_days only
+	StartDate    string             `json:"startDate"`              // YYYY-MM-DD
+	EndDate      string             `json:"endDate,omitempty"`      // inclusive; empty means no end
+	Paused       bool               `json:"paused"`
+	// LastRunDate is the day occurrences were last materialized through.
+	LastRunDate string `json:"lastRunDate,omitempty"`
+
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// RecurringPreview lists a rule's upcoming occurrence dates.
+type RecurringPreview struct {
+	RuleID string   `json:"ruleId"`
+	Dates  []string `json:"dates"`
+}
+
+// Rule categorizes transactions automatically. A rule matches when every
+// condition that is set holds; rules run by ascending Priority, then name.
+type Rule struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Priority   int            `json:"priority"`
+	Paused     bool           `json:"paused"`
+	Conditions RuleConditions `json:"conditions"`
+	Actions    RuleActions    `json:"actions"`
+	CreatedAt  string         `json:"createdAt"`
+	UpdatedAt  string         `json:"updatedAt"`
+}
+
+// RuleConditions never match transfers. Note conditions ignore case.
+type RuleConditions struct {
+	NoteContains   string          `json:"noteContains,omitempty"`
+	NoteRegex      string          `json:"noteRegex,omitempty"` // RE2 syntax
+	MinAmountCents *int64          `json:"minAmountCents,omitempty"`
+	MaxAmountCents *int64          `json:"maxAmountCents,omitempty"`
+	Kind           TransactionKind `json:"kind,omitempty"`
+	AccountID      string          `json:"accountId,omitempty"`
+}
+
+// RuleActions apply to a matching transaction. CategoryID is skipped for split
+// transactions and for a kind the category doesn't fit. SetNote replaces the
+// note; with a NoteRegex, $1 and ${name} expand to its groups.
+type RuleActions struct {
+	CategoryID string   `json:"categoryId,omitempty"`
+	TagIDs     []string `json:"tagIds,omitempty"`
+	SetNote    string   `json:"setNote,omitempty"`
+}
+
+// RuleChange is how a rule changes one existing transaction.
+type RuleChange struct {
+	TxnID         string   `json:"transactionId"`
+	Date          string   `json:"date"`
+	Note          string   `json:"note"`
+	CategoryID    string   `json:"categoryId"`
+	NewNote       string   `json:"newNote"`
+	NewCategoryID string   `json:"newCategoryId"`
+	AddedTagIDs   []string `json:"addedTagIds,omitempty"`
+}
+
+// RuleApplyResult reports a rule run over existing transactions. Matched counts
+// every transaction the conditions hold for; only Changes were (or, on a dry
+// run, would be) updated.
+type RuleApplyResult struct {
+	RuleID  string       `json:"ruleId"`
+	DryRun  bool         `json:"dryRun"`
+	Matched int          `json:"matched"`
+	Changes []RuleChange `json:"changes"`
 }
 
 type AppStateV1 struct {
@@ -48,4 +359,33 @@ type AppStateV1 struct {
 	Categories   []Category `json:"categories"`
 	Budgets      []Budget   `json:"budgets"`
 	Transactions []Txn      `json:"transactions"`
+	// Accounts is optional; when omitted, PUT /state leaves existing accounts alone.
+	Accounts []Account `json:"accounts,omitempty"`
+	// RecurringRules is optional too; rules kept by omitting it must still find
+	// their category and account in the new state.
+	RecurringRules []RecurringRule `json:"recurringRules,omitempty"`
+	// Tags is optional as well; transactions may use any tag that ends up stored.
+	Tags []Tag `json:"tags,omitempty"`
+	// Payees is optional in the same way.
+	Payees []Payee `json:"payees,omitempty"`
+}
+
+// Trash holds deleted items that can still be restored, most recently deleted
+// first. Both legs of a deleted transfer are listed.
+type Trash struct {
+	Transactions []Txn      `json:"transactions"`
+	Categories   []Category `json:"categories"`
+	Budgets      []Budget   `json:"budgets"`
+}
+
+type User struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"createdAt"`
+}
+
+type AuthToken struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+	User      User   `json:"user"`
 }
@@ -0,0 +1,130 @@
+package models
+
+// Report shapes are computed server-side so every client gets the same numbers.
+// Amounts are converted to Currency; MissingRates lists currencies that had
+// transactions in range but no exchange rate, so those rows were left out.
+// Per-category figures roll subcategories up: a parent's amounts include its
+// children's, and ParentID lets clients nest the rows again. Split transactions
+// count each line toward its own category.
+
+type CategoryTotal struct {
+	CategoryID   string          `json:"categoryId"`
+	CategoryName string          `json:"categoryName"`
+	ParentID     string          `json:"parentId,omitempty"`
+	Kind         TransactionKind `json:"kind"`
+	TotalCents   int64           `json:"totalCents"`
+	Count        int             `json:"count"`
+}
+
+type Summary struct {
+	From         string          `json:"from"` // YYYY-MM-DD, inclusive
+	To           string          `json:"to"`   // YYYY-MM-DD, inclusive
+	IncomeCents  int64           `json:"incomeCents"`
+	ExpenseCents int64           `json:"expenseCents"`
+	NetCents     int64           `json:"netCents"`
+	Categories   []CategoryTotal `json:"categories"`
+	Currency     string          `json:"currency"`
+	MissingRates []string        `json:"missingRates,omitempty"`
+}
+
+// BudgetLine compares one expense category's budget against actual spend.
+// CarriedCents is the balance rolled over from earlier months (negative after
+// overspending); remaining, percent used and over budget count it as part of
+// the budget. PercentUsed is null when nothing is available. Totals only count
+// top-level lines.
+type BudgetLine struct {
+	CategoryID     string   `json:"categoryId"`
+	CategoryName   string   `json:"categoryName"`
+	ParentID       string   `json:"parentId,omitempty"`
+	BudgetedCents  int64    `json:"budgetedCents"`
+	CarriedCents   int64    `json:"carriedCents"`
+	SpentCents     int64    `json:"spentCents"`
+	RemainingCents int64    `json:"remainingCents"`
+	PercentUsed    *float64 `json:"percentUsed"`
+	OverBudget     bool     `json:"overBudget"`
+}
+
+type BudgetReport struct {
+	Month        string       `json:"month"` // YYYY-MM
+	Lines        []BudgetLine `json:"lines"`
+	Totals       BudgetLine   `json:"totals"`
+	Currency     string       `json:"currency"`
+	MissingRates []string     `json:"missingRates,omitempty"`
+}
+
+// TagTotal is the expense spend of transactions carrying one tag. A transaction
+// with several tags counts toward each of them.
+type TagTotal struct {
+	TagID      string `json:"tagId"`
+	TagName    string `json:"tagName"`
+	TotalCents int64  `json:"totalCents"`
+	Count      int    `json:"count"`
+}
+
+type TagReport struct {
+	From         string     `json:"from"` // YYYY-MM-DD, inclusive
+	To           string     `json:"to"`   // YYYY-MM-DD, inclusive
+	Tags         []TagTotal `json:"tags"`
+	Currency     string     `json:"currency"`
+	MissingRates []string   `json:"missingRates,omitempty"`
+}
+
+// PayeeTotal is the expense spend of transactions with one payee.
+type PayeeTotal struct {
+	PayeeID    string `json:"payeeId"`
+	PayeeName  string `json:"payeeName"`
+	TotalCents int64  `json:"totalCents"`
+	Count      int    `json:"count"`
+}
+
+type PayeeReport struct {
+	From         string       `json:"from"` // YYYY-MM-DD, inclusive
+	To           string       `json:"to"`   // YYYY-MM-DD, inclusive
+	Payees       []PayeeTotal `json:"payees"`
+	Currency     string       `json:"currency"`
+	MissingRates []string     `json:"missingRates,omitempty"`
+}
+
+type TrendPoint struct {
+	Month           string `json:"month"` // YYYY-MM
+	SpentCents      int64  `json:"spentCents"`
+	BudgetedCents   int64  `json:"budgetedCents"`
+	RollingAvgCents int64  `json:"rollingAvgCents"` // trailing average of SpentCents
+}
+
+type CategoryTrend struct {
+	CategoryID      string       `json:"categoryId"`
+	CategoryName    string       `json:"categoryName"`
+	ParentID        string       `json:"parentId,omitempty"`
+	Points          []TrendPoint `json:"points"`
+	TotalSpentCents int64        `json:"totalSpentCents"`
+}
+
+type TrendReport struct {
+	From         string          `json:"from"` // YYYY-MM
+	To           string          `json:"to"`   // YYYY-MM
+	Window       int             `json:"window"`
+	Categories   []CategoryTrend `json:"categories"`
+	Currency     string          `json:"currency"`
+	MissingRates []string        `json:"missingRates,omitempty"`
+}
+
+// BalanceEntry is one transaction on an account with the balance after it.
+type BalanceEntry struct {
+	TxnID        string          `json:"txnId"`
+	Date         string          `json:"date"` // YYYY-MM-DD
+	Kind         TransactionKind `json:"kind"`
+	Note         string          `json:"note,omitempty"`
+	AmountCents  int64           `json:"amountCents"` // signed: inflows positive, outflows negative
+	BalanceCents int64           `json:"balanceCents"`
+}
+
+type AccountBalance struct {
+	AccountID         string         `json:"accountId"`
+	Currency          string         `json:"currency"`
+	From              string         `json:"from,omitempty"` // YYYY-MM-DD
+	To                string         `json:"to,omitempty"`   // YYYY-MM-DD
+	StartBalanceCents int64          `json:"startBalanceCents"`
+	EndBalanceCents   int64          `json:"endBalanceCents"`
+	Entries           []BalanceEntry `json:"entries"`
+}
@@ -0,0 +1,39 @@
+package money
+
+// Amounts are stored in hundredths of a currency unit ("cents") whatever the
+// currency. Decimals is how many of those two digits a currency really uses:
+// IDR and JPY have no usable minor unit, so their amounts are whole multiples of 100.
+type Currency struct {
+	Code     string
+	Decimals int
+}
+
+var currencies = map[string]Currency{
+	"AUD": {Code: "AUD", Decimals: 2},
+	"CNY": {Code: "CNY", Decimals: 2},
+	"EUR": {Code: "EUR", Decimals: 2},
+	"GBP": {Code: "GBP", Decimals: 2},
+	"HKD": {Code: "HKD", Decimals: 2},
+	"IDR": {Code: "IDR", Decimals: 0},
+	"JPY": {Code: "JPY", Decimals: 0},
+	"KRW": {Code: "KRW", Decimals: 0},
+	"MYR": {Code: "MYR", Decimals: 2},
+	"SGD": {Code: "SGD", Decimals: 2},
+	"THB": {Code: "THB", Decimals: 2},
+	"USD": {Code: "USD", Decimals: 2},
+}
+
+// LookupCurrency returns the currency for an ISO 4217 code, if supported.
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencies[code]
+	return c, ok
+}
+
+// ValidCents reports whether cents is expressible in c's minor units.
+func (c Currency) ValidCents(cents int64) bool {
+	step := int64(1)
+	for i := c.Decimals; i < 2; i++ {
+		step *= 10
+	}
+	return cents%step == 0
+}
@@ -0,0 +1,115 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DecimalStyle says which separator marks decimals in human-entered amounts.
+type DecimalStyle string
+
+const (
+	// DecimalComma is the id-ID style: "10.000,50".
+	DecimalComma DecimalStyle = "comma"
+	// DecimalDot is the en-US style: "10,000.50".
+	DecimalDot DecimalStyle = "dot"
+)
+
+func (d DecimalStyle) Valid() bool {
+	return d == DecimalComma || d == DecimalDot
+}
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ParseCents parses a human-entered amount into signed cents.
+// It accepts an optional "Rp"/"IDR" prefix, thousands separators, up to two decimals
+// and negatives written as "-10", "10-" or "(10)". This mirrors the frontend's
+// parseMoneyToCents, with the decimal separator made explicit.
+func ParseCents(raw string, style DecimalStyle) (int64, error) {
+	s := strings.TrimSpace(raw)
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg = true
+		s = s[1 : len(s)-1]
+	}
+	s = strings.Map(func(r rune) rune {
+		if r == ' ' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, s)
+	if strings.HasPrefix(s, "-") {
+		neg = !neg
+		s = s[1:]
+	} else if strings.HasSuffix(s, "-") {
+		neg = !neg
+		s = s[:len(s)-1]
+	}
+	upper := strings.ToUpper(s)
+	for _, prefix := range []string{"IDR", "RP"} {
+		if strings.HasPrefix(upper, prefix) {
+			// "Rp.10.000" and "Rp-5" are both seen in bank exports.
+			s = strings.TrimPrefix(s[len(prefix):], ".")
+			if strings.HasPrefix(s, "-") {
+				neg = !neg
+				s = s[1:]
+			}
+			break
+		}
+	}
+
+	thousands, decimal := ".", ","
+	if style == DecimalDot {
+		thousands, decimal = ",", "."
+	}
+	s = strings.ReplaceAll(s, thousands, "")
+	whole, frac, hasFrac := strings.Cut(s, decimal)
+	if whole == "" && !hasFrac {
+		return 0, ErrInvalidAmount
+	}
+	if len(frac) > 2 || (hasFrac && frac == "" && whole == "") || !digitsOnly(whole) || !digitsOnly(frac) {
+		return 0, ErrInvalidAmount
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || n > (1<<62)/100 {
+		return 0, ErrInvalidAmount
+	}
+	f, _ := strconv.ParseInt(frac, 10, 64)
+	cents := n*100 + f
+	if neg {
+		cents = -cents
+	}
+	return cents, nil
+}
+
+func digitsOnly(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatCents renders signed cents as a plain decimal string without thousands
+// separators, e.g. -1234550 → "-12345,50" (comma) or "-12345.50" (dot).
+func FormatCents(cents int64, style DecimalStyle) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	sep := ","
+	if style == DecimalDot {
+		sep = "."
+	}
+	return fmt.Sprintf("%s%d%s%02d", sign, cents/100, sep, cents%100)
+}
@@ -0,0 +1,59 @@
+package money
+
+import "testing"
+
+func TestParseCents(t *testing.T) {
+	cases := []struct {
+		raw   string
+		style DecimalStyle
+		want  int64
+		ok    bool
+	}{
+		{"10000", DecimalComma, 10000_00, true},
+		{"10.000", DecimalComma, 10000_00, true},
+		{"10.000,50", DecimalComma, 10000_50, true},
+		{"Rp 10.000,5", DecimalComma, 10000_50, true},
+		{"-1.250,00", DecimalComma, -1250_00, true},
+		{"(75,25)", DecimalComma, -75_25, true},
+		{"10,000.50", DecimalDot, 10000_50, true},
+		{"IDR 12.5", DecimalDot, 12_50, true},
+		{"300-", DecimalDot, -300_00, true},
+		{".5", DecimalDot, 50, true},
+		{"", DecimalComma, 0, false},
+		{"abc", DecimalComma, 0, false},
+		{"1,234", DecimalComma, 0, false},
+		{"10.000,50", DecimalDot, 0, false},
+	}
+	for _, tc := range cases {
+		got, err := ParseCents(tc.raw, tc.style)
+		if tc.ok && (err != nil || got != tc.want) {
+			t.Errorf("ParseCents(%q, %s) = %d, %v; want %d", tc.raw, tc.style, got, err, tc.want)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("ParseCents(%q, %s) = %d; want error", tc.raw, tc.style, got)
+		}
+	}
+}
+
+func TestFormatCents(t *testing.T) {
+	if got := FormatCents(-1234550, DecimalComma); got != "-12345,50" {
+		t.Errorf("got %q", got)
+	}
+	if got := FormatCents(5, DecimalDot); got != "0.05" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestCurrency_ValidCents(t *testing.T) {
+	idr, _ := LookupCurrency("IDR")
+	usd, _ := LookupCurrency("USD")
+	if !idr.ValidCents(150000_00) || idr.ValidCents(150000_50) {
+		t.Fatalf("IDR amounts must be whole rupiah")
+	}
+	if !usd.ValidCents(12_34) {
+		t.Fatalf("USD amounts may have cents")
+	}
+	if _, ok := LookupCurrency("XXX"); ok {
+		t.Fatalf("unknown currency should not resolve")
+	}
+}
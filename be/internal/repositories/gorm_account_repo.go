@@ -0,0 +1,188 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormAccountRepo struct {
+	db *gorm.DB
+}
+
+func NewGormAccountRepo(db *gorm.DB) *GormAccountRepo {
+	return &GormAccountRepo{db: db}
+}
+
+var _ AccountRepository = (*GormAccountRepo)(nil)
+
+func (r *GormAccountRepo) List(ctx context.Context, includeArchived bool) ([]models.Account, error) {
+	q := owned(ctx, r.db).Order("created_at asc")
+	if !includeArchived {
+		q = q.Where("archived = ?", false)
+	}
+	var rows []dbmodel.Account
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.Account, 0, len(rows))
+	for _, a := range rows {
+		out = append(out, toAPIAccount(a))
+	}
+	return out, nil
+}
+
+func (r *GormAccountRepo) Get(ctx context.Context, id string) (models.Account, error) {
+	var row dbmodel.Account
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
+		if isNotFound(err) {
+			return models.Account{}, errs.ErrNotFound
+		}
+		return models.Account{}, err
+	}
+	return toAPIAccount(row), nil
+}
+
+func (r *GormAccountRepo) Create(ctx context.Context, a models.Account) (models.Account, error) {
+	row, err := toDBAccount(auth.UserID(ctx), a)
+	if err != nil {
+		return models.Account{}, err
+	}
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return models.Account{}, errs.ErrConflict
+		}
+		return models.Account{}, err
+	}
+	return r.Get(ctx, a.ID)
+}
+
+func (r *GormAccountRepo) Update(ctx context.Context, id string, patch AccountPatch) (models.Account, error) {
+	updates := map[string]any{}
+	if patch.Name != nil {
+		updates["name"] = *patch.Name
+	}
+	if patch.Type != nil {
+		updates["type"] = string(*patch.Type)
+	}
+	if patch.OpeningBalanceCents != nil {
+		updates["opening_balance_cents"] = *patch.OpeningBalanceCents
+	}
+	if patch.Currency != nil {
+		updates["currency"] = *patch.Currency
+	}
+	if patch.Archived != nil {
+		updates["archived"] = *patch.Archived
+	}
+	if patch.UpdatedAt != nil {
+		if t, err := time.Parse(time.RFC3339, *patch.UpdatedAt); err == nil {
+			updates["updated_at"] = t
+		}
+	}
+	if len(updates) == 0 {
+		return r.Get(ctx, id)
+	}
+	tx := owned(ctx, r.db).Model(&dbmodel.Account{}).Where("id = ?", id).Updates(updates)
+	if tx.Error != nil {
+		return models.Account{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.Account{}, errs.ErrNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *GormAccountRepo) Delete(ctx context.Context, id string) error {
+	tx := owned(ctx, r.db).Delete(&dbmodel.Account{}, "id = ?", id)
+	if tx.Error != nil {
+		if isForeignKeyViolation(tx.Error) {
+			return errs.ErrConflict
+		}
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormAccountRepo) NetChange(ctx context.Context, accountID string, before string) (int64, error) {
+	var net int64
+	err := owned(ctx, r.db).Model(&dbmodel.Transaction{}).
+		Select("COALESCE(SUM(CASE WHEN kind = ? OR transfer_direction = ? THEN amount_cents ELSE -amount_cents END), 0)",
+			string(models.KindIncome), string(models.TransferIn)).
+		Where("account_id = ? AND date < ?", accountID, before).
+		Scan(&net).Error
+	if err != nil {
+		return 0, err
+	}
+	return net, nil
+}
+
+// DeleteAll removes the user's accounts. It is meant to run inside a Transactor.
+func (r *GormAccountRepo) DeleteAll(ctx context.Context) error {
+	return owned(ctx, r.db).Delete(&dbmodel.Account{}).Error
+}
+
+// CreateMany inserts items in batches, failing on the first bad row.
+func (r *GormAccountRepo) CreateMany(ctx context.Context, items []models.Account) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows := make([]dbmodel.Account, 0, len(items))
+	for _, it := range items {
+		row, err := toDBAccount(auth.UserID(ctx), it)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+	if err := conn(ctx, r.db).CreateInBatches(&rows, 500).Error; err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func toAPIAccount(a dbmodel.Account) models.Account {
+	return models.Account{
+		ID:                  a.ID,
+		Name:                a.Name,
+		Type:                models.AccountType(a.Type),
+		OpeningBalanceCents: a.OpeningBalanceCents,
+		Currency:            a.Currency,
+		Archived:            a.Archived,
+		CreatedAt:           a.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:           a.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toDBAccount(userID string, a models.Account) (dbmodel.Account, error) {
+	createdAt, err := time.Parse(time.RFC3339, a.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	updatedAt, err := time.Parse(time.RFC3339, a.UpdatedAt)
+	if err != nil {
+		updatedAt = createdAt
+	}
+	return dbmodel.Account{
+		ID:                  a.ID,
+		UserID:              userID,
+		Name:                a.Name,
+		Type:                string(a.Type),
+		OpeningBalanceCents: a.OpeningBalanceCents,
+		Currency:            models.CurrencyOrDefault(a.Currency),
+		Archived:            a.Archived,
+		CreatedAt:           createdAt.UTC(),
+		UpdatedAt:           updatedAt.UTC(),
+	}, nil
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormAttachmentRepo struct {
+	db *gorm.DB
+}
+
+func NewGormAttachmentRepo(db *gorm.DB) *GormAttachmentRepo {
+	return &GormAttachmentRepo{db: db}
+}
+
+var _ AttachmentRepository = (*GormAttachmentRepo)(nil)
+
+func (r *GormAttachmentRepo) ListByTxn(ctx context.Context, txnID string) ([]models.Attachment, error) {
+	var rows []dbmodel.Attachment
+	if err := owned(ctx, r.db).Where("txn_id = ?", txnID).Order("created_at asc, id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toAPIAttachments(rows), nil
+}
+
+func (r *GormAttachmentRepo) Get(ctx context.Context, id string) (models.Attachment, error) {
+	var row dbmodel.Attachment
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
+		if isNotFound(err) {
+			return models.Attachment{}, errs.ErrNotFound
+		}
+		return models.Attachment{}, err
+	}
+	return toAPIAttachment(row), nil
+}
+
+func (r *GormAttachmentRepo) Create(ctx context.Context, a models.Attachment) (models.Attachment, error) {
+	createdAt, err := time.Parse(time.RFC3339, a.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	row := dbmodel.Attachment{
+		ID:          a.ID,
+		UserID:      auth.UserID(ctx),
+		TxnID:       a.TxnID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		CreatedAt:   createdAt.UTC(),
+	}
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return models.Attachment{}, errs.ErrConflict
+		}
+		return models.Attachment{}, err
+	}
+	return toAPIAttachment(row), nil
+}
+
+func (r *GormAttachmentRepo) Delete(ctx context.Context, id string) error {
+	tx := owned(ctx, r.db).Delete(&dbmodel.Attachment{}, "id = ?", id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormAttachmentRepo) ListOrphans(ctx context.Context) ([]models.Attachment, error) {
+	var rows []dbmodel.Attachment
+	err := owned(ctx, r.db).
+		Where("NOT EXISTS (SELECT 1 FROM transactions t WHERE t.id = attachments.txn_id)").
+		Order("id asc").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return toAPIAttachments(rows), nil
+}
+
+func (r *GormAttachmentRepo) DeleteMany(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return owned(ctx, r.db).Delete(&dbmodel.Attachment{}, "id IN ?", ids).Error
+}
+
+func toAPIAttachments(rows []dbmodel.Attachment) []models.Attachment {
+	out := make([]models.Attachment, 0, len(rows))
+	for _, a := range rows {
+		out = append(out, toAPIAttachment(a))
+	}
+	return out
+}
+
+func toAPIAttachment(a dbmodel.Attachment) models.Attachment {
+	return models.Attachment{
+		ID:          a.ID,
+		TxnID:       a.TxnID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		SizeBytes:   a.SizeBytes,
+		CreatedAt:   a.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
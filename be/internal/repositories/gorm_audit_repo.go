@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormAuditRepo struct {
+	db *gorm.DB
+}
+
+func NewGormAuditRepo(db *gorm.DB) *GormAuditRepo {
+	return &GormAuditRepo{db: db}
+}
+
+var _ AuditRepository = (*GormAuditRepo)(nil)
+
+// CreateMany inserts items in batches; the IDs they get follow their order.
+func (r *GormAuditRepo) CreateMany(ctx context.Context, items []models.AuditEntry) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows := make([]dbmodel.AuditEntry, 0, len(items))
+	for _, it := range items {
+		rows = append(rows, toDBAuditEntry(auth.UserID(ctx), it))
+	}
+	return conn(ctx, r.db).CreateInBatches(&rows, 500).Error
+}
+
+func (r *GormAuditRepo) History(ctx context.Context, entity models.AuditEntity, entityID string) ([]models.AuditEntry, error) {
+	var rows []dbmodel.AuditEntry
+	err := owned(ctx, r.db).
+		Where("entity_type = ? AND entity_id = ?", string(entity), entityID).
+		Order("id desc").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.AuditEntry, 0, len(rows))
+	for _, e := range rows {
+		out = append(out, toAPIAuditEntry(e))
+	}
+	return out, nil
+}
+
+func (r *GormAuditRepo) Feed(ctx context.Context, q AuditQuery) (AuditPage, error) {
+	db := owned(ctx, r.db)
+	if !q.From.IsZero() {
+		db = db.Where("created_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		db = db.Where("created_at < ?", q.To)
+	}
+	if q.EntityType != "" {
+		db = db.Where("entity_type = ?", string(q.EntityType))
+	}
+	if q.Cursor != "" {
+		before, err := strconv.ParseInt(q.Cursor, 10, 64)
+		if err != nil || before <= 0 {
+			return AuditPage{}, errs.ErrValidation
+		}
+		db = db.Where("id < ?", before)
+	}
+	var rows []dbmodel.AuditEntry
+	// Fetch one extra row to know whether another page exists.
+	if err := db.Order("id desc").Limit(q.Limit + 1).Find(&rows).Error; err != nil {
+		return AuditPage{}, err
+	}
+	page := AuditPage{Items: make([]models.AuditEntry, 0, len(rows))}
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+		page.NextCursor = strconv.FormatInt(rows[len(rows)-1].ID, 10)
+	}
+	for _, e := range rows {
+		page.Items = append(page.Items, toAPIAuditEntry(e))
+	}
+	return page, nil
+}
+
+func toAPIAuditEntry(e dbmodel.AuditEntry) models.AuditEntry {
+	diff := make(map[string]models.AuditChange, len(e.Diff))
+	for field, v := range e.Diff {
+		ch, _ := v.(map[string]any)
+		diff[field] = models.AuditChange{From: ch["from"], To: ch["to"]}
+	}
+	return models.AuditEntry{
+		ID:         e.ID,
+		EntityType: models.AuditEntity(e.EntityType),
+		EntityID:   e.EntityID,
+		Action:     models.AuditAction(e.Action),
+		Actor:      e.Actor,
+		Before:     e.Before,
+		After:      e.After,
+		Diff:       diff,
+		CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toDBAuditEntry(userID string, e models.AuditEntry) dbmodel.AuditEntry {
+	createdAt, err := time.Parse(time.RFC3339, e.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	diff := make(map[string]any, len(e.Diff))
+	for field, ch := range e.Diff {
+		diff[field] = map[string]any{"from": ch.From, "to": ch.To}
+	}
+	return dbmodel.AuditEntry{
+		UserID:     userID,
+		EntityType: string(e.EntityType),
+		EntityID:   e.EntityID,
+		Action:     string(e.Action),
+		Actor:      e.Actor,
+		Before:     e.Before,
+		After:      e.After,
+		Diff:       diff,
+		CreatedAt:  createdAt.UTC(),
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
+	"personal-budgeting/be/internal/auth"
 	"personal-budgeting/be/internal/dbmodel"
 	"personal-budgeting/be/internal/errs"
 	"personal-budgeting/be/internal/models"
@@ -24,7 +25,7 @@ var _ BudgetRepository = (*GormBudgetRepo)(nil)
 
 func (r *GormBudgetRepo) List(ctx context.Context) ([]models.Budget, error) {
 	var rows []dbmodel.Budget
-	if err := r.db.WithContext(ctx).Order("created_at asc").Find(&rows).Error; err != nil {
+	if err := owned(ctx, r.db).Order("created_at asc").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]models.Budget, 0, len(rows))
@@ -36,7 +37,7 @@ func (r *GormBudgetRepo) List(ctx context.Context) ([]models.Budget, error) {
 
 func (r *GormBudgetRepo) Get(ctx context.Context, id string) (models.Budget, error) {
 	var row dbmodel.Budget
-	if err := r.db.WithContext(ctx).First(&row, "id = ?", id).Error; err != nil {
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
 		if isNotFound(err) {
 			return models.Budget{}, errs.ErrNotFound
 		}
@@ -47,7 +48,7 @@ func (r *GormBudgetRepo) Get(ctx context.Context, id string) (models.Budget, err
 
 func (r *GormBudgetRepo) FindByMonthCategory(ctx context.Context, month string, categoryID string) (models.Budget, bool, error) {
 	var row dbmodel.Budget
-	err := r.db.WithContext(ctx).First(&row, "month = ? AND category_id = ?", month, categoryID).Error
+	err := owned(ctx, r.db).First(&row, "month = ? AND category_id = ?", month, categoryID).Error
 	if err != nil {
 		if isNotFound(err) {
 			return models.Budget{}, false, nil
@@ -58,15 +59,17 @@ func (r *GormBudgetRepo) FindByMonthCategory(ctx context.Context, month string,
 }
 
 func (r *GormBudgetRepo) Upsert(ctx context.Context, b models.Budget) (models.Budget, error) {
-	row, err := toDBBudget(b)
+	row, err := toDBBudget(auth.UserID(ctx), b)
 	if err != nil {
 		return models.Budget{}, err
 	}
 
-	err = r.db.WithContext(ctx).
+	// A trashed budget still holds the (month, category) slot; setting one there
+	// brings it back with the new amount.
+	err = conn(ctx, r.db).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "month"}, {Name: "category_id"}},
-			DoUpdates: clause.AssignmentColumns([]string{"amount_cents", "updated_at"}),
+			DoUpdates: clause.AssignmentColumns([]string{"amount_cents", "currency", "updated_at", "deleted_at"}),
 		}).
 		Create(&row).Error
 	if err != nil {
@@ -87,7 +90,7 @@ func (r *GormBudgetRepo) Upsert(ctx context.Context, b models.Budget) (models.Bu
 }
 
 func (r *GormBudgetRepo) Delete(ctx context.Context, id string) error {
-	tx := r.db.WithContext(ctx).Delete(&dbmodel.Budget{}, "id = ?", id)
+	tx := owned(ctx, r.db).Delete(&dbmodel.Budget{}, "id = ?", id)
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -97,19 +100,89 @@ func (r *GormBudgetRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *GormBudgetRepo) Reset() {
-	_ = r.db.WithContext(context.Background()).Exec("DELETE FROM budgets").Error
+func (r *GormBudgetRepo) ListByMonth(ctx context.Context, month string) ([]models.Budget, error) {
+	var rows []dbmodel.Budget
+	if err := owned(ctx, r.db).Where("month = ?", month).Order("created_at asc, id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.Budget, 0, len(rows))
+	for _, b := range rows {
+		out = append(out, toAPIBudget(b))
+	}
+	return out, nil
+}
+
+func (r *GormBudgetRepo) ListDeleted(ctx context.Context) ([]models.Budget, error) {
+	var rows []dbmodel.Budget
+	err := owned(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc, id asc").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Budget, 0, len(rows))
+	for _, b := range rows {
+		out = append(out, toAPIBudget(b))
+	}
+	return out, nil
 }
 
-func (r *GormBudgetRepo) BulkUpsert(items []models.Budget) {
-	ctx := context.Background()
-	for _, b := range items {
-		row, err := toDBBudget(b)
+func (r *GormBudgetRepo) Restore(ctx context.Context, id string) (models.Budget, error) {
+	tx := owned(ctx, r.db).Unscoped().Model(&dbmodel.Budget{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if tx.Error != nil {
+		return models.Budget{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.Budget{}, errs.ErrNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *GormBudgetRepo) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	tx := owned(ctx, r.db).Unscoped().Where("deleted_at < ?", before).Delete(&dbmodel.Budget{})
+	return int(tx.RowsAffected), tx.Error
+}
+
+func (r *GormBudgetRepo) DeletedOwners(ctx context.Context, before time.Time) ([]string, error) {
+	var out []string
+	err := conn(ctx, r.db).Unscoped().Model(&dbmodel.Budget{}).
+		Where("deleted_at < ?", before).
+		Distinct().Order("user_id").Pluck("user_id", &out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteAll empties the budgets table, trash included. It is meant to run
+// inside a Transactor.
+func (r *GormBudgetRepo) DeleteAll(ctx context.Context) error {
+	return owned(ctx, r.db).Unscoped().Delete(&dbmodel.Budget{}).Error
+}
+
+// CreateMany inserts items in batches, failing on the first bad row.
+func (r *GormBudgetRepo) CreateMany(ctx context.Context, items []models.Budget) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows := make([]dbmodel.Budget, 0, len(items))
+	for _, it := range items {
+		row, err := toDBBudget(auth.UserID(ctx), it)
 		if err != nil {
-			continue
+			return err
 		}
-		_ = r.db.WithContext(ctx).Save(&row).Error
+		rows = append(rows, row)
 	}
+	if err := conn(ctx, r.db).CreateInBatches(&rows, 500).Error; err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
+		if isForeignKeyViolation(err) {
+			return errs.ErrValidation
+		}
+		return err
+	}
+	return nil
 }
 
 func toAPIBudget(b dbmodel.Budget) models.Budget {
@@ -118,12 +191,14 @@ func toAPIBudget(b dbmodel.Budget) models.Budget {
 		Month:       b.Month,
 		CategoryID:  b.CategoryID,
 		AmountCents: b.AmountCents,
+		Currency:    b.Currency,
 		CreatedAt:   b.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt:   b.UpdatedAt.UTC().Format(time.RFC3339),
+		DeletedAt:   formatDeletedAt(b.DeletedAt),
 	}
 }
 
-func toDBBudget(b models.Budget) (dbmodel.Budget, error) {
+func toDBBudget(userID string, b models.Budget) (dbmodel.Budget, error) {
 	createdAt, err := time.Parse(time.RFC3339, b.CreatedAt)
 	if err != nil {
 		createdAt = time.Now().UTC()
@@ -134,12 +209,184 @@ func toDBBudget(b models.Budget) (dbmodel.Budget, error) {
 	}
 	return dbmodel.Budget{
 		ID:          b.ID,
+		UserID:      userID,
 		Month:       b.Month,
 		CategoryID:  b.CategoryID,
 		AmountCents: b.AmountCents,
+		Currency:    models.CurrencyOrDefault(b.Currency),
 		CreatedAt:   createdAt.UTC(),
 		UpdatedAt:   updatedAt.UTC(),
 	}, nil
 }
 
+func (r *GormBudgetRepo) ListTemplates(ctx context.Context) ([]models.BudgetTemplate, error) {
+	var rows []dbmodel.BudgetTemplate
+	err := owned(ctx, r.db).Preload("Lines", orderLines).Order("name asc, id asc").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.BudgetTemplate, 0, len(rows))
+	for _, t := range rows {
+		out = append(out, toAPIBudgetTemplate(t))
+	}
+	return out, nil
+}
 
+func (r *GormBudgetRepo) GetTemplate(ctx context.Context, id string) (models.BudgetTemplate, error) {
+	var row dbmodel.BudgetTemplate
+	if err := owned(ctx, r.db).Preload("Lines", orderLines).First(&row, "id = ?", id).Error; err != nil {
+		if isNotFound(err) {
+			return models.BudgetTemplate{}, errs.ErrNotFound
+		}
+		return models.BudgetTemplate{}, err
+	}
+	return toAPIBudgetTemplate(row), nil
+}
+
+// CreateTemplate inserts the template and its lines. It is meant to run inside
+// a Transactor.
+func (r *GormBudgetRepo) CreateTemplate(ctx context.Context, t models.BudgetTemplate) (models.BudgetTemplate, error) {
+	row := toDBBudgetTemplate(auth.UserID(ctx), t)
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return models.BudgetTemplate{}, errs.ErrConflict
+		}
+		return models.BudgetTemplate{}, err
+	}
+	return r.GetTemplate(ctx, t.ID)
+}
+
+// UpdateTemplate is meant to run inside a Transactor, since replacing the lines
+// takes a delete and an insert.
+func (r *GormBudgetRepo) UpdateTemplate(ctx context.Context, id string, patch BudgetTemplatePatch) (models.BudgetTemplate, error) {
+	updates := map[string]any{}
+	if patch.Name != nil {
+		updates["name"] = *patch.Name
+	}
+	if patch.AutoApply != nil {
+		updates["auto_apply"] = *patch.AutoApply
+	}
+	if patch.UpdatedAt != nil {
+		if t, err := time.Parse(time.RFC3339, *patch.UpdatedAt); err == nil {
+			updates["updated_at"] = t
+		}
+	}
+	if len(updates) > 0 {
+		tx := owned(ctx, r.db).Model(&dbmodel.BudgetTemplate{}).Where("id = ?", id).Updates(updates)
+		if tx.Error != nil {
+			if isUniqueViolation(tx.Error) {
+				return models.BudgetTemplate{}, errs.ErrConflict
+			}
+			return models.BudgetTemplate{}, tx.Error
+		}
+		if tx.RowsAffected == 0 {
+			return models.BudgetTemplate{}, errs.ErrNotFound
+		}
+	}
+	if patch.Lines != nil {
+		if _, err := r.GetTemplate(ctx, id); err != nil {
+			return models.BudgetTemplate{}, err
+		}
+		if err := conn(ctx, r.db).Delete(&dbmodel.BudgetTemplateLine{}, "template_id = ?", id).Error; err != nil {
+			return models.BudgetTemplate{}, err
+		}
+		if lines := toDBBudgetTemplateLines(id, *patch.Lines); len(lines) > 0 {
+			if err := conn(ctx, r.db).Create(&lines).Error; err != nil {
+				if isUniqueViolation(err) {
+					return models.BudgetTemplate{}, errs.ErrConflict
+				}
+				return models.BudgetTemplate{}, err
+			}
+		}
+	}
+	return r.GetTemplate(ctx, id)
+}
+
+// DeleteTemplate removes the template; its lines go with it.
+func (r *GormBudgetRepo) DeleteTemplate(ctx context.Context, id string) error {
+	tx := owned(ctx, r.db).Delete(&dbmodel.BudgetTemplate{}, "id = ?", id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormBudgetRepo) CountTemplatesByCategory(ctx context.Context, categoryID string) (int, error) {
+	var n int64
+	err := conn(ctx, r.db).Model(&dbmodel.BudgetTemplateLine{}).
+		Joins("JOIN budget_templates ON budget_templates.id = budget_template_lines.template_id").
+		Where("budget_templates.user_id = ? AND budget_template_lines.category_id = ?", auth.UserID(ctx), categoryID).
+		Count(&n).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// PruneTemplateLines runs after PUT /state has replaced the categories.
+func (r *GormBudgetRepo) PruneTemplateLines(ctx context.Context) error {
+	userID := auth.UserID(ctx)
+	return conn(ctx, r.db).
+		Where("template_id IN (?)", conn(ctx, r.db).Model(&dbmodel.BudgetTemplate{}).Select("id").Where("user_id = ?", userID)).
+		Where("category_id NOT IN (?)", conn(ctx, r.db).Model(&dbmodel.Category{}).Select("id").Where("user_id = ?", userID)).
+		Delete(&dbmodel.BudgetTemplateLine{}).Error
+}
+
+func orderLines(db *gorm.DB) *gorm.DB {
+	return db.Order("category_id asc")
+}
+
+func toAPIBudgetTemplate(t dbmodel.BudgetTemplate) models.BudgetTemplate {
+	lines := make([]models.BudgetTemplateLine, 0, len(t.Lines))
+	for _, l := range t.Lines {
+		lines = append(lines, models.BudgetTemplateLine{
+			CategoryID:  l.CategoryID,
+			AmountCents: l.AmountCents,
+			Currency:    l.Currency,
+		})
+	}
+	return models.BudgetTemplate{
+		ID:        t.ID,
+		Name:      t.Name,
+		AutoApply: t.AutoApply,
+		Lines:     lines,
+		CreatedAt: t.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: t.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toDBBudgetTemplate(userID string, t models.BudgetTemplate) dbmodel.BudgetTemplate {
+	createdAt, err := time.Parse(time.RFC3339, t.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	updatedAt, err := time.Parse(time.RFC3339, t.UpdatedAt)
+	if err != nil {
+		updatedAt = createdAt
+	}
+	return dbmodel.BudgetTemplate{
+		ID:        t.ID,
+		UserID:    userID,
+		Name:      t.Name,
+		AutoApply: t.AutoApply,
+		CreatedAt: createdAt.UTC(),
+		UpdatedAt: updatedAt.UTC(),
+		Lines:     toDBBudgetTemplateLines(t.ID, t.Lines),
+	}
+}
+
+func toDBBudgetTemplateLines(templateID string, lines []models.BudgetTemplateLine) []dbmodel.BudgetTemplateLine {
+	out := make([]dbmodel.BudgetTemplateLine, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, dbmodel.BudgetTemplateLine{
+			TemplateID:  templateID,
+			CategoryID:  l.CategoryID,
+			AmountCents: l.AmountCents,
+			Currency:    models.CurrencyOrDefault(l.Currency),
+		})
+	}
+	return out
+}
@@ -6,6 +6,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"personal-budgeting/be/internal/auth"
 	"personal-budgeting/be/internal/dbmodel"
 	"personal-budgeting/be/internal/errs"
 	"personal-budgeting/be/internal/models"
@@ -23,7 +24,7 @@ var _ CategoryRepository = (*GormCategoryRepo)(nil)
 
 func (r *GormCategoryRepo) List(ctx context.Context) ([]models.Category, error) {
 	var rows []dbmodel.Category
-	if err := r.db.WithContext(ctx).Order("created_at asc").Find(&rows).Error; err != nil {
+	if err := owned(ctx, r.db).Order("created_at asc").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]models.Category, 0, len(rows))
@@ -35,7 +36,7 @@ func (r *GormCategoryRepo) List(ctx context.Context) ([]models.Category, error)
 
 func (r *GormCategoryRepo) Get(ctx context.Context, id string) (models.Category, error) {
 	var row dbmodel.Category
-	if err := r.db.WithContext(ctx).First(&row, "id = ?", id).Error; err != nil {
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
 		if isNotFound(err) {
 			return models.Category{}, errs.ErrNotFound
 		}
@@ -45,11 +46,11 @@ func (r *GormCategoryRepo) Get(ctx context.Context, id string) (models.Category,
 }
 
 func (r *GormCategoryRepo) Create(ctx context.Context, c models.Category) (models.Category, error) {
-	row, err := toDBCategory(c)
+	row, err := toDBCategory(auth.UserID(ctx), c)
 	if err != nil {
 		return models.Category{}, err
 	}
-	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
 		if isUniqueViolation(err) {
 			return models.Category{}, errs.ErrConflict
 		}
@@ -66,26 +67,45 @@ func (r *GormCategoryRepo) Update(ctx context.Context, id string, patch Category
 	if patch.Description != nil {
 		updates["description"] = *patch.Description
 	}
+	if patch.ParentID != nil {
+		updates["parent_id"] = nullableString(*patch.ParentID)
+	}
+	if patch.RolloverPolicy != nil {
+		updates["rollover_policy"] = string(*patch.RolloverPolicy)
+	}
 	if patch.UpdatedAt != nil {
 		if t, err := time.Parse(time.RFC3339, *patch.UpdatedAt); err == nil {
 			updates["updated_at"] = t
 		}
 	}
 	if len(updates) == 0 {
-		return r.Get(ctx, id)
+		c, err := r.Get(ctx, id)
+		if err == nil && patch.IfVersion != 0 && c.Version != patch.IfVersion {
+			return models.Category{}, errs.ErrPreconditionFailed
+		}
+		return c, err
 	}
-	tx := r.db.WithContext(ctx).Model(&dbmodel.Category{}).Where("id = ?", id).Updates(updates)
+	updates["version"] = gorm.Expr("version + 1")
+	q := owned(ctx, r.db).Model(&dbmodel.Category{}).Where("id = ?", id)
+	if patch.IfVersion != 0 {
+		q = q.Where("version = ?", patch.IfVersion)
+	}
+	tx := q.Updates(updates)
 	if tx.Error != nil {
 		return models.Category{}, tx.Error
 	}
 	if tx.RowsAffected == 0 {
-		return models.Category{}, errs.ErrNotFound
+		// Missing, or no longer at IfVersion.
+		if _, err := r.Get(ctx, id); err != nil {
+			return models.Category{}, err
+		}
+		return models.Category{}, errs.ErrPreconditionFailed
 	}
 	return r.Get(ctx, id)
 }
 
 func (r *GormCategoryRepo) Delete(ctx context.Context, id string) error {
-	tx := r.db.WithContext(ctx).Delete(&dbmodel.Category{}, "id = ?", id)
+	tx := owned(ctx, r.db).Delete(&dbmodel.Category{}, "id = ?", id)
 	if tx.Error != nil {
 		if isForeignKeyViolation(tx.Error) {
 			return errs.ErrConflict
@@ -98,34 +118,103 @@ func (r *GormCategoryRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-// Reset/BulkUpsert are used by StateService.Replace via type assertion.
-func (r *GormCategoryRepo) Reset() {
-	_ = r.db.WithContext(context.Background()).Exec("DELETE FROM categories").Error
+func (r *GormCategoryRepo) ListDeleted(ctx context.Context) ([]models.Category, error) {
+	var rows []dbmodel.Category
+	err := owned(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc, id asc").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Category, 0, len(rows))
+	for _, c := range rows {
+		out = append(out, toAPICategory(c))
+	}
+	return out, nil
+}
+
+func (r *GormCategoryRepo) Restore(ctx context.Context, id string) (models.Category, error) {
+	tx := owned(ctx, r.db).Unscoped().Model(&dbmodel.Category{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if tx.Error != nil {
+		return models.Category{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.Category{}, errs.ErrNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+// PurgeDeleted hard-deletes categories trashed before the cutoff. Categories
+// still used by a transaction or budget row, trashed or not, are kept for a
+// later run.
+func (r *GormCategoryRepo) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	tx := owned(ctx, r.db).Unscoped().
+		Where("deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM transactions t WHERE t.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM txn_splits s WHERE s.category_id = categories.id)").
+		Where("NOT EXISTS (SELECT 1 FROM budgets b WHERE b.category_id = categories.id)").
+		Delete(&dbmodel.Category{})
+	return int(tx.RowsAffected), tx.Error
+}
+
+func (r *GormCategoryRepo) DeletedOwners(ctx context.Context, before time.Time) ([]string, error) {
+	var out []string
+	err := conn(ctx, r.db).Unscoped().Model(&dbmodel.Category{}).
+		Where("deleted_at < ?", before).
+		Distinct().Order("user_id").Pluck("user_id", &out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteAll empties the categories table, trash included. It is meant to run
+// inside a Transactor.
+func (r *GormCategoryRepo) DeleteAll(ctx context.Context) error {
+	return owned(ctx, r.db).Unscoped().Delete(&dbmodel.Category{}).Error
 }
 
-func (r *GormCategoryRepo) BulkUpsert(items []models.Category) {
-	ctx := context.Background()
-	for _, c := range items {
-		row, err := toDBCategory(c)
+// CreateMany inserts items in batches, failing on the first bad row.
+func (r *GormCategoryRepo) CreateMany(ctx context.Context, items []models.Category) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows := make([]dbmodel.Category, 0, len(items))
+	for _, it := range items {
+		row, err := toDBCategory(auth.UserID(ctx), it)
 		if err != nil {
-			continue
+			return err
 		}
-		_ = r.db.WithContext(ctx).Save(&row).Error
+		rows = append(rows, row)
 	}
+	if err := conn(ctx, r.db).CreateInBatches(&rows, 500).Error; err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
+		if isForeignKeyViolation(err) {
+			return errs.ErrValidation
+		}
+		return err
+	}
+	return nil
 }
 
 func toAPICategory(c dbmodel.Category) models.Category {
 	return models.Category{
-		ID:          c.ID,
-		Type:        models.CategoryType(c.Type),
-		Name:        c.Name,
-		Description: c.Description,
-		CreatedAt:   c.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt:   c.UpdatedAt.UTC().Format(time.RFC3339),
+		ID:             c.ID,
+		Type:           models.CategoryType(c.Type),
+		Name:           c.Name,
+		Description:    c.Description,
+		ParentID:       derefString(c.ParentID),
+		RolloverPolicy: models.RolloverPolicy(c.RolloverPolicy),
+		Version:        c.Version,
+		CreatedAt:      c.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:      c.UpdatedAt.UTC().Format(time.RFC3339),
+		DeletedAt:      formatDeletedAt(c.DeletedAt),
 	}
 }
 
-func toDBCategory(c models.Category) (dbmodel.Category, error) {
+func toDBCategory(userID string, c models.Category) (dbmodel.Category, error) {
 	createdAt, err := time.Parse(time.RFC3339, c.CreatedAt)
 	if err != nil {
 		createdAt = time.Now().UTC()
@@ -135,13 +224,22 @@ func toDBCategory(c models.Category) (dbmodel.Category, error) {
 		updatedAt = createdAt
 	}
 	return dbmodel.Category{
-		ID:          c.ID,
-		Type:        string(c.Type),
-		Name:        c.Name,
-		Description: c.Description,
-		CreatedAt:   createdAt.UTC(),
-		UpdatedAt:   updatedAt.UTC(),
+		ID:             c.ID,
+		UserID:         userID,
+		Type:           string(c.Type),
+		Name:           c.Name,
+		Description:    c.Description,
+		ParentID:       nullableString(c.ParentID),
+		RolloverPolicy: rolloverOrDefault(c.RolloverPolicy),
+		Version:        versionOrDefault(c.Version),
+		CreatedAt:      createdAt.UTC(),
+		UpdatedAt:      updatedAt.UTC(),
 	}, nil
 }
 
-
+func rolloverOrDefault(p models.RolloverPolicy) string {
+	if p == "" {
+		return string(models.RolloverNone)
+	}
+	return string(p)
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormPayeeRepo struct {
+	db *gorm.DB
+}
+
+func NewGormPayeeRepo(db *gorm.DB) *GormPayeeRepo {
+	return &GormPayeeRepo{db: db}
+}
+
+var _ PayeeRepository = (*GormPayeeRepo)(nil)
+
+func (r *GormPayeeRepo) List(ctx context.Context) ([]models.Payee, error) {
+	var rows []dbmodel.Payee
+	if err := owned(ctx, r.db).Order("name asc, id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.Payee, 0, len(rows))
+	for _, p := range rows {
+		out = append(out, toAPIPayee(p))
+	}
+	return out, nil
+}
+
+func (r *GormPayeeRepo) Get(ctx context.Context, id string) (models.Payee, error) {
+	var row dbmodel.Payee
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
+		if isNotFound(err) {
+			return models.Payee{}, errs.ErrNotFound
+		}
+		return models.Payee{}, err
+	}
+	return toAPIPayee(row), nil
+}
+
+func (r *GormPayeeRepo) Create(ctx context.Context, p models.Payee) (models.Payee, error) {
+	row := toDBPayee(auth.UserID(ctx), p)
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		return models.Payee{}, err
+	}
+	return r.Get(ctx, p.ID)
+}
+
+func (r *GormPayeeRepo) Update(ctx context.Context, id string, patch PayeePatch) (models.Payee, error) {
+	updates := map[string]any{}
+	if patch.Name != nil {
+		updates["name"] = *patch.Name
+	}
+	if patch.Aliases != nil {
+		aliases, err := json.Marshal(nonNilStrings(*patch.Aliases))
+		if err != nil {
+			return models.Payee{}, err
+		}
+		// A map update bypasses the column's JSON serializer.
+		updates["aliases"] = string(aliases)
+	}
+	if patch.UpdatedAt != nil {
+		if t, err := time.Parse(time.RFC3339, *patch.UpdatedAt); err == nil {
+			updates["updated_at"] = t
+		}
+	}
+	if len(updates) == 0 {
+		return r.Get(ctx, id)
+	}
+	tx := owned(ctx, r.db).Model(&dbmodel.Payee{}).Where("id = ?", id).Updates(updates)
+	if tx.Error != nil {
+		return models.Payee{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.Payee{}, errs.ErrNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *GormPayeeRepo) Delete(ctx context.Context, id string) error {
+	tx := owned(ctx, r.db).Delete(&dbmodel.Payee{}, "id = ?", id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAll removes the user's payees. It is meant to run inside a Transactor.
+func (r *GormPayeeRepo) DeleteAll(ctx context.Context) error {
+	return owned(ctx, r.db).Delete(&dbmodel.Payee{}).Error
+}
+
+// CreateMany inserts items in batches, failing on the first bad row.
+func (r *GormPayeeRepo) CreateMany(ctx context.Context, items []models.Payee) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows := make([]dbmodel.Payee, 0, len(items))
+	for _, it := range items {
+		rows = append(rows, toDBPayee(auth.UserID(ctx), it))
+	}
+	return conn(ctx, r.db).CreateInBatches(&rows, 500).Error
+}
+
+func toAPIPayee(p dbmodel.Payee) models.Payee {
+	return models.Payee{
+		ID:        p.ID,
+		Name:      p.Name,
+		Aliases:   nonNilStrings(p.Aliases),
+		CreatedAt: p.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: p.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toDBPayee(userID string, p models.Payee) dbmodel.Payee {
+	createdAt, err := time.Parse(time.RFC3339, p.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	updatedAt, err := time.Parse(time.RFC3339, p.UpdatedAt)
+	if err != nil {
+		updatedAt = createdAt
+	}
+	return dbmodel.Payee{
+		ID:        p.ID,
+		UserID:    userID,
+		Name:      p.Name,
+		Aliases:   nonNilStrings(p.Aliases),
+		CreatedAt: createdAt.UTC(),
+		UpdatedAt: updatedAt.UTC(),
+	}
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormRateRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRateRepo(db *gorm.DB) *GormRateRepo {
+	return &GormRateRepo{db: db}
+}
+
+var _ RateRepository = (*GormRateRepo)(nil)
+
+func (r *GormRateRepo) List(ctx context.Context, f RateFilter) ([]models.ExchangeRate, error) {
+	q := owned(ctx, r.db)
+	if f.Base != "" {
+		q = q.Where("base = ?", f.Base)
+	}
+	if f.Quote != "" {
+		q = q.Where("quote = ?", f.Quote)
+	}
+	if f.From != "" {
+		q = q.Where("date >= ?", f.From)
+	}
+	if f.To != "" {
+		q = q.Where("date <= ?", f.To)
+	}
+	var rows []dbmodel.ExchangeRate
+	if err := q.Order("base, quote, date").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.ExchangeRate, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toAPIRate(row))
+	}
+	return out, nil
+}
+
+func (r *GormRateRepo) Upsert(ctx context.Context, rate models.ExchangeRate) (models.ExchangeRate, error) {
+	createdAt, err := time.Parse(time.RFC3339, rate.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	row := dbmodel.ExchangeRate{
+		ID:        rate.ID,
+		UserID:    auth.UserID(ctx),
+		Base:      rate.Base,
+		Quote:     rate.Quote,
+		Date:      rate.Date,
+		Rate:      rate.Rate,
+		CreatedAt: createdAt.UTC(),
+		UpdatedAt: createdAt.UTC(),
+	}
+	err = conn(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "base"}, {Name: "quote"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+		}).
+		Create(&row).Error
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+
+	var out dbmodel.ExchangeRate
+	err = owned(ctx, r.db).First(&out, "base = ? AND quote = ? AND date = ?", rate.Base, rate.Quote, rate.Date).Error
+	if err != nil {
+		return models.ExchangeRate{}, err
+	}
+	return toAPIRate(out), nil
+}
+
+func (r *GormRateRepo) Delete(ctx context.Context, id string) error {
+	tx := owned(ctx, r.db).Delete(&dbmodel.ExchangeRate{}, "id = ?", id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func toAPIRate(r dbmodel.ExchangeRate) models.ExchangeRate {
+	return models.ExchangeRate{
+		ID:        r.ID,
+		Date:      r.Date,
+		Base:      r.Base,
+		Quote:     r.Quote,
+		Rate:      r.Rate,
+		CreatedAt: r.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: r.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormRecurringRuleRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRecurringRuleRepo(db *gorm.DB) *GormRecurringRuleRepo {
+	return &GormRecurringRuleRepo{db: db}
+}
+
+var _ RecurringRuleRepository = (*GormRecurringRuleRepo)(nil)
+
+func (r *GormRecurringRuleRepo) List(ctx context.Context) ([]models.RecurringRule, error) {
+	var rows []dbmodel.RecurringRule
+	if err := owned(ctx, r.db).Order("created_at asc, id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.RecurringRule, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toAPIRecurringRule(row))
+	}
+	return out, nil
+}
+
+func (r *GormRecurringRuleRepo) Get(ctx context.Context, id string) (models.RecurringRule, error) {
+	var row dbmodel.RecurringRule
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
+		if isNotFound(err) {
+			return models.RecurringRule{}, errs.ErrNotFound
+		}
+		return models.RecurringRule{}, err
+	}
+	return toAPIRecurringRule(row), nil
+}
+
+func (r *GormRecurringRuleRepo) Create(ctx context.Context, rule models.RecurringRule) (models.RecurringRule, error) {
+	row := toDBRecurringRule(auth.UserID(ctx), rule)
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return models.RecurringRule{}, errs.ErrConflict
+		}
+		return models.RecurringRule{}, err
+	}
+	return r.Get(ctx, rule.ID)
+}
+
+func (r *GormRecurringRuleRepo) Update(ctx context.Context, id string, patch RecurringRulePatch) (models.RecurringRule, error) {
+	updates := map[string]any{}
+	if patch.Name != nil {
+		updates["name"] = *patch.Name
+	}
+	if patch.CategoryID != nil {
+		updates["category_id"] = *patch.CategoryID
+	}
+	if patch.AmountCents != nil {
+		updates["amount_cents"] = *patch.AmountCents
+	}
+	if patch.Note != nil {
+		updates["note"] = *patch.Note
+	}
+	if patch.EndDate != nil {
+		updates["end_date"] = *patch.EndDate
+	}
+	if patch.Paused != nil {
+		updates["paused"] = *patch.Paused
+	}
+	if patch.LastRunDate != nil {
+		updates["last_run_date"] = *patch.LastRunDate
+	}
+	if patch.UpdatedAt != nil {
+		if t, err := time.Parse(time.RFC3339, *patch.UpdatedAt); err == nil {
+			updates["updated_at"] = t
+		}
+	}
+	if len(updates) == 0 {
+		return r.Get(ctx, id)
+	}
+	tx := owned(ctx, r.db).Model(&dbmodel.RecurringRule{}).Where("id = ?", id).Updates(updates)
+	if tx.Error != nil {
+		return models.RecurringRule{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.RecurringRule{}, errs.ErrNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *GormRecurringRuleRepo) Delete(ctx context.Context, id string) error {
+	tx := owned(ctx, r.db).Delete(&dbmodel.RecurringRule{}, "id = ?", id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormRecurringRuleRepo) CountByCategory(ctx context.Context, categoryID string) (int, error) {
+	var n int64
+	if err := owned(ctx, r.db).Model(&dbmodel.RecurringRule{}).Where("category_id = ?", categoryID).Count(&n).Error; err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *GormRecurringRuleRepo) CountByAccount(ctx context.Context, accountID string) (int, error) {
+	var n int64
+	if err := owned(ctx, r.db).Model(&dbmodel.RecurringRule{}).Where("account_id = ?", accountID).Count(&n).Error; err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *GormRecurringRuleRepo) DueOwners(ctx context.Context, date string) ([]string, error) {
+	var out []string
+	err := conn(ctx, r.db).Model(&dbmodel.RecurringRule{}).
+		Where("paused = ? AND last_run_date < ? AND start_date <= ?", false, date, date).
+		Where("end_date = '' OR last_run_date < end_date").
+		Distinct().Order("user_id").Pluck("user_id", &out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteAll removes the user's rules. It is meant to run inside a Transactor.
+func (r *GormRecurringRuleRepo) DeleteAll(ctx context.Context) error {
+	return owned(ctx, r.db).Delete(&dbmodel.RecurringRule{}).Error
+}
+
+// CreateMany inserts items in batches, failing on the first bad row.
+func (r *GormRecurringRuleRepo) CreateMany(ctx context.Context, items []models.RecurringRule) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows := make([]dbmodel.RecurringRule, 0, len(items))
+	for _, it := range items {
+		rows = append(rows, toDBRecurringRule(auth.UserID(ctx), it))
+	}
+	if err := conn(ctx, r.db).CreateInBatches(&rows, 500).Error; err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func toAPIRecurringRule(r dbmodel.RecurringRule) models.RecurringRule {
+	return models.RecurringRule{
+		ID:           r.ID,
+		Name:         r.Name,
+		Kind:         models.TransactionKind(r.Kind),
+		CategoryID:   r.CategoryID,
+		AccountID:    derefString(r.AccountID),
+		AmountCents:  r.AmountCents,
+		Currency:     r.Currency,
+		Note:         r.Note,
+		Frequency:    models.RecurringFrequency(r.Frequency),
+		DayOfMonth:   r.DayOfMonth,
+		IntervalDays: r.IntervalDays,
+		StartDate:    r.StartDate,
+		EndDate:      r.EndDate,
+		Paused:       r.Paused,
+		LastRunDate:  r.LastRunDate,
+		CreatedAt:    r.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    r.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toDBRecurringRule(userID string, r models.RecurringRule) dbmodel.RecurringRule {
+	createdAt, err := time.Parse(time.RFC3339, r.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	updatedAt, err := time.Parse(time.RFC3339, r.UpdatedAt)
+	if err != nil {
+		updatedAt = createdAt
+	}
+	return dbmodel.RecurringRule{
+		ID:           r.ID,
+		UserID:       userID,
+		Name:         r.Name,
+		Kind:         string(r.Kind),
+		CategoryID:   r.CategoryID,
+		AccountID:    nullableString(r.AccountID),
+		AmountCents:  r.AmountCents,
+		Currency:     models.CurrencyOrDefault(r.Currency),
+		Note:         r.Note,
+		Frequency:    string(r.Frequency),
+		DayOfMonth:   r.DayOfMonth,
+		IntervalDays: r.IntervalDays,
+		StartDate:    r.StartDate,
+		EndDate:      r.EndDate,
+		Paused:       r.Paused,
+		LastRunDate:  r.LastRunDate,
+		CreatedAt:    createdAt.UTC(),
+		UpdatedAt:    updatedAt.UTC(),
+	}
+}
@@ -0,0 +1,280 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormReportRepo struct {
+	db *gorm.DB
+}
+
+func NewGormReportRepo(db *gorm.DB) *GormReportRepo {
+	return &GormReportRepo{db: db}
+}
+
+var _ ReportRepository = (*GormReportRepo)(nil)
+
+// rateSQL looks up how many units of @ccy one unit of ccyCol was worth on
+// dateExpr, using the latest rate on or before that date in either direction.
+// It is NULL when no rate exists. Queries using it bind @uid and @ccy, and
+// must qualify ccyCol and dateExpr: a bare "date" would resolve to r.date.
+func rateSQL(ccyCol string, dateExpr string) string {
+	return fmt.Sprintf(`COALESCE(
+		(SELECT r.rate FROM exchange_rates r
+		 WHERE r.user_id = @uid AND r.base = %[1]s AND r.quote = @ccy AND r.date <= %[2]s
+		 ORDER BY r.date DESC LIMIT 1),
+		(SELECT 1.0 / r.rate FROM exchange_rates r
+		 WHERE r.user_id = @uid AND r.base = @ccy AND r.quote = %[1]s AND r.date <= %[2]s
+		 ORDER BY r.date DESC LIMIT 1))`, ccyCol, dateExpr)
+}
+
+// convertedSQL is amountCol expressed in @ccy cents, or NULL when no rate applies.
+func convertedSQL(amountCol string, ccyCol string, dateExpr string) string {
+	return fmt.Sprintf("CASE WHEN %[2]s = @ccy THEN %[1]s ELSE ROUND(%[1]s * %[3]s) END",
+		amountCol, ccyCol, rateSQL(ccyCol, dateExpr))
+}
+
+// sumSQL sums converted amounts as an integer; rows without a rate are skipped.
+func sumSQL(amountCol string, ccyCol string, dateExpr string) string {
+	return fmt.Sprintf("CAST(COALESCE(SUM(%s), 0) AS BIGINT)", convertedSQL(amountCol, ccyCol, dateExpr))
+}
+
+// txnLinesSQL yields one row per category line: each line of a split
+// transaction, or the transaction itself when it has none. Category figures
+// read from it instead of transactions. Trashed transactions are left out here
+// and in every other report query.
+const txnLinesSQL = `(
+	SELECT t.id, t.user_id, t.kind, t.date, t.currency,
+	       COALESCE(s.category_id, t.category_id) AS category_id,
+	       COALESCE(s.amount_cents, t.amount_cents) AS amount_cents
+	FROM transactions t
+	LEFT JOIN txn_splits s ON s.txn_id = t.id
+	WHERE t.deleted_at IS NULL)`
+
+func (r *GormReportRepo) args(ctx context.Context, currency string, extra map[string]any) map[string]any {
+	out := map[string]any{
+		"uid":     auth.UserID(ctx),
+		"ccy":     currency,
+		"income":  string(models.KindIncome),
+		"expense": string(models.KindExpense),
+	}
+	for k, v := range extra {
+		out[k] = v
+	}
+	return out
+}
+
+func (r *GormReportRepo) CategoryTotals(ctx context.Context, from string, to string, currency string) ([]models.CategoryTotal, error) {
+	var rows []struct {
+		CategoryID   string
+		CategoryName string
+		Kind         string
+		TotalCents   int64
+		TxnCount     int
+	}
+	err := conn(ctx, r.db).Raw(`
+		SELECT t.category_id, c.name AS category_name, t.kind,
+		       `+sumSQL("t.amount_cents", "t.currency", "t.date")+` AS total_cents, COUNT(DISTINCT t.id) AS txn_count
+		FROM `+txnLinesSQL+` t
+		JOIN categories c ON c.id = t.category_id
+		WHERE t.user_id = @uid AND t.kind IN (@income, @expense) AND t.date >= @from AND t.date <= @to
+		GROUP BY t.category_id, c.name, t.kind
+		ORDER BY t.kind, total_cents DESC`,
+		r.args(ctx, currency, map[string]any{"from": from, "to": to})).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.CategoryTotal, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, models.CategoryTotal{
+			CategoryID:   row.CategoryID,
+			CategoryName: row.CategoryName,
+			Kind:         models.TransactionKind(row.Kind),
+			TotalCents:   row.TotalCents,
+			Count:        row.TxnCount,
+		})
+	}
+	return out, nil
+}
+
+func (r *GormReportRepo) TagTotals(ctx context.Context, from string, to string, currency string) ([]models.TagTotal, error) {
+	var rows []struct {
+		TagID      string
+		TagName    string
+		TotalCents int64
+		TxnCount   int
+	}
+	err := conn(ctx, r.db).Raw(`
+		SELECT g.id AS tag_id, g.name AS tag_name,
+		       `+sumSQL("t.amount_cents", "t.currency", "t.date")+` AS total_cents, COUNT(*) AS txn_count
+		FROM transactions t
+		JOIN txn_tags tt ON tt.txn_id = t.id
+		JOIN tags g ON g.id = tt.tag_id
+		WHERE t.user_id = @uid AND t.deleted_at IS NULL AND t.kind = @expense AND t.date >= @from AND t.date <= @to
+		GROUP BY g.id, g.name
+		ORDER BY total_cents DESC, g.name`,
+		r.args(ctx, currency, map[string]any{"from": from, "to": to})).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.TagTotal, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, models.TagTotal{
+			TagID:      row.TagID,
+			TagName:    row.TagName,
+			TotalCents: row.TotalCents,
+			Count:      row.TxnCount,
+		})
+	}
+	return out, nil
+}
+
+func (r *GormReportRepo) PayeeTotals(ctx context.Context, from string, to string, currency string, limit int) ([]models.PayeeTotal, error) {
+	var rows []struct {
+		PayeeID    string
+		PayeeName  string
+		TotalCents int64
+		TxnCount   int
+	}
+	err := conn(ctx, r.db).Raw(`
+		SELECT p.id AS payee_id, p.name AS payee_name,
+		       `+sumSQL("t.amount_cents", "t.currency", "t.date")+` AS total_cents, COUNT(*) AS txn_count
+		FROM transactions t
+		JOIN payees p ON p.id = t.payee_id
+		WHERE t.user_id = @uid AND t.deleted_at IS NULL AND t.kind = @expense AND t.date >= @from AND t.date <= @to
+		GROUP BY p.id, p.name
+		ORDER BY total_cents DESC, p.name
+		LIMIT @limit`,
+		r.args(ctx, currency, map[string]any{"from": from, "to": to, "limit": limit})).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.PayeeTotal, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, models.PayeeTotal{
+			PayeeID:    row.PayeeID,
+			PayeeName:  row.PayeeName,
+			TotalCents: row.TotalCents,
+			Count:      row.TxnCount,
+		})
+	}
+	return out, nil
+}
+
+func (r *GormReportRepo) BudgetActuals(ctx context.Context, month string, from string, to string, currency string) ([]models.BudgetLine, error) {
+	var rows []struct {
+		CategoryID    string
+		CategoryName  string
+		BudgetedCents int64
+		SpentCents    int64
+	}
+	err := conn(ctx, r.db).Raw(`
+		SELECT c.id AS category_id, c.name AS category_name,
+		       CAST(COALESCE(`+convertedSQL("b.amount_cents", "b.currency", "@monthEnd")+`, 0) AS BIGINT) AS budgeted_cents,
+		       COALESCE(s.spent_cents, 0) AS spent_cents
+		FROM categories c
+		LEFT JOIN budgets b ON b.category_id = c.id AND b.user_id = c.user_id AND b.month = @month AND b.deleted_at IS NULL
+		LEFT JOIN (
+			SELECT l.category_id, `+sumSQL("l.amount_cents", "l.currency", "l.date")+` AS spent_cents
+			FROM `+txnLinesSQL+` l
+			WHERE l.user_id = @uid AND l.kind = @expense AND l.date >= @from AND l.date <= @to
+			GROUP BY l.category_id
+		) s ON s.category_id = c.id
+		WHERE c.user_id = @uid AND c.deleted_at IS NULL AND c.type = @expense AND (b.id IS NOT NULL OR s.spent_cents IS NOT NULL)
+		ORDER BY c.name, c.id`,
+		r.args(ctx, currency, map[string]any{"month": month, "monthEnd": to, "from": from, "to": to})).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.BudgetLine, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, models.BudgetLine{
+			CategoryID:    row.CategoryID,
+			CategoryName:  row.CategoryName,
+			BudgetedCents: row.BudgetedCents,
+			SpentCents:    row.SpentCents,
+		})
+	}
+	return out, nil
+}
+
+func (r *GormReportRepo) MonthlyExpenses(ctx context.Context, from string, to string, categoryIDs []string, currency string) ([]MonthlyAmount, error) {
+	catFilter := ""
+	if len(categoryIDs) > 0 {
+		catFilter = "AND l.category_id IN @cats"
+	}
+	var out []MonthlyAmount
+	err := conn(ctx, r.db).Raw(`
+		SELECT SUBSTR(l.date, 1, 7) AS month, l.category_id, `+sumSQL("l.amount_cents", "l.currency", "l.date")+` AS amount_cents
+		FROM `+txnLinesSQL+` l
+		WHERE l.user_id = @uid AND l.kind = @expense AND l.date >= @from AND l.date <= @to `+catFilter+`
+		GROUP BY SUBSTR(l.date, 1, 7), l.category_id`,
+		r.args(ctx, currency, map[string]any{"from": from, "to": to, "cats": categoryIDs})).
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *GormReportRepo) MonthlyBudgets(ctx context.Context, fromMonth string, toMonth string, categoryIDs []string, currency string) ([]MonthlyAmount, error) {
+	catFilter := ""
+	if len(categoryIDs) > 0 {
+		catFilter = "AND category_id IN @cats"
+	}
+	// Budgets convert at the last rate of their month; "-31" sorts after every real day.
+	var out []MonthlyAmount
+	err := conn(ctx, r.db).Raw(`
+		SELECT month, category_id,
+		       CAST(COALESCE(`+convertedSQL("amount_cents", "currency", "month || '-31'")+`, 0) AS BIGINT) AS amount_cents
+		FROM budgets
+		WHERE user_id = @uid AND deleted_at IS NULL AND month >= @fromMonth AND month <= @toMonth `+catFilter,
+		r.args(ctx, currency, map[string]any{"fromMonth": fromMonth, "toMonth": toMonth, "cats": categoryIDs})).
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *GormReportRepo) MissingRates(ctx context.Context, from string, to string, currency string) ([]string, error) {
+	var out []string
+	err := conn(ctx, r.db).Raw(`
+		SELECT DISTINCT t.currency
+		FROM transactions t
+		WHERE t.user_id = @uid AND t.deleted_at IS NULL AND t.kind IN (@income, @expense) AND t.date >= @from AND t.date <= @to
+		  AND t.currency <> @ccy AND `+rateSQL("t.currency", "t.date")+` IS NULL
+		ORDER BY t.currency`,
+		r.args(ctx, currency, map[string]any{"from": from, "to": to})).
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *GormReportRepo) MissingBudgetRates(ctx context.Context, fromMonth string, toMonth string, currency string) ([]string, error) {
+	var out []string
+	err := conn(ctx, r.db).Raw(`
+		SELECT DISTINCT b.currency
+		FROM budgets b
+		WHERE b.user_id = @uid AND b.deleted_at IS NULL AND b.month >= @fromMonth AND b.month <= @toMonth
+		  AND b.currency <> @ccy AND `+rateSQL("b.currency", "b.month || '-31'")+` IS NULL
+		ORDER BY b.currency`,
+		r.args(ctx, currency, map[string]any{"fromMonth": fromMonth, "toMonth": toMonth})).
+		Scan(&out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
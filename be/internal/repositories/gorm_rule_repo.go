@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormRuleRepo struct {
+	db *gorm.DB
+}
+
+func NewGormRuleRepo(db *gorm.DB) *GormRuleRepo {
+	return &GormRuleRepo{db: db}
+}
+
+var _ RuleRepository = (*GormRuleRepo)(nil)
+
+func (r *GormRuleRepo) List(ctx context.Context) ([]models.Rule, error) {
+	var rows []dbmodel.Rule
+	if err := owned(ctx, r.db).Order("priority asc, name asc, id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.Rule, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toAPIRule(row))
+	}
+	return out, nil
+}
+
+func (r *GormRuleRepo) Get(ctx context.Context, id string) (models.Rule, error) {
+	var row dbmodel.Rule
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
+		if isNotFound(err) {
+			return models.Rule{}, errs.ErrNotFound
+		}
+		return models.Rule{}, err
+	}
+	return toAPIRule(row), nil
+}
+
+func (r *GormRuleRepo) Create(ctx context.Context, rule models.Rule) (models.Rule, error) {
+	row := toDBRule(auth.UserID(ctx), rule)
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return models.Rule{}, errs.ErrConflict
+		}
+		return models.Rule{}, err
+	}
+	return r.Get(ctx, rule.ID)
+}
+
+func (r *GormRuleRepo) Update(ctx context.Context, id string, patch RulePatch) (models.Rule, error) {
+	updates := map[string]any{}
+	if patch.Name != nil {
+		updates["name"] = *patch.Name
+	}
+	if patch.Priority != nil {
+		updates["priority"] = *patch.Priority
+	}
+	if patch.Paused != nil {
+		updates["paused"] = *patch.Paused
+	}
+	if c := patch.Conditions; c != nil {
+		updates["note_contains"] = c.NoteContains
+		updates["note_regex"] = c.NoteRegex
+		updates["min_amount_cents"] = c.MinAmountCents
+		updates["max_amount_cents"] = c.MaxAmountCents
+		updates["kind"] = string(c.Kind)
+		updates["account_id"] = c.AccountID
+	}
+	if a := patch.Actions; a != nil {
+		tagIDs, err := json.Marshal(nonNilStrings(a.TagIDs))
+		if err != nil {
+			return models.Rule{}, err
+		}
+		updates["category_id"] = a.CategoryID
+		// A map update bypasses the column's JSON serializer.
+		updates["tag_ids"] = string(tagIDs)
+		updates["set_note"] = a.SetNote
+	}
+	if patch.UpdatedAt != nil {
+		if t, err := time.Parse(time.RFC3339, *patch.UpdatedAt); err == nil {
+			updates["updated_at"] = t
+		}
+	}
+	if len(updates) == 0 {
+		return r.Get(ctx, id)
+	}
+	tx := owned(ctx, r.db).Model(&dbmodel.Rule{}).Where("id = ?", id).Updates(updates)
+	if tx.Error != nil {
+		return models.Rule{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.Rule{}, errs.ErrNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *GormRuleRepo) Delete(ctx context.Context, id string) error {
+	tx := owned(ctx, r.db).Delete(&dbmodel.Rule{}, "id = ?", id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormRuleRepo) CountByCategory(ctx context.Context, categoryID string) (int, error) {
+	var n int64
+	if err := owned(ctx, r.db).Model(&dbmodel.Rule{}).Where("category_id = ?", categoryID).Count(&n).Error; err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *GormRuleRepo) CountByAccount(ctx context.Context, accountID string) (int, error) {
+	var n int64
+	if err := owned(ctx, r.db).Model(&dbmodel.Rule{}).Where("account_id = ?", accountID).Count(&n).Error; err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func toAPIRule(r dbmodel.Rule) models.Rule {
+	return models.Rule{
+		ID:       r.ID,
+		Name:     r.Name,
+		Priority: r.Priority,
+		Paused:   r.Paused,
+		Conditions: models.RuleConditions{
+			NoteContains:   r.NoteContains,
+			NoteRegex:      r.NoteRegex,
+			MinAmountCents: r.MinAmountCents,
+			MaxAmountCents: r.MaxAmountCents,
+			Kind:           models.TransactionKind(r.Kind),
+			AccountID:      r.AccountID,
+		},
+		Actions: models.RuleActions{
+			CategoryID: r.CategoryID,
+			TagIDs:     r.TagIDs,
+			SetNote:    r.SetNote,
+		},
+		CreatedAt: r.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: r.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toDBRule(userID string, r models.Rule) dbmodel.Rule {
+	createdAt, err := time.Parse(time.RFC3339, r.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	updatedAt, err := time.Parse(time.RFC3339, r.UpdatedAt)
+	if err != nil {
+		updatedAt = createdAt
+	}
+	return dbmodel.Rule{
+		ID:             r.ID,
+		UserID:         userID,
+		Name:           r.Name,
+		Priority:       r.Priority,
+		Paused:         r.Paused,
+		NoteContains:   r.Conditions.NoteContains,
+		NoteRegex:      r.Conditions.NoteRegex,
+		MinAmountCents: r.Conditions.MinAmountCents,
+		MaxAmountCents: r.Conditions.MaxAmountCents,
+		Kind:           string(r.Conditions.Kind),
+		AccountID:      r.Conditions.AccountID,
+		CategoryID:     r.Actions.CategoryID,
+		TagIDs:         nonNilStrings(r.Actions.TagIDs),
+		SetNote:        r.Actions.SetNote,
+		CreatedAt:      createdAt.UTC(),
+		UpdatedAt:      updatedAt.UTC(),
+	}
+}
+
+// nonNilStrings stores an empty list as [] rather than null.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
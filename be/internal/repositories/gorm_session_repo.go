@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+)
+
+type GormSessionRepo struct {
+	db *gorm.DB
+}
+
+func NewGormSessionRepo(db *gorm.DB) *GormSessionRepo {
+	return &GormSessionRepo{db: db}
+}
+
+var _ SessionRepository = (*GormSessionRepo)(nil)
+
+func (r *GormSessionRepo) Create(ctx context.Context, s Session) error {
+	row := dbmodel.Session{
+		TokenHash: s.TokenHash,
+		UserID:    s.UserID,
+		ExpiresAt: s.ExpiresAt.UTC(),
+	}
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		if isForeignKeyViolation(err) {
+			return errs.ErrValidation
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *GormSessionRepo) Get(ctx context.Context, tokenHash string) (Session, error) {
+	var row dbmodel.Session
+	if err := conn(ctx, r.db).First(&row, "token_hash = ?", tokenHash).Error; err != nil {
+		if isNotFound(err) {
+			return Session{}, errs.ErrNotFound
+		}
+		return Session{}, err
+	}
+	return Session{TokenHash: row.TokenHash, UserID: row.UserID, ExpiresAt: row.ExpiresAt}, nil
+}
+
+func (r *GormSessionRepo) Delete(ctx context.Context, tokenHash string) error {
+	return conn(ctx, r.db).Delete(&dbmodel.Session{}, "token_hash = ?", tokenHash).Error
+}
+
+func (r *GormSessionRepo) DeleteExpired(ctx context.Context, now time.Time) error {
+	return conn(ctx, r.db).Delete(&dbmodel.Session{}, "expires_at <= ?", now.UTC()).Error
+}
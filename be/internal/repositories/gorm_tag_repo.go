@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormTagRepo struct {
+	db *gorm.DB
+}
+
+func NewGormTagRepo(db *gorm.DB) *GormTagRepo {
+	return &GormTagRepo{db: db}
+}
+
+var _ TagRepository = (*GormTagRepo)(nil)
+
+func (r *GormTagRepo) List(ctx context.Context) ([]models.Tag, error) {
+	var rows []dbmodel.Tag
+	if err := owned(ctx, r.db).Order("name asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]models.Tag, 0, len(rows))
+	for _, t := range rows {
+		out = append(out, toAPITag(t))
+	}
+	return out, nil
+}
+
+func (r *GormTagRepo) Get(ctx context.Context, id string) (models.Tag, error) {
+	var row dbmodel.Tag
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
+		if isNotFound(err) {
+			return models.Tag{}, errs.ErrNotFound
+		}
+		return models.Tag{}, err
+	}
+	return toAPITag(row), nil
+}
+
+func (r *GormTagRepo) Create(ctx context.Context, t models.Tag) (models.Tag, error) {
+	row := toDBTag(auth.UserID(ctx), t)
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return models.Tag{}, errs.ErrConflict
+		}
+		return models.Tag{}, err
+	}
+	return r.Get(ctx, t.ID)
+}
+
+func (r *GormTagRepo) Update(ctx context.Context, id string, patch TagPatch) (models.Tag, error) {
+	updates := map[string]any{}
+	if patch.Name != nil {
+		updates["name"] = *patch.Name
+	}
+	if patch.UpdatedAt != nil {
+		if t, err := time.Parse(time.RFC3339, *patch.UpdatedAt); err == nil {
+			updates["updated_at"] = t
+		}
+	}
+	if len(updates) == 0 {
+		return r.Get(ctx, id)
+	}
+	tx := owned(ctx, r.db).Model(&dbmodel.Tag{}).Where("id = ?", id).Updates(updates)
+	if tx.Error != nil {
+		if isUniqueViolation(tx.Error) {
+			return models.Tag{}, errs.ErrConflict
+		}
+		return models.Tag{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.Tag{}, errs.ErrNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+// Delete removes the tag from every transaction carrying it.
+func (r *GormTagRepo) Delete(ctx context.Context, id string) error {
+	tx := owned(ctx, r.db).Delete(&dbmodel.Tag{}, "id = ?", id)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return errs.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteAll removes the user's tags. It is meant to run inside a Transactor.
+func (r *GormTagRepo) DeleteAll(ctx context.Context) error {
+	return owned(ctx, r.db).Delete(&dbmodel.Tag{}).Error
+}
+
+// CreateMany inserts items in batches, failing on the first bad row.
+func (r *GormTagRepo) CreateMany(ctx context.Context, items []models.Tag) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows := make([]dbmodel.Tag, 0, len(items))
+	for _, it := range items {
+		rows = append(rows, toDBTag(auth.UserID(ctx), it))
+	}
+	if err := conn(ctx, r.db).CreateInBatches(&rows, 500).Error; err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func toAPITag(t dbmodel.Tag) models.Tag {
+	return models.Tag{
+		ID:        t.ID,
+		Name:      t.Name,
+		CreatedAt: t.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: t.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toDBTag(userID string, t models.Tag) dbmodel.Tag {
+	createdAt, err := time.Parse(time.RFC3339, t.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	updatedAt, err := time.Parse(time.RFC3339, t.UpdatedAt)
+	if err != nil {
+		updatedAt = createdAt
+	}
+	return dbmodel.Tag{
+		ID:        t.ID,
+		UserID:    userID,
+		Name:      t.Name,
+		CreatedAt: createdAt.UTC(),
+		UpdatedAt: updatedAt.UTC(),
+	}
+}
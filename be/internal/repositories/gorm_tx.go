@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/auth"
+)
+
+type txKey struct{}
+
+// GormTransactor runs work in one database transaction. Repositories pick the
+// transaction up from the context, so services can compose several repositories atomically.
+type GormTransactor struct {
+	db *gorm.DB
+}
+
+func NewGormTransactor(db *gorm.DB) *GormTransactor {
+	return &GormTransactor{db: db}
+}
+
+var _ Transactor = (*GormTransactor)(nil)
+
+// WithinTx commits if fn returns nil and rolls back otherwise. Nested calls join
+// the outer transaction.
+func (t *GormTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Savepoint runs fn in a savepoint of the transaction bound to ctx, or in a
+// transaction of its own when there is none. Postgres aborts a whole
+// transaction on a failed statement; rolling back to the savepoint undoes that.
+func (t *GormTransactor) Savepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	if !ok {
+		return t.WithinTx(ctx, fn)
+	}
+	return tx.WithContext(ctx).Transaction(func(sp *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, sp))
+	})
+}
+
+// conn returns the transaction bound to ctx, falling back to db.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// owned is conn scoped to rows belonging to the user bound to ctx.
+func owned(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return conn(ctx, db).Where("user_id = ?", auth.UserID(ctx))
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/errs"
+)
+
+// applyTxnFilter translates a TxnFilter into WHERE clauses. Dates are
+// YYYY-MM-DD text, so range comparisons use the date index directly.
+func applyTxnFilter(db *gorm.DB, f TxnFilter) *gorm.DB {
+	if f.From != "" {
+		db = db.Where("date >= ?", f.From)
+	}
+	if f.To != "" {
+		db = db.Where("date <= ?", f.To)
+	}
+	if f.Kind != "" {
+		db = db.Where("kind = ?", string(f.Kind))
+	}
+	if f.CategoryID != "" {
+		db = db.Where("(category_id = ? OR id IN (SELECT txn_id FROM txn_splits WHERE category_id = ?))", f.CategoryID, f.CategoryID)
+	}
+	if f.AccountID != "" {
+		db = db.Where("account_id = ?", f.AccountID)
+	}
+	if f.PayeeID != "" {
+		db = db.Where("payee_id = ?", f.PayeeID)
+	}
+	if f.MinAmountCents != nil {
+		db = db.Where("amount_cents >= ?", *f.MinAmountCents)
+	}
+	if f.MaxAmountCents != nil {
+		db = db.Where("amount_cents <= ?", *f.MaxAmountCents)
+	}
+	if f.TagID != "" {
+		db = db.Where("id IN (SELECT txn_id FROM txn_tags WHERE tag_id = ?)", f.TagID)
+	}
+	if f.NoteContains != "" {
+		db = db.Where(`LOWER(note) LIKE ? ESCAPE '\'`, "%"+escapeLike(strings.ToLower(f.NoteContains))+"%")
+	}
+	return db
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// sortColumn returns the column a TxnSort orders by and whether it is descending.
+// Ties are always broken by id in the same direction so pages are stable.
+func (s TxnSort) sortColumn() (string, bool) {
+	switch s {
+	case TxnSortDateAsc:
+		return "date", false
+	case TxnSortAmountDesc:
+		return "amount_cents", true
+	case TxnSortAmountAsc:
+		return "amount_cents", false
+	default:
+		return "date", true
+	}
+}
+
+type txnCursor struct {
+	Sort  TxnSort `json:"s"`
+	Value string  `json:"v"`
+	ID    string  `json:"id"`
+}
+
+func encodeTxnCursor(c txnCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeTxnCursor(raw string, sort TxnSort) (txnCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return txnCursor{}, errs.ErrValidation
+	}
+	var c txnCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort || c.ID == "" {
+		return txnCursor{}, errs.ErrValidation
+	}
+	return c, nil
+}
+
+// applyTxnCursor restricts the query to rows strictly after the cursor position.
+func applyTxnCursor(db *gorm.DB, sort TxnSort, raw string) (*gorm.DB, error) {
+	c, err := decodeTxnCursor(raw, sort)
+	if err != nil {
+		return nil, err
+	}
+	col, desc := sort.sortColumn()
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	var v any = c.Value
+	if col == "amount_cents" {
+		n, err := strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			return nil, errs.ErrValidation
+		}
+		v = n
+	}
+	cond := fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", col, op, col, op)
+	return db.Where(cond, v, v, c.ID), nil
+}
@@ -2,10 +2,13 @@ package repositories
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"personal-budgeting/be/internal/auth"
 	"personal-budgeting/be/internal/dbmodel"
 	"personal-budgeting/be/internal/errs"
 	"personal-budgeting/be/internal/models"
@@ -23,33 +26,112 @@ var _ TxnRepository = (*GormTxnRepo)(nil)
 
 func (r *GormTxnRepo) List(ctx context.Context) ([]models.Txn, error) {
 	var rows []dbmodel.Transaction
-	if err := r.db.WithContext(ctx).Order("created_at asc").Find(&rows).Error; err != nil {
+	if err := owned(ctx, r.db).Order("created_at asc").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]models.Txn, 0, len(rows))
 	for _, t := range rows {
 		out = append(out, toAPITxn(t))
 	}
+	if err := r.attachLinks(ctx, out); err != nil {
+		return nil, err
+	}
 	return out, nil
 }
 
+func (r *GormTxnRepo) Query(ctx context.Context, q TxnQuery) (TxnPage, error) {
+	if !q.Sort.Valid() {
+		q.Sort = TxnSortDateDesc
+	}
+	db := applyTxnFilter(owned(ctx, r.db), q.TxnFilter)
+	if q.Cursor != "" {
+		var err error
+		db, err = applyTxnCursor(db, q.Sort, q.Cursor)
+		if err != nil {
+			return TxnPage{}, err
+		}
+	}
+
+	col, desc := q.Sort.sortColumn()
+	dir := " asc"
+	if desc {
+		dir = " desc"
+	}
+	var rows []dbmodel.Transaction
+	// Fetch one extra row to know whether another page exists.
+	if err := db.Order(col + dir).Order("id" + dir).Limit(q.Limit + 1).Find(&rows).Error; err != nil {
+		return TxnPage{}, err
+	}
+
+	page := TxnPage{Items: make([]models.Txn, 0, len(rows))}
+	if len(rows) > q.Limit {
+		rows = rows[:q.Limit]
+		last := rows[len(rows)-1]
+		v := last.Date
+		if col == "amount_cents" {
+			v = strconv.FormatInt(last.AmountCents, 10)
+		}
+		page.NextCursor = encodeTxnCursor(txnCursor{Sort: q.Sort, Value: v, ID: last.ID})
+	}
+	for _, t := range rows {
+		page.Items = append(page.Items, toAPITxn(t))
+	}
+	if err := r.attachLinks(ctx, page.Items); err != nil {
+		return TxnPage{}, err
+	}
+	return page, nil
+}
+
+func (r *GormTxnRepo) Each(ctx context.Context, f TxnFilter, fn func(models.Txn) error) error {
+	rows, err := applyTxnFilter(owned(ctx, r.db).Model(&dbmodel.Transaction{}), f).
+		Order("date asc").Order("id asc").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var row dbmodel.Transaction
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(toAPITxn(row)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (r *GormTxnRepo) Get(ctx context.Context, id string) (models.Txn, error) {
 	var row dbmodel.Transaction
-	if err := r.db.WithContext(ctx).First(&row, "id = ?", id).Error; err != nil {
+	if err := owned(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
 		if isNotFound(err) {
 			return models.Txn{}, errs.ErrNotFound
 		}
 		return models.Txn{}, err
 	}
-	return toAPITxn(row), nil
+	out := []models.Txn{toAPITxn(row)}
+	if err := r.attachLinks(ctx, out); err != nil {
+		return models.Txn{}, err
+	}
+	return out[0], nil
 }
 
 func (r *GormTxnRepo) Create(ctx context.Context, t models.Txn) (models.Txn, error) {
-	row, err := toDBTxn(t)
+	row, err := toDBTxn(auth.UserID(ctx), t)
 	if err != nil {
 		return models.Txn{}, err
 	}
-	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
+	err = NewGormTransactor(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+			return err
+		}
+		if err := r.setSplits(ctx, t.ID, t.Splits); err != nil {
+			return err
+		}
+		return r.setTags(ctx, t.ID, t.TagIDs)
+	})
+	if err != nil {
 		if isUniqueViolation(err) {
 			return models.Txn{}, errs.ErrConflict
 		}
@@ -72,9 +154,18 @@ func (r *GormTxnRepo) Update(ctx context.Context, id string, patch TxnPatch) (mo
 	if patch.CategoryID != nil {
 		updates["category_id"] = *patch.CategoryID
 	}
+	if patch.AccountID != nil {
+		updates["account_id"] = nullableString(*patch.AccountID)
+	}
+	if patch.PayeeID != nil {
+		updates["payee_id"] = nullableString(*patch.PayeeID)
+	}
 	if patch.AmountCents != nil {
 		updates["amount_cents"] = *patch.AmountCents
 	}
+	if patch.Currency != nil {
+		updates["currency"] = *patch.Currency
+	}
 	if patch.Note != nil {
 		updates["note"] = *patch.Note
 	}
@@ -83,24 +174,58 @@ func (r *GormTxnRepo) Update(ctx context.Context, id string, patch TxnPatch) (mo
 			updates["updated_at"] = t
 		}
 	}
-	if len(updates) == 0 {
-		return r.Get(ctx, id)
+	if len(updates) == 0 && patch.TagIDs == nil && patch.Splits == nil {
+		t, err := r.Get(ctx, id)
+		if err == nil && patch.IfVersion != 0 && t.Version != patch.IfVersion {
+			return models.Txn{}, errs.ErrPreconditionFailed
+		}
+		return t, err
 	}
-	tx := r.db.WithContext(ctx).Model(&dbmodel.Transaction{}).Where("id = ?", id).Updates(updates)
-	if tx.Error != nil {
-		if isForeignKeyViolation(tx.Error) {
+	// Replacing only tags or lines still counts as a new version.
+	updates["version"] = gorm.Expr("version + 1")
+	err := NewGormTransactor(r.db).WithinTx(ctx, func(ctx context.Context) error {
+		q := owned(ctx, r.db).Model(&dbmodel.Transaction{}).Where("id = ?", id)
+		if patch.IfVersion != 0 {
+			q = q.Where("version = ?", patch.IfVersion)
+		}
+		tx := q.Updates(updates)
+		if tx.Error != nil {
+			return tx.Error
+		}
+		if tx.RowsAffected == 0 {
+			// Missing, or no longer at IfVersion.
+			if _, err := r.Get(ctx, id); err != nil {
+				return err
+			}
+			return errs.ErrPreconditionFailed
+		}
+		if patch.Splits != nil {
+			if err := conn(ctx, r.db).Delete(&dbmodel.TxnSplit{}, "txn_id = ?", id).Error; err != nil {
+				return err
+			}
+			if err := r.setSplits(ctx, id, *patch.Splits); err != nil {
+				return err
+			}
+		}
+		if patch.TagIDs == nil {
+			return nil
+		}
+		if err := conn(ctx, r.db).Delete(&dbmodel.TxnTag{}, "txn_id = ?", id).Error; err != nil {
+			return err
+		}
+		return r.setTags(ctx, id, *patch.TagIDs)
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
 			return models.Txn{}, errs.ErrValidation
 		}
-		return models.Txn{}, tx.Error
-	}
-	if tx.RowsAffected == 0 {
-		return models.Txn{}, errs.ErrNotFound
+		return models.Txn{}, err
 	}
 	return r.Get(ctx, id)
 }
 
 func (r *GormTxnRepo) Delete(ctx context.Context, id string) error {
-	tx := r.db.WithContext(ctx).Delete(&dbmodel.Transaction{}, "id = ?", id)
+	tx := owned(ctx, r.db).Delete(&dbmodel.Transaction{}, "id = ?", id)
 	if tx.Error != nil {
 		return tx.Error
 	}
@@ -112,41 +237,250 @@ func (r *GormTxnRepo) Delete(ctx context.Context, id string) error {
 
 func (r *GormTxnRepo) CountByCategory(ctx context.Context, categoryID string) (int, error) {
 	var n int64
-	if err := r.db.WithContext(ctx).Model(&dbmodel.Transaction{}).Where("category_id = ?", categoryID).Count(&n).Error; err != nil {
+	err := owned(ctx, r.db).Model(&dbmodel.Transaction{}).
+		Where("category_id = ? OR id IN (SELECT txn_id FROM txn_splits WHERE category_id = ?)", categoryID, categoryID).
+		Count(&n).Error
+	if err != nil {
 		return 0, err
 	}
 	return int(n), nil
 }
 
-func (r *GormTxnRepo) Reset() {
-	_ = r.db.WithContext(context.Background()).Exec("DELETE FROM transactions").Error
+func (r *GormTxnRepo) CountByAccount(ctx context.Context, accountID string) (int, error) {
+	var n int64
+	if err := owned(ctx, r.db).Unscoped().Model(&dbmodel.Transaction{}).Where("account_id = ?", accountID).Count(&n).Error; err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (r *GormTxnRepo) ReassignPayee(ctx context.Context, from []string, to string) error {
+	if len(from) == 0 {
+		return nil
+	}
+	return owned(ctx, r.db).Unscoped().Model(&dbmodel.Transaction{}).Where("payee_id IN ?", from).
+		Updates(map[string]any{"payee_id": nullableString(to), "version": gorm.Expr("version + 1")}).Error
 }
 
-func (r *GormTxnRepo) BulkUpsert(items []models.Txn) {
-	ctx := context.Background()
-	for _, t := range items {
-		row, err := toDBTxn(t)
+func (r *GormTxnRepo) ListByTransfer(ctx context.Context, transferID string) ([]models.Txn, error) {
+	var rows []dbmodel.Transaction
+	// "out" sorts after "in", hence descending.
+	err := owned(ctx, r.db).Where("transfer_id = ?", transferID).Order("transfer_direction desc").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Txn, 0, len(rows))
+	for _, t := range rows {
+		out = append(out, toAPITxn(t))
+	}
+	if err := r.attachLinks(ctx, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *GormTxnRepo) ExistingExternalIDs(ctx context.Context, externalIDs []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	if len(externalIDs) == 0 {
+		return out, nil
+	}
+	var found []string
+	err := owned(ctx, r.db).Unscoped().Model(&dbmodel.Transaction{}).
+		Where("external_id IN ?", externalIDs).
+		Pluck("external_id", &found).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range found {
+		out[id] = true
+	}
+	return out, nil
+}
+
+func (r *GormTxnRepo) ListDeleted(ctx context.Context) ([]models.Txn, error) {
+	var rows []dbmodel.Transaction
+	err := owned(ctx, r.db).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at desc, id asc").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]models.Txn, 0, len(rows))
+	for _, t := range rows {
+		out = append(out, toAPITxn(t))
+	}
+	if err := r.attachLinks(ctx, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *GormTxnRepo) Restore(ctx context.Context, id string) (models.Txn, error) {
+	tx := owned(ctx, r.db).Unscoped().Model(&dbmodel.Transaction{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if tx.Error != nil {
+		return models.Txn{}, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return models.Txn{}, errs.ErrNotFound
+	}
+	return r.Get(ctx, id)
+}
+
+// PurgeDeleted hard-deletes transactions trashed before the cutoff; their tag
+// links and split lines go with them.
+func (r *GormTxnRepo) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	tx := owned(ctx, r.db).Unscoped().Where("deleted_at < ?", before).Delete(&dbmodel.Transaction{})
+	return int(tx.RowsAffected), tx.Error
+}
+
+func (r *GormTxnRepo) DeletedOwners(ctx context.Context, before time.Time) ([]string, error) {
+	var out []string
+	err := conn(ctx, r.db).Unscoped().Model(&dbmodel.Transaction{}).
+		Where("deleted_at < ?", before).
+		Distinct().Order("user_id").Pluck("user_id", &out).Error
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeleteAll empties the transactions table, trash included. It is meant to run
+// inside a Transactor.
+func (r *GormTxnRepo) DeleteAll(ctx context.Context) error {
+	return owned(ctx, r.db).Unscoped().Delete(&dbmodel.Transaction{}).Error
+}
+
+// CreateMany inserts items in batches, failing on the first bad row.
+func (r *GormTxnRepo) CreateMany(ctx context.Context, items []models.Txn) error {
+	if len(items) == 0 {
+		return nil
+	}
+	rows := make([]dbmodel.Transaction, 0, len(items))
+	var links []dbmodel.TxnTag
+	var splits []dbmodel.TxnSplit
+	for _, it := range items {
+		row, err := toDBTxn(auth.UserID(ctx), it)
 		if err != nil {
-			continue
+			return err
+		}
+		rows = append(rows, row)
+		for _, tagID := range it.TagIDs {
+			links = append(links, dbmodel.TxnTag{TxnID: it.ID, TagID: tagID})
 		}
-		_ = r.db.WithContext(ctx).Save(&row).Error
+		splits = append(splits, toDBSplits(it.ID, it.Splits)...)
 	}
+	err := conn(ctx, r.db).CreateInBatches(&rows, 500).Error
+	if err == nil && len(splits) > 0 {
+		err = conn(ctx, r.db).Omit(clause.Associations).CreateInBatches(&splits, 500).Error
+	}
+	if err == nil && len(links) > 0 {
+		err = conn(ctx, r.db).Omit(clause.Associations).CreateInBatches(&links, 500).Error
+	}
+	if err != nil {
+		if isUniqueViolation(err) {
+			return errs.ErrConflict
+		}
+		if isForeignKeyViolation(err) {
+			return errs.ErrValidation
+		}
+		return err
+	}
+	return nil
+}
+
+// setTags links a new or just-cleared transaction to tagIDs.
+func (r *GormTxnRepo) setTags(ctx context.Context, txnID string, tagIDs []string) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	links := make([]dbmodel.TxnTag, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		links = append(links, dbmodel.TxnTag{TxnID: txnID, TagID: tagID})
+	}
+	return conn(ctx, r.db).Omit(clause.Associations).Create(&links).Error
+}
+
+// setSplits stores the lines of a new or just-cleared transaction.
+func (r *GormTxnRepo) setSplits(ctx context.Context, txnID string, splits []models.TxnSplit) error {
+	if len(splits) == 0 {
+		return nil
+	}
+	rows := toDBSplits(txnID, splits)
+	return conn(ctx, r.db).Omit(clause.Associations).Create(&rows).Error
+}
+
+// attachLinks fills in TagIDs, sorted, and Splits, in order, for txns in place.
+func (r *GormTxnRepo) attachLinks(ctx context.Context, txns []models.Txn) error {
+	byID := make(map[string]int, len(txns))
+	ids := make([]string, 0, len(txns))
+	for i, t := range txns {
+		byID[t.ID] = i
+		ids = append(ids, t.ID)
+	}
+	for start := 0; start < len(ids); start += 500 {
+		end := min(start+500, len(ids))
+		var links []dbmodel.TxnTag
+		err := conn(ctx, r.db).Where("txn_id IN ?", ids[start:end]).Order("txn_id, tag_id").Find(&links).Error
+		if err != nil {
+			return err
+		}
+		for _, l := range links {
+			i := byID[l.TxnID]
+			txns[i].TagIDs = append(txns[i].TagIDs, l.TagID)
+		}
+		var splits []dbmodel.TxnSplit
+		err = conn(ctx, r.db).Where("txn_id IN ?", ids[start:end]).Order("txn_id, position").Find(&splits).Error
+		if err != nil {
+			return err
+		}
+		for _, sp := range splits {
+			i := byID[sp.TxnID]
+			txns[i].Splits = append(txns[i].Splits, models.TxnSplit{
+				CategoryID:  sp.CategoryID,
+				AmountCents: sp.AmountCents,
+				Note:        sp.Note,
+			})
+		}
+	}
+	return nil
+}
+
+func toDBSplits(txnID string, splits []models.TxnSplit) []dbmodel.TxnSplit {
+	out := make([]dbmodel.TxnSplit, 0, len(splits))
+	for i, sp := range splits {
+		out = append(out, dbmodel.TxnSplit{
+			TxnID:       txnID,
+			Position:    i,
+			CategoryID:  sp.CategoryID,
+			AmountCents: sp.AmountCents,
+			Note:        sp.Note,
+		})
+	}
+	return out
 }
 
 func toAPITxn(t dbmodel.Transaction) models.Txn {
 	return models.Txn{
-		ID:          t.ID,
-		Kind:        models.TransactionKind(t.Kind),
-		Date:        t.Date,
-		CategoryID:  t.CategoryID,
-		AmountCents: t.AmountCents,
-		Note:        t.Note,
-		CreatedAt:   t.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt:   t.UpdatedAt.UTC().Format(time.RFC3339),
+		ID:                t.ID,
+		Kind:              models.TransactionKind(t.Kind),
+		Date:              t.Date,
+		CategoryID:        derefString(t.CategoryID),
+		AccountID:         derefString(t.AccountID),
+		PayeeID:           derefString(t.PayeeID),
+		AmountCents:       t.AmountCents,
+		Currency:          t.Currency,
+		Note:              t.Note,
+		ExternalID:        derefString(t.ExternalID),
+		TransferID:        derefString(t.TransferID),
+		TransferDirection: models.TransferDirection(t.TransferDir),
+		Version:           t.Version,
+		CreatedAt:         t.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:         t.UpdatedAt.UTC().Format(time.RFC3339),
+		DeletedAt:         formatDeletedAt(t.DeletedAt),
 	}
 }
 
-func toDBTxn(t models.Txn) (dbmodel.Transaction, error) {
+func toDBTxn(userID string, t models.Txn) (dbmodel.Transaction, error) {
 	createdAt, err := time.Parse(time.RFC3339, t.CreatedAt)
 	if err != nil {
 		createdAt = time.Now().UTC()
@@ -157,14 +491,20 @@ func toDBTxn(t models.Txn) (dbmodel.Transaction, error) {
 	}
 	return dbmodel.Transaction{
 		ID:          t.ID,
+		UserID:      userID,
 		Kind:        string(t.Kind),
 		Date:        t.Date,
-		CategoryID:  t.CategoryID,
+		CategoryID:  nullableString(t.CategoryID),
+		AccountID:   nullableString(t.AccountID),
+		PayeeID:     nullableString(t.PayeeID),
 		AmountCents: t.AmountCents,
+		Currency:    models.CurrencyOrDefault(t.Currency),
 		Note:        t.Note,
+		ExternalID:  nullableString(t.ExternalID),
+		TransferID:  nullableString(t.TransferID),
+		TransferDir: string(t.TransferDirection),
+		Version:     versionOrDefault(t.Version),
 		CreatedAt:   createdAt.UTC(),
 		UpdatedAt:   updatedAt.UTC(),
 	}, nil
 }
-
-
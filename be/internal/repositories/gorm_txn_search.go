@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/models"
+)
+
+// searchCategoryName is the name of a transaction's category, "" for transfers.
+// A subquery rather than a join keeps applyTxnFilter's bare columns unambiguous.
+const searchCategoryName = "COALESCE((SELECT c.name FROM categories c WHERE c.id = transactions.category_id), '')"
+
+type txnSearchRow struct {
+	dbmodel.Transaction
+	CategoryName string
+	Rank         float64
+}
+
+// Search uses full-text search on Postgres: the 'simple' configuration (notes
+// are often not English) with prefix terms, backed by the GIN indexes from
+// migration 015. Other databases fall back to counting LIKE matches.
+func (r *GormTxnRepo) Search(ctx context.Context, q TxnSearch) ([]TxnSearchHit, error) {
+	if len(q.Terms) == 0 {
+		return []TxnSearchHit{}, nil
+	}
+	db := applyTxnFilter(owned(ctx, r.db).Model(&dbmodel.Transaction{}), q.TxnFilter)
+	if db.Dialector.Name() == "postgres" {
+		db = searchPostgres(db, q.Terms)
+	} else {
+		db = searchLike(db, q.Terms)
+	}
+	var rows []txnSearchRow
+	if err := db.Order("rank desc").Order("date desc").Order("id desc").Limit(q.Limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	txns := make([]models.Txn, 0, len(rows))
+	for _, row := range rows {
+		txns = append(txns, toAPITxn(row.Transaction))
+	}
+	if err := r.attachLinks(ctx, txns); err != nil {
+		return nil, err
+	}
+	out := make([]TxnSearchHit, 0, len(rows))
+	for i, row := range rows {
+		snippet, ok := searchSnippet(row.Note, q.Terms)
+		if byName, nameOK := searchSnippet(row.CategoryName, q.Terms); !ok && (nameOK || row.Note == "") {
+			snippet = byName
+		}
+		out = append(out, TxnSearchHit{Txn: txns[i], Rank: row.Rank, Snippet: snippet})
+	}
+	return out, nil
+}
+
+// searchPostgres matches any term in the note or the category name. Note
+// matches weigh more in the rank.
+func searchPostgres(db *gorm.DB, terms []string) *gorm.DB {
+	prefixes := make([]string, 0, len(terms))
+	for _, t := range terms {
+		prefixes = append(prefixes, t+":*")
+	}
+	tsq := strings.Join(prefixes, " | ")
+	return db.
+		Select(`transactions.*, `+searchCategoryName+` AS category_name,
+			ts_rank(setweight(to_tsvector('simple', note), 'A') || setweight(to_tsvector('simple', `+searchCategoryName+`), 'B'),
+				to_tsquery('simple', ?)) AS rank`, tsq).
+		Where(`(to_tsvector('simple', note) @@ to_tsquery('simple', ?)
+			OR category_id IN (SELECT c.id FROM categories c WHERE to_tsvector('simple', c.name) @@ to_tsquery('simple', ?)))`, tsq, tsq)
+}
+
+// searchLike ranks by the number of terms found anywhere in the note or the
+// category name.
+func searchLike(db *gorm.DB, terms []string) *gorm.DB {
+	var rank, match []string
+	var rankArgs, matchArgs []any
+	for _, t := range terms {
+		pattern := "%" + escapeLike(t) + "%"
+		cond := `(LOWER(note) LIKE ? ESCAPE '\' OR LOWER(` + searchCategoryName + `) LIKE ? ESCAPE '\')`
+		rank = append(rank, "CASE WHEN "+cond+" THEN 1 ELSE 0 END")
+		match = append(match, cond)
+		rankArgs = append(rankArgs, pattern, pattern)
+		matchArgs = append(matchArgs, pattern, pattern)
+	}
+	return db.
+		Select(`transactions.*, `+searchCategoryName+` AS category_name, (`+strings.Join(rank, " + ")+`) AS rank`, rankArgs...).
+		Where("("+strings.Join(match, " OR ")+")", matchArgs...)
+}
+
+// searchSnippetWords is how many words of context a snippet keeps around its
+// first match.
+const searchSnippetWords = 8
+
+// searchSnippet escapes text and highlights words starting with a term. Long
+// texts are cut to a window around the first match. ok is false when nothing
+// matched.
+func searchSnippet(text string, terms []string) (snippet string, ok bool) {
+	words := strings.Fields(text)
+	first := -1
+	marked := make([]string, len(words))
+	for i, w := range words {
+		marked[i] = html.EscapeString(w)
+		if matchesTerm(w, terms) {
+			marked[i] = markWord(w)
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	matched := first >= 0
+	start := max(first-searchSnippetWords/2, 0)
+	end := min(start+searchSnippetWords*2, len(marked))
+	out := strings.Join(marked[start:end], " ")
+	if start > 0 {
+		out = "… " + out
+	}
+	if end < len(marked) {
+		out += " …"
+	}
+	return out, matched
+}
+
+// matchesTerm reports whether a word, ignoring surrounding punctuation, starts
+// with one of the terms.
+func matchesTerm(word string, terms []string) bool {
+	w := strings.ToLower(strings.TrimFunc(word, isNotWordRune))
+	for _, t := range terms {
+		if strings.HasPrefix(w, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// markWord wraps the word in <mark>, leaving surrounding punctuation outside.
+func markWord(word string) string {
+	lead := len(word) - len(strings.TrimLeftFunc(word, isNotWordRune))
+	trail := len(strings.TrimRightFunc(word, isNotWordRune))
+	if trail < lead {
+		return html.EscapeString(word)
+	}
+	return html.EscapeString(word[:lead]) + "<mark>" + html.EscapeString(word[lead:trail]) + "</mark>" + html.EscapeString(word[trail:])
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"personal-budgeting/be/internal/dbmodel"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+)
+
+type GormUserRepo struct {
+	db *gorm.DB
+}
+
+func NewGormUserRepo(db *gorm.DB) *GormUserRepo {
+	return &GormUserRepo{db: db}
+}
+
+var _ UserRepository = (*GormUserRepo)(nil)
+
+func (r *GormUserRepo) Get(ctx context.Context, id string) (models.User, error) {
+	var row dbmodel.User
+	if err := conn(ctx, r.db).First(&row, "id = ?", id).Error; err != nil {
+		if isNotFound(err) {
+			return models.User{}, errs.ErrNotFound
+		}
+		return models.User{}, err
+	}
+	return toAPIUser(row), nil
+}
+
+func (r *GormUserRepo) GetByEmail(ctx context.Context, email string) (models.User, string, error) {
+	var row dbmodel.User
+	if err := conn(ctx, r.db).First(&row, "email = ?", email).Error; err != nil {
+		if isNotFound(err) {
+			return models.User{}, "", errs.ErrNotFound
+		}
+		return models.User{}, "", err
+	}
+	return toAPIUser(row), row.PasswordHash, nil
+}
+
+func (r *GormUserRepo) Create(ctx context.Context, u models.User, passwordHash string) (models.User, error) {
+	createdAt, err := time.Parse(time.RFC3339, u.CreatedAt)
+	if err != nil {
+		createdAt = time.Now().UTC()
+	}
+	row := dbmodel.User{
+		ID:           u.ID,
+		Email:        u.Email,
+		PasswordHash: passwordHash,
+		CreatedAt:    createdAt.UTC(),
+		UpdatedAt:    createdAt.UTC(),
+	}
+	if err := conn(ctx, r.db).Create(&row).Error; err != nil {
+		if isUniqueViolation(err) {
+			return models.User{}, errs.ErrConflict
+		}
+		return models.User{}, err
+	}
+	return toAPIUser(row), nil
+}
+
+func (r *GormUserRepo) Count(ctx context.Context) (int64, error) {
+	var n int64
+	if err := conn(ctx, r.db).Model(&dbmodel.User{}).Count(&n).Error; err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (r *GormUserRepo) ClaimUnowned(ctx context.Context, userID string) error {
+	for _, m := range []any{&dbmodel.Account{}, &dbmodel.Category{}, &dbmodel.Budget{}, &dbmodel.Transaction{}, &dbmodel.ExchangeRate{}, &dbmodel.RecurringRule{}, &dbmodel.BudgetTemplate{}, &dbmodel.Tag{}, &dbmodel.Payee{}, &dbmodel.Attachment{}, &dbmodel.Rule{}} {
+		err := conn(ctx, r.db).Unscoped().Model(m).Where("user_id = ?", "").Update("user_id", userID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toAPIUser(u dbmodel.User) models.User {
+	return models.User{
+		ID:        u.ID,
+		Email:     u.Email,
+		CreatedAt: u.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
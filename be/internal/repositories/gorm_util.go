@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
@@ -27,4 +28,33 @@ func isForeignKeyViolation(err error) bool {
 	return false
 }
 
+// nullableString maps "" to NULL for optional columns (e.g. unique-but-optional keys).
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
 
+// versionOrDefault starts rows that come without a version at 1.
+func versionOrDefault(v int64) int64 {
+	if v < 1 {
+		return 1
+	}
+	return v
+}
+
+// formatDeletedAt renders when a trashed row was deleted; live rows give "".
+func formatDeletedAt(d gorm.DeletedAt) string {
+	if !d.Valid {
+		return ""
+	}
+	return d.Time.UTC().Format(time.RFC3339)
+}
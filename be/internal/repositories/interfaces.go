@@ -2,46 +2,409 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"personal-budgeting/be/internal/models"
 )
 
+// Transactor runs fn in a single database transaction. Repository calls made with
+// the ctx handed to fn take part in it; returning an error rolls everything back.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// Savepoint is WithinTx, except that inside an outer transaction a failing
+	// fn rolls back only its own writes and leaves the outer one usable.
+	Savepoint(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type CategoryRepository interface {
 	List(ctx context.Context) ([]models.Category, error)
 	Get(ctx context.Context, id string) (models.Category, error)
 	Create(ctx context.Context, c models.Category) (models.Category, error)
+	// Update bumps the category's version; see CategoryPatch.IfVersion.
 	Update(ctx context.Context, id string, patch CategoryPatch) (models.Category, error)
+	// Delete moves the category to the trash.
 	Delete(ctx context.Context, id string) error
+	ListDeleted(ctx context.Context) ([]models.Category, error)
+	// Restore takes the category out of the trash; ErrNotFound if it is not there.
+	Restore(ctx context.Context, id string) (models.Category, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	// DeletedOwners lists users with categories trashed before the cutoff. Like
+	// UserRepository it is not scoped by the user in ctx.
+	DeletedOwners(ctx context.Context, before time.Time) ([]string, error)
+	DeleteAll(ctx context.Context) error
+	CreateMany(ctx context.Context, items []models.Category) error
 }
 
 type CategoryPatch struct {
-	Name        *string
-	Description *string
-	UpdatedAt   *string
+	Name           *string
+	Description    *string
+	ParentID       *string // "" makes the category top-level
+	RolloverPolicy *models.RolloverPolicy
+	UpdatedAt      *string
+	// IfVersion, when set, makes Update fail with errs.ErrPreconditionFailed
+	// unless the category is still at that version.
+	IfVersion int64
+}
+
+type AccountRepository interface {
+	// List returns active accounts, plus archived ones when includeArchived is set.
+	List(ctx context.Context, includeArchived bool) ([]models.Account, error)
+	Get(ctx context.Context, id string) (models.Account, error)
+	Create(ctx context.Context, a models.Account) (models.Account, error)
+	Update(ctx context.Context, id string, patch AccountPatch) (models.Account, error)
+	Delete(ctx context.Context, id string) error
+	// NetChange sums the signed amounts of the account's transactions dated before
+	// the given YYYY-MM-DD key (inflows positive, outflows negative).
+	NetChange(ctx context.Context, accountID string, before string) (int64, error)
+	DeleteAll(ctx context.Context) error
+	CreateMany(ctx context.Context, items []models.Account) error
+}
+
+type AccountPatch struct {
+	Name                *string
+	Type                *models.AccountType
+	OpeningBalanceCents *int64
+	Currency            *string
+	Archived            *bool
+	UpdatedAt           *string
 }
 
 type BudgetRepository interface {
 	List(ctx context.Context) ([]models.Budget, error)
 	Get(ctx context.Context, id string) (models.Budget, error)
 	Upsert(ctx context.Context, b models.Budget) (models.Budget, error)
+	// Delete moves the budget to the trash.
 	Delete(ctx context.Context, id string) error
 	FindByMonthCategory(ctx context.Context, month string, categoryID string) (models.Budget, bool, error)
+	ListByMonth(ctx context.Context, month string) ([]models.Budget, error)
+	ListDeleted(ctx context.Context) ([]models.Budget, error)
+	Restore(ctx context.Context, id string) (models.Budget, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	DeletedOwners(ctx context.Context, before time.Time) ([]string, error)
+	DeleteAll(ctx context.Context) error
+	CreateMany(ctx context.Context, items []models.Budget) error
+
+	ListTemplates(ctx context.Context) ([]models.BudgetTemplate, error)
+	GetTemplate(ctx context.Context, id string) (models.BudgetTemplate, error)
+	CreateTemplate(ctx context.Context, t models.BudgetTemplate) (models.BudgetTemplate, error)
+	UpdateTemplate(ctx context.Context, id string, patch BudgetTemplatePatch) (models.BudgetTemplate, error)
+	DeleteTemplate(ctx context.Context, id string) error
+	CountTemplatesByCategory(ctx context.Context, categoryID string) (int, error)
+	// PruneTemplateLines drops template lines whose category no longer exists.
+	PruneTemplateLines(ctx context.Context) error
+}
+
+// BudgetTemplatePatch replaces the template's lines wholesale when Lines is set.
+type BudgetTemplatePatch struct {
+	Name      *string
+	AutoApply *bool
+	Lines     *[]models.BudgetTemplateLine
+	UpdatedAt *string
+}
+
+type TagRepository interface {
+	List(ctx context.Context) ([]models.Tag, error)
+	Get(ctx context.Context, id string) (models.Tag, error)
+	Create(ctx context.Context, t models.Tag) (models.Tag, error)
+	Update(ctx context.Context, id string, patch TagPatch) (models.Tag, error)
+	Delete(ctx context.Context, id string) error
+	DeleteAll(ctx context.Context) error
+	CreateMany(ctx context.Context, items []models.Tag) error
+}
+
+type TagPatch struct {
+	Name      *string
+	UpdatedAt *string
+}
+
+type PayeeRepository interface {
+	List(ctx context.Context) ([]models.Payee, error)
+	Get(ctx context.Context, id string) (models.Payee, error)
+	Create(ctx context.Context, p models.Payee) (models.Payee, error)
+	Update(ctx context.Context, id string, patch PayeePatch) (models.Payee, error)
+	// Delete clears the payee from its transactions.
+	Delete(ctx context.Context, id string) error
+	DeleteAll(ctx context.Context) error
+	CreateMany(ctx context.Context, items []models.Payee) error
 }
 
+type PayeePatch struct {
+	Name      *string
+	Aliases   *[]string // replaces every alias
+	UpdatedAt *string
+}
+
+type AttachmentRepository interface {
+	ListByTxn(ctx context.Context, txnID string) ([]models.Attachment, error)
+	Get(ctx context.Context, id string) (models.Attachment, error)
+	Create(ctx context.Context, a models.Attachment) (models.Attachment, error)
+	Delete(ctx context.Context, id string) error
+	// ListOrphans returns attachments whose transaction no longer exists.
+	ListOrphans(ctx context.Context) ([]models.Attachment, error)
+	DeleteMany(ctx context.Context, ids []string) error
+}
+
+// TxnRepository loads TagIDs and Splits with every transaction except those
+// streamed by Each.
 type TxnRepository interface {
 	List(ctx context.Context) ([]models.Txn, error)
+	Query(ctx context.Context, q TxnQuery) (TxnPage, error)
+	// Each streams matching transactions ordered by date then id, one row at a time.
+	Each(ctx context.Context, f TxnFilter, fn func(models.Txn) error) error
 	Get(ctx context.Context, id string) (models.Txn, error)
 	Create(ctx context.Context, t models.Txn) (models.Txn, error)
+	// Update bumps the transaction's version; see TxnPatch.IfVersion.
 	Update(ctx context.Context, id string, patch TxnPatch) (models.Txn, error)
+	// Delete moves the transaction to the trash.
 	Delete(ctx context.Context, id string) error
+	// CountByCategory counts transactions using the category, split lines included.
 	CountByCategory(ctx context.Context, categoryID string) (int, error)
+	// CountByAccount counts the account's transactions, trashed ones included.
+	CountByAccount(ctx context.Context, accountID string) (int, error)
+	// ReassignPayee moves transactions of the from payees, trashed ones
+	// included, to payee to; "" leaves them without a payee.
+	ReassignPayee(ctx context.Context, from []string, to string) error
+	// ListByTransfer returns the legs of a transfer, outgoing leg first.
+	ListByTransfer(ctx context.Context, transferID string) ([]models.Txn, error)
+	// Search ranks matching transactions by how well their note and category
+	// name match the terms, best first.
+	Search(ctx context.Context, q TxnSearch) ([]TxnSearchHit, error)
+	// ExistingExternalIDs reports which of the given external IDs are already
+	// stored, in the trash or not.
+	ExistingExternalIDs(ctx context.Context, externalIDs []string) (map[string]bool, error)
+	ListDeleted(ctx context.Context) ([]models.Txn, error)
+	Restore(ctx context.Context, id string) (models.Txn, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	DeletedOwners(ctx context.Context, before time.Time) ([]string, error)
+	DeleteAll(ctx context.Context) error
+	CreateMany(ctx context.Context, items []models.Txn) error
 }
 
 type TxnPatch struct {
 	Kind        *models.TransactionKind
 	Date        *string
 	CategoryID  *string
+	AccountID   *string // "" detaches the transaction from its account
+	PayeeID     *string // "" clears the payee
+	AmountCents *int64
+	Currency    *string
+	Note        *string
+	TagIDs      *[]string          // replaces the transaction's tags
+	Splits      *[]models.TxnSplit // replaces the lines; empty un-splits
+	UpdatedAt   *string
+	// IfVersion, when set, makes Update fail with errs.ErrPreconditionFailed
+	// unless the transaction is still at that version.
+	IfVersion int64
+}
+
+// TxnFilter narrows a transaction listing. Zero values mean "no constraint".
+// From/To are inclusive YYYY-MM-DD keys.
+type TxnFilter struct {
+	From           string
+	To             string
+	Kind           models.TransactionKind
+	CategoryID     string
+	AccountID      string
+	PayeeID        string
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	NoteContains   string
+	TagID          string
+}
+
+type TxnSort string
+
+const (
+	TxnSortDateDesc   TxnSort = "date_desc"
+	TxnSortDateAsc    TxnSort = "date_asc"
+	TxnSortAmountDesc TxnSort = "amount_desc"
+	TxnSortAmountAsc  TxnSort = "amount_asc"
+)
+
+func (s TxnSort) Valid() bool {
+	switch s {
+	case TxnSortDateDesc, TxnSortDateAsc, TxnSortAmountDesc, TxnSortAmountAsc:
+		return true
+	}
+	return false
+}
+
+// TxnQuery is a filtered, keyset-paginated listing. Cursor is the opaque
+// NextCursor of a previous page and is only valid with the same Sort.
+type TxnQuery struct {
+	TxnFilter
+	Sort   TxnSort
+	Cursor string
+	Limit  int
+}
+
+type TxnPage struct {
+	Items      []models.Txn `json:"items"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
+
+// TxnSearch matches transactions containing any of Terms as a word prefix,
+// lowercase letters and digits only, within the filter.
+type TxnSearch struct {
+	TxnFilter
+	Terms []string
+	Limit int
+}
+
+// TxnSearchHit is a search result. Snippet is HTML-escaped text of the note, or
+// the category name when only that matched, with matches wrapped in <mark>.
+type TxnSearchHit struct {
+	Txn     models.Txn `json:"transaction"`
+	Rank    float64    `json:"rank"`
+	Snippet string     `json:"snippet"`
+}
+
+type TxnSearchResult struct {
+	Items []TxnSearchHit `json:"items"`
+}
+
+// ReportRepository runs aggregate queries; it never returns individual rows.
+// Amounts come back converted to currency at each row's date; rows in other
+// currencies without a usable exchange rate are left out (see MissingRates).
+// Per-category figures count each line of a split transaction separately.
+type ReportRepository interface {
+	CategoryTotals(ctx context.Context, from string, to string, currency string) ([]models.CategoryTotal, error)
+	// TagTotals sums expense transactions within [from, to] per tag, largest first.
+	TagTotals(ctx context.Context, from string, to string, currency string) ([]models.TagTotal, error)
+	// PayeeTotals sums expense transactions within [from, to] per payee, largest
+	// first, up to limit payees.
+	PayeeTotals(ctx context.Context, from string, to string, currency string, limit int) ([]models.PayeeTotal, error)
+	// BudgetActuals returns budgeted and spent amounts for every expense category
+	// that has a budget in month or expense transactions within [from, to].
+	BudgetActuals(ctx context.Context, month string, from string, to string, currency string) ([]models.BudgetLine, error)
+	// MonthlyExpenses sums expense transactions per (month, category) within [from, to].
+	// An empty categoryIDs means all categories.
+	MonthlyExpenses(ctx context.Context, from string, to string, categoryIDs []string, currency string) ([]MonthlyAmount, error)
+	// MonthlyBudgets lists budget amounts per (month, category) for months in [fromMonth, toMonth].
+	MonthlyBudgets(ctx context.Context, fromMonth string, toMonth string, categoryIDs []string, currency string) ([]MonthlyAmount, error)
+	// MissingRates lists currencies of income/expense transactions within [from, to]
+	// that cannot be converted to currency.
+	MissingRates(ctx context.Context, from string, to string, currency string) ([]string, error)
+	// MissingBudgetRates lists currencies of budgets for months in [fromMonth,
+	// toMonth] that cannot be converted to currency at their month's end.
+	MissingBudgetRates(ctx context.Context, fromMonth string, toMonth string, currency string) ([]string, error)
+}
+
+type MonthlyAmount struct {
+	Month       string
+	CategoryID  string
+	AmountCents int64
+}
+
+type RateRepository interface {
+	List(ctx context.Context, f RateFilter) ([]models.ExchangeRate, error)
+	// Upsert inserts a rate or replaces the one for the same pair and date.
+	Upsert(ctx context.Context, r models.ExchangeRate) (models.ExchangeRate, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// RateFilter narrows a rate listing; zero values mean "no constraint".
+type RateFilter struct {
+	Base  string
+	Quote string
+	From  string
+	To    string
+}
+
+type RecurringRuleRepository interface {
+	List(ctx context.Context) ([]models.RecurringRule, error)
+	Get(ctx context.Context, id string) (models.RecurringRule, error)
+	Create(ctx context.Context, r models.RecurringRule) (models.RecurringRule, error)
+	Update(ctx context.Context, id string, patch RecurringRulePatch) (models.RecurringRule, error)
+	Delete(ctx context.Context, id string) error
+	CountByCategory(ctx context.Context, categoryID string) (int, error)
+	CountByAccount(ctx context.Context, accountID string) (int, error)
+	// DueOwners lists users with an unpaused rule not yet run through date. Like
+	// UserRepository it is not scoped by the user in ctx.
+	DueOwners(ctx context.Context, date string) ([]string, error)
+	DeleteAll(ctx context.Context) error
+	CreateMany(ctx context.Context, items []models.RecurringRule) error
+}
+
+type RecurringRulePatch struct {
+	Name        *string
+	CategoryID  *string
 	AmountCents *int64
 	Note        *string
+	EndDate     *string // "" removes the end date
+	Paused      *bool
+	LastRunDate *string
 	UpdatedAt   *string
 }
+
+// RuleRepository lists rules in the order they run.
+type RuleRepository interface {
+	List(ctx context.Context) ([]models.Rule, error)
+	Get(ctx context.Context, id string) (models.Rule, error)
+	Create(ctx context.Context, r models.Rule) (models.Rule, error)
+	Update(ctx context.Context, id string, patch RulePatch) (models.Rule, error)
+	Delete(ctx context.Context, id string) error
+	CountByCategory(ctx context.Context, categoryID string) (int, error)
+	CountByAccount(ctx context.Context, accountID string) (int, error)
+}
+
+// RulePatch replaces conditions and actions as a whole.
+type RulePatch struct {
+	Name       *string
+	Priority   *int
+	Paused     *bool
+	Conditions *models.RuleConditions
+	Actions    *models.RuleActions
+	UpdatedAt  *string
+}
+
+// AuditRepository is append-only; entries are never changed or removed.
+type AuditRepository interface {
+	CreateMany(ctx context.Context, items []models.AuditEntry) error
+	// History returns every entry of one entity, newest first.
+	History(ctx context.Context, entity models.AuditEntity, entityID string) ([]models.AuditEntry, error)
+	// Feed returns entries newest first, a page at a time.
+	Feed(ctx context.Context, q AuditQuery) (AuditPage, error)
+}
+
+// AuditQuery selects entries created in [From, To); a zero bound is open.
+// EntityType, when set, keeps one kind of entity. Cursor is the NextCursor of
+// the previous page.
+type AuditQuery struct {
+	From       time.Time
+	To         time.Time
+	EntityType models.AuditEntity
+	Cursor     string
+	Limit      int
+}
+
+type AuditPage struct {
+	Items      []models.AuditEntry `json:"items"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
+
+// UserRepository stores accounts. Unlike the data repositories it is not scoped
+// by the user in ctx.
+type UserRepository interface {
+	Get(ctx context.Context, id string) (models.User, error)
+	// GetByEmail returns the user and their password hash.
+	GetByEmail(ctx context.Context, email string) (models.User, string, error)
+	Create(ctx context.Context, u models.User, passwordHash string) (models.User, error)
+	Count(ctx context.Context) (int64, error)
+	// ClaimUnowned assigns rows created before accounts existed to userID.
+	ClaimUnowned(ctx context.Context, userID string) error
+}
+
+type Session struct {
+	TokenHash string
+	UserID    string
+	ExpiresAt time.Time
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, s Session) error
+	// Get returns errs.ErrNotFound for unknown tokens; callers check ExpiresAt.
+	Get(ctx context.Context, tokenHash string) (Session, error)
+	Delete(ctx context.Context, tokenHash string) error
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
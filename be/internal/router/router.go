@@ -13,46 +13,176 @@ import (
 type App = fiber.App
 
 type Deps struct {
+	// HomeCurrency is the default for new accounts, budgets and transactions.
+	HomeCurrency string
+	// AttachmentMaxBytes caps uploaded attachments; 0 means
+	// handlers.DefaultAttachmentMaxBytes.
+	AttachmentMaxBytes int64
+
+	Auth        *services.AuthService
+	Account     *services.AccountService
 	Category    *services.CategoryService
 	Budget      *services.BudgetService
 	Transaction *services.TxnService
+	Transfer    *services.TransferService
+	Recurring   *services.RecurringService
+	Tag         *services.TagService
+	Rule        *services.RuleService
+	Payee       *services.PayeeService
+	Attachment  *services.AttachmentService
+	Trash       *services.TrashService
+	Audit       *services.AuditService
 	State       *services.StateService
+	Rate        *services.RateService
+	Report      *services.ReportService
+	Import      *services.ImportService
+	Export      *services.ExportService
 }
 
 func New(d Deps) *fiber.App {
-	app := fiber.New()
+	if d.AttachmentMaxBytes <= 0 {
+		d.AttachmentMaxBytes = handlers.DefaultAttachmentMaxBytes
+	}
+	// Leave room for the multipart framing around the largest attachment.
+	app := fiber.New(fiber.Config{BodyLimit: max(fiber.DefaultBodyLimit, int(d.AttachmentMaxBytes)+64<<10)})
 	app.Use(recover.New())
 	app.Use(logger.New())
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "http://localhost:5173,http://127.0.0.1:5173",
-		AllowHeaders: "Origin, Content-Type, Accept",
-		AllowMethods: "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowOrigins:  "http://localhost:5173,http://127.0.0.1:5173",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, If-Match",
+		AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		ExposeHeaders: "ETag",
 	}))
 
 	v1 := app.Group("/api/v1")
 	v1.Get("/health", handlers.Health())
 
-	state := handlers.State{Svc: d.State}
+	authH := handlers.Auth{Svc: d.Auth}
+	v1.Post("/auth/register", authH.Register)
+	v1.Post("/auth/login", authH.Login)
+
+	// Everything below requires a bearer token.
+	v1.Use(authH.Require)
+	v1.Post("/auth/logout", authH.Logout)
+	v1.Get("/auth/me", authH.Me)
+
+	state := handlers.State{Svc: d.State, AttachmentSvc: d.Attachment}
 	v1.Get("/state", state.Get)
 	v1.Put("/state", state.Replace)
 
-	cats := handlers.Categories{Svc: d.Category, BudgetSvc: d.Budget, TxnSvc: d.Transaction}
+	cats := handlers.Categories{Svc: d.Category, BudgetSvc: d.Budget, TxnSvc: d.Transaction, RecurringSvc: d.Recurring, RuleSvc: d.Rule}
 	v1.Get("/categories", cats.List)
 	v1.Post("/categories", cats.Create)
+	v1.Get("/categories/:id", cats.Get)
 	v1.Patch("/categories/:id", cats.Update)
 	v1.Delete("/categories/:id", cats.Delete)
 
-	budgets := handlers.Budgets{Svc: d.Budget, CatSvc: d.Category}
+	budgets := handlers.Budgets{Svc: d.Budget, CatSvc: d.Category, HomeCurrency: d.HomeCurrency}
 	v1.Get("/budgets", budgets.List)
 	v1.Put("/budgets", budgets.Upsert)
 	v1.Delete("/budgets/:id", budgets.Delete)
+	v1.Post("/budgets/copy", budgets.Copy)
+	v1.Get("/budget-templates", budgets.ListTemplates)
+	v1.Post("/budget-templates", budgets.CreateTemplate)
+	v1.Get("/budget-templates/:id", budgets.GetTemplate)
+	v1.Patch("/budget-templates/:id", budgets.UpdateTemplate)
+	v1.Delete("/budget-templates/:id", budgets.DeleteTemplate)
+	v1.Post("/budget-templates/:id/apply", budgets.ApplyTemplate)
+
+	accounts := handlers.Accounts{Svc: d.Account, TxnSvc: d.Transaction, RecurringSvc: d.Recurring, RuleSvc: d.Rule, HomeCurrency: d.HomeCurrency}
+	v1.Get("/accounts", accounts.List)
+	v1.Post("/accounts", accounts.Create)
+	v1.Get("/accounts/:id", accounts.Get)
+	v1.Patch("/accounts/:id", accounts.Update)
+	v1.Delete("/accounts/:id", accounts.Delete)
+	v1.Get("/accounts/:id/balance", accounts.Balance)
 
-	txns := handlers.Transactions{Svc: d.Transaction, CatSvc: d.Category}
+	txns := handlers.Transactions{Svc: d.Transaction, CatSvc: d.Category, AccSvc: d.Account, TagSvc: d.Tag, TransferSvc: d.Transfer, RuleSvc: d.Rule, PayeeSvc: d.Payee, HomeCurrency: d.HomeCurrency}
 	v1.Get("/transactions", txns.List)
+	v1.Get("/transactions/search", txns.Search)
 	v1.Post("/transactions", txns.Create)
+	v1.Get("/transactions/:id", txns.Get)
 	v1.Patch("/transactions/:id", txns.Update)
 	v1.Delete("/transactions/:id", txns.Delete)
 
+	att := handlers.Attachments{Svc: d.Attachment, TxnSvc: d.Transaction, MaxBytes: d.AttachmentMaxBytes}
+	v1.Get("/transactions/:id/attachments", att.List)
+	v1.Post("/transactions/:id/attachments", att.Upload)
+	v1.Get("/attachments/:id", att.Get)
+	v1.Get("/attachments/:id/content", att.Download)
+	v1.Delete("/attachments/:id", att.Delete)
+
+	transfers := handlers.Transfers{Svc: d.Transfer, AccSvc: d.Account}
+	v1.Post("/transfers", transfers.Create)
+	v1.Get("/transfers/:id", transfers.Get)
+	v1.Patch("/transfers/:id", transfers.Update)
+	v1.Delete("/transfers/:id", transfers.Delete)
+
+	recurring := handlers.Recurring{Svc: d.Recurring, CatSvc: d.Category, AccSvc: d.Account, HomeCurrency: d.HomeCurrency}
+	v1.Get("/recurring", recurring.List)
+	v1.Post("/recurring", recurring.Create)
+	v1.Get("/recurring/:id", recurring.Get)
+	v1.Patch("/recurring/:id", recurring.Update)
+	v1.Delete("/recurring/:id", recurring.Delete)
+	v1.Get("/recurring/:id/preview", recurring.Preview)
+
+	tags := handlers.Tags{Svc: d.Tag}
+	v1.Get("/tags", tags.List)
+	v1.Post("/tags", tags.Create)
+	v1.Patch("/tags/:id", tags.Update)
+	v1.Delete("/tags/:id", tags.Delete)
+
+	payees := handlers.Payees{Svc: d.Payee}
+	v1.Get("/payees", payees.List)
+	v1.Post("/payees", payees.Create)
+	v1.Get("/payees/match", payees.Match)
+	v1.Get("/payees/:id", payees.Get)
+	v1.Patch("/payees/:id", payees.Update)
+	v1.Delete("/payees/:id", payees.Delete)
+	v1.Post("/payees/:id/merge", payees.Merge)
+
+	rules := handlers.Rules{Svc: d.Rule, CatSvc: d.Category, AccSvc: d.Account, TagSvc: d.Tag}
+	v1.Get("/rules", rules.List)
+	v1.Post("/rules", rules.Create)
+	v1.Get("/rules/:id", rules.Get)
+	v1.Patch("/rules/:id", rules.Update)
+	v1.Delete("/rules/:id", rules.Delete)
+	v1.Post("/rules/:id/preview", rules.Preview)
+	v1.Post("/rules/:id/apply", rules.Apply)
+
+	trash := handlers.Trash{Svc: d.Trash}
+	v1.Get("/trash", trash.List)
+	v1.Post("/trash/transactions/:id/restore", trash.RestoreTransaction)
+	v1.Post("/trash/categories/:id/restore", trash.RestoreCategory)
+	v1.Post("/trash/budgets/:id/restore", trash.RestoreBudget)
+
+	audit := handlers.Audit{Svc: d.Audit}
+	v1.Get("/audit", audit.Feed)
+	v1.Get("/transactions/:id/history", audit.TransactionHistory)
+	v1.Get("/categories/:id/history", audit.CategoryHistory)
+	v1.Get("/budgets/:id/history", audit.BudgetHistory)
+
+	rates := handlers.Rates{Svc: d.Rate}
+	v1.Get("/rates", rates.List)
+	v1.Put("/rates", rates.Upsert)
+	v1.Delete("/rates/:id", rates.Delete)
+
+	reports := handlers.Reports{Svc: d.Report}
+	v1.Get("/summary", reports.Summary)
+	v1.Get("/reports/budget-vs-actual", reports.BudgetVsActual)
+	v1.Get("/reports/trends", reports.Trends)
+	v1.Get("/reports/tags", reports.Tags)
+	v1.Get("/reports/payees", reports.Payees)
+
+	imp := handlers.Imports{Svc: d.Import, HomeCurrency: d.HomeCurrency}
+	v1.Post("/imports/csv/preview", imp.PreviewCSV)
+	v1.Post("/imports/csv/commit", imp.CommitCSV)
+	v1.Post("/imports/ofx/preview", imp.PreviewOFX)
+	v1.Post("/imports/ofx/commit", imp.CommitOFX)
+
+	exp := handlers.Exports{Svc: d.Export}
+	v1.Get("/exports/transactions", exp.Transactions)
+
 	return app
 }
 
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+type AccountService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	accounts repositories.AccountRepository
+	txns     repositories.TxnRepository
+}
+
+func NewAccountService(clk clock.Clock, ids id.Generator, accounts repositories.AccountRepository, txns repositories.TxnRepository) *AccountService {
+	return &AccountService{clk: clk, ids: ids, accounts: accounts, txns: txns}
+}
+
+func (s *AccountService) List(ctx context.Context, includeArchived bool) ([]models.Account, error) {
+	return s.accounts.List(ctx, includeArchived)
+}
+
+func (s *AccountService) Get(ctx context.Context, id string) (models.Account, error) {
+	return s.accounts.Get(ctx, id)
+}
+
+type CreateAccountInput struct {
+	Name                string             `json:"name"`
+	Type                models.AccountType `json:"type"`
+	OpeningBalanceCents int64              `json:"openingBalanceCents"`
+	Currency            string             `json:"currency,omitempty"`
+}
+
+func (s *AccountService) Create(ctx context.Context, in CreateAccountInput) (models.Account, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	currency := strings.ToUpper(strings.TrimSpace(in.Currency))
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	a := models.Account{
+		ID:                  s.ids.NewID(),
+		Name:                strings.TrimSpace(in.Name),
+		Type:                in.Type,
+		OpeningBalanceCents: in.OpeningBalanceCents,
+		Currency:            currency,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	return s.accounts.Create(ctx, a)
+}
+
+type UpdateAccountInput struct {
+	Name                *string             `json:"name"`
+	Type                *models.AccountType `json:"type"`
+	OpeningBalanceCents *int64              `json:"openingBalanceCents"`
+	Currency            *string             `json:"currency"`
+	Archived            *bool               `json:"archived"`
+}
+
+func (s *AccountService) Update(ctx context.Context, id string, in UpdateAccountInput) (models.Account, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	patch := repositories.AccountPatch{
+		Type:                in.Type,
+		OpeningBalanceCents: in.OpeningBalanceCents,
+		Archived:            in.Archived,
+		UpdatedAt:           &now,
+	}
+	if in.Name != nil {
+		n := strings.TrimSpace(*in.Name)
+		patch.Name = &n
+	}
+	if in.Currency != nil {
+		ccy := strings.ToUpper(strings.TrimSpace(*in.Currency))
+		patch.Currency = &ccy
+	}
+	return s.accounts.Update(ctx, id, patch)
+}
+
+func (s *AccountService) Delete(ctx context.Context, id string) error {
+	return s.accounts.Delete(ctx, id)
+}
+
+// Balance walks the account's transactions in [from, to] (either bound may be
+// empty) and returns each with the running balance after it. The start balance
+// is the opening balance plus everything dated before from.
+func (s *AccountService) Balance(ctx context.Context, accountID string, from string, to string) (models.AccountBalance, error) {
+	acct, err := s.accounts.Get(ctx, accountID)
+	if err != nil {
+		return models.AccountBalance{}, err
+	}
+	start := acct.OpeningBalanceCents
+	if from != "" {
+		net, err := s.accounts.NetChange(ctx, accountID, from)
+		if err != nil {
+			return models.AccountBalance{}, err
+		}
+		start += net
+	}
+
+	out := models.AccountBalance{
+		AccountID:         acct.ID,
+		Currency:          acct.Currency,
+		From:              from,
+		To:                to,
+		StartBalanceCents: start,
+		Entries:           []models.BalanceEntry{},
+	}
+	running := start
+	err = s.txns.Each(ctx, repositories.TxnFilter{AccountID: accountID, From: from, To: to}, func(t models.Txn) error {
+		amt := signedAmount(t)
+		running += amt
+		out.Entries = append(out.Entries, models.BalanceEntry{
+			TxnID:        t.ID,
+			Date:         t.Date,
+			Kind:         t.Kind,
+			Note:         t.Note,
+			AmountCents:  amt,
+			BalanceCents: running,
+		})
+		return nil
+	})
+	if err != nil {
+		return models.AccountBalance{}, err
+	}
+	out.EndBalanceCents = running
+	return out, nil
+}
+
+// signedAmount is the effect of t on its account's balance.
+func signedAmount(t models.Txn) int64 {
+	if t.Kind == models.KindIncome || t.TransferDirection == models.TransferIn {
+		return t.AmountCents
+	}
+	return -t.AmountCents
+}
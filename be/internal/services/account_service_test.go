@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/testutil"
+)
+
+func TestAccountService_Balance(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+	svc := NewAccountService(clk, ids, repositories.NewGormAccountRepo(gdb), txnRepo)
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), txnRepo)
+
+	_, _ = catRepo.Create(ctx, models.Category{ID: "food", Type: models.CategoryExpense, Name: "Food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "salary", Type: models.CategoryIncome, Name: "Salary"})
+	bca, err := svc.Create(ctx, CreateAccountInput{Name: "BCA", Type: models.AccountBank, OpeningBalanceCents: 1000_00})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if bca.Currency != models.DefaultCurrency {
+		t.Fatalf("expected default currency, got %q", bca.Currency)
+	}
+
+	for _, in := range []CreateTxnInput{
+		{Kind: models.KindExpense, Date: "2025-12-30", CategoryID: "food", AccountID: bca.ID, AmountCents: 100_00},
+		{Kind: models.KindIncome, Date: "2026-01-25", CategoryID: "salary", AccountID: bca.ID, AmountCents: 5000_00},
+		{Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "food", AccountID: bca.ID, AmountCents: 250_00},
+		{Kind: models.KindExpense, Date: "2026-01-06", CategoryID: "food", AmountCents: 999_00}, // no account
+	} {
+		if _, err := txns.Create(ctx, in); err != nil {
+			t.Fatalf("create txn: %v", err)
+		}
+	}
+
+	got, err := svc.Balance(ctx, bca.ID, "2026-01-01", "")
+	if err != nil {
+		t.Fatalf("balance: %v", err)
+	}
+	if got.StartBalanceCents != 900_00 || got.EndBalanceCents != 5650_00 {
+		t.Fatalf("unexpected start/end: %+v", got)
+	}
+	if len(got.Entries) != 2 || got.Entries[0].AmountCents != -250_00 || got.Entries[0].BalanceCents != 650_00 {
+		t.Fatalf("unexpected entries: %+v", got.Entries)
+	}
+
+	all, _ := svc.Balance(ctx, bca.ID, "", "")
+	if len(all.Entries) != 3 || all.StartBalanceCents != 1000_00 || all.EndBalanceCents != 5650_00 {
+		t.Fatalf("unexpected full ledger: %+v", all)
+	}
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+
+	"personal-budgeting/be/internal/blob"
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// AttachmentService keeps attachment metadata in the database and content in a
+// blob.Store under the attachment's ID.
+type AttachmentService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	attachments repositories.AttachmentRepository
+	blobs       blob.Store
+}
+
+func NewAttachmentService(clk clock.Clock, ids id.Generator, attachments repositories.AttachmentRepository, blobs blob.Store) *AttachmentService {
+	return &AttachmentService{clk: clk, ids: ids, attachments: attachments, blobs: blobs}
+}
+
+func (s *AttachmentService) List(ctx context.Context, txnID string) ([]models.Attachment, error) {
+	return s.attachments.ListByTxn(ctx, txnID)
+}
+
+func (s *AttachmentService) Get(ctx context.Context, id string) (models.Attachment, error) {
+	return s.attachments.Get(ctx, id)
+}
+
+type CreateAttachmentInput struct {
+	TxnID       string
+	FileName    string
+	ContentType string
+	SizeBytes   int64
+	Body        io.Reader
+}
+
+// Create stores the content before the metadata, so a listed attachment can
+// always be downloaded.
+func (s *AttachmentService) Create(ctx context.Context, in CreateAttachmentInput) (models.Attachment, error) {
+	a := models.Attachment{
+		ID:          s.ids.NewID(),
+		TxnID:       in.TxnID,
+		FileName:    in.FileName,
+		ContentType: in.ContentType,
+		SizeBytes:   in.SizeBytes,
+		CreatedAt:   s.clk.Now().Format(time.RFC3339),
+	}
+	if err := s.blobs.Put(ctx, a.ID, in.Body); err != nil {
+		return models.Attachment{}, err
+	}
+	out, err := s.attachments.Create(ctx, a)
+	if err != nil {
+		_ = s.blobs.Delete(ctx, a.ID)
+		return models.Attachment{}, err
+	}
+	return out, nil
+}
+
+// Open returns the attachment with its content; callers close the reader.
+func (s *AttachmentService) Open(ctx context.Context, id string) (models.Attachment, io.ReadCloser, error) {
+	a, err := s.attachments.Get(ctx, id)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	rc, err := s.blobs.Open(ctx, a.ID)
+	if err != nil {
+		return models.Attachment{}, nil, err
+	}
+	return a, rc, nil
+}
+
+func (s *AttachmentService) Delete(ctx context.Context, id string) error {
+	if err := s.attachments.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := s.blobs.Delete(ctx, id); err != nil {
+		log.Printf("attachment %s: delete content: %v", id, err)
+	}
+	return nil
+}
+
+// Prune removes the attachments, content first, of transactions that no longer
+// exist. It runs after transactions are deleted; failures are only logged, and
+// whatever is left is picked up by the next prune.
+func (s *AttachmentService) Prune(ctx context.Context) {
+	orphans, err := s.attachments.ListOrphans(ctx)
+	if err != nil {
+		log.Printf("attachments: prune: %v", err)
+		return
+	}
+	ids := make([]string, 0, len(orphans))
+	for _, a := range orphans {
+		if err := s.blobs.Delete(ctx, a.ID); err != nil {
+			log.Printf("attachment %s: delete content: %v", a.ID, err)
+			continue
+		}
+		ids = append(ids, a.ID)
+	}
+	if err := s.attachments.DeleteMany(ctx, ids); err != nil {
+		log.Printf("attachments: prune: %v", err)
+	}
+}
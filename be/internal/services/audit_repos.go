@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// The audited repositories pass reads through and record every write in the
+// audit log, in one database transaction with the write. Purging the trash is
+// not recorded: the delete that put an item there already is.
+
+// Categories wraps cats so that changes made through it are recorded.
+func (s *AuditService) Categories(cats repositories.CategoryRepository) repositories.CategoryRepository {
+	return auditedCategories{CategoryRepository: cats, audit: s}
+}
+
+type auditedCategories struct {
+	repositories.CategoryRepository
+	audit *AuditService
+}
+
+func (r auditedCategories) Create(ctx context.Context, c models.Category) (models.Category, error) {
+	var out models.Category
+	err := r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = r.CategoryRepository.Create(ctx, c); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditCategory, out.ID, models.AuditCreate, nil, out)
+	})
+	return out, err
+}
+
+func (r auditedCategories) Update(ctx context.Context, id string, patch repositories.CategoryPatch) (models.Category, error) {
+	var out models.Category
+	err := r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.CategoryRepository.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if out, err = r.CategoryRepository.Update(ctx, id, patch); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditCategory, id, models.AuditUpdate, before, out)
+	})
+	return out, err
+}
+
+func (r auditedCategories) Delete(ctx context.Context, id string) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.CategoryRepository.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := r.CategoryRepository.Delete(ctx, id); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditCategory, id, models.AuditDelete, before, nil)
+	})
+}
+
+func (r auditedCategories) Restore(ctx context.Context, id string) (models.Category, error) {
+	var out models.Category
+	err := r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = r.CategoryRepository.Restore(ctx, id); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditCategory, id, models.AuditRestore, nil, out)
+	})
+	return out, err
+}
+
+func (r auditedCategories) DeleteAll(ctx context.Context) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.CategoryRepository.List(ctx)
+		if err != nil {
+			return err
+		}
+		if err := r.CategoryRepository.DeleteAll(ctx); err != nil {
+			return err
+		}
+		return recordAll(ctx, r.audit, models.AuditCategory, models.AuditDelete, before, categoryID)
+	})
+}
+
+func (r auditedCategories) CreateMany(ctx context.Context, items []models.Category) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.CategoryRepository.CreateMany(ctx, items); err != nil {
+			return err
+		}
+		return recordAll(ctx, r.audit, models.AuditCategory, models.AuditCreate, items, categoryID)
+	})
+}
+
+func categoryID(c models.Category) string { return c.ID }
+
+// Budgets wraps budgets so that changes made through it are recorded. Budget
+// templates are not audited.
+func (s *AuditService) Budgets(budgets repositories.BudgetRepository) repositories.BudgetRepository {
+	return auditedBudgets{BudgetRepository: budgets, audit: s}
+}
+
+type auditedBudgets struct {
+	repositories.BudgetRepository
+	audit *AuditService
+}
+
+// Upsert records a create, or an update of the budget the month and category
+// already have.
+func (r auditedBudgets) Upsert(ctx context.Context, b models.Budget) (models.Budget, error) {
+	var out models.Budget
+	err := r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, ok, err := r.BudgetRepository.FindByMonthCategory(ctx, b.Month, b.CategoryID)
+		if err != nil {
+			return err
+		}
+		if out, err = r.BudgetRepository.Upsert(ctx, b); err != nil {
+			return err
+		}
+		if !ok {
+			return r.audit.record(ctx, models.AuditBudget, out.ID, models.AuditCreate, nil, out)
+		}
+		return r.audit.record(ctx, models.AuditBudget, out.ID, models.AuditUpdate, before, out)
+	})
+	return out, err
+}
+
+func (r auditedBudgets) Delete(ctx context.Context, id string) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.BudgetRepository.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := r.BudgetRepository.Delete(ctx, id); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditBudget, id, models.AuditDelete, before, nil)
+	})
+}
+
+func (r auditedBudgets) Restore(ctx context.Context, id string) (models.Budget, error) {
+	var out models.Budget
+	err := r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = r.BudgetRepository.Restore(ctx, id); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditBudget, id, models.AuditRestore, nil, out)
+	})
+	return out, err
+}
+
+func (r auditedBudgets) DeleteAll(ctx context.Context) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.BudgetRepository.List(ctx)
+		if err != nil {
+			return err
+		}
+		if err := r.BudgetRepository.DeleteAll(ctx); err != nil {
+			return err
+		}
+		return recordAll(ctx, r.audit, models.AuditBudget, models.AuditDelete, before, budgetID)
+	})
+}
+
+func (r auditedBudgets) CreateMany(ctx context.Context, items []models.Budget) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.BudgetRepository.CreateMany(ctx, items); err != nil {
+			return err
+		}
+		return recordAll(ctx, r.audit, models.AuditBudget, models.AuditCreate, items, budgetID)
+	})
+}
+
+func budgetID(b models.Budget) string { return b.ID }
+
+// Txns wraps txns so that changes made through it are recorded.
+func (s *AuditService) Txns(txns repositories.TxnRepository) repositories.TxnRepository {
+	return auditedTxns{TxnRepository: txns, audit: s}
+}
+
+type auditedTxns struct {
+	repositories.TxnRepository
+	audit *AuditService
+}
+
+func (r auditedTxns) Create(ctx context.Context, t models.Txn) (models.Txn, error) {
+	var out models.Txn
+	err := r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = r.TxnRepository.Create(ctx, t); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditTransaction, out.ID, models.AuditCreate, nil, out)
+	})
+	return out, err
+}
+
+func (r auditedTxns) Update(ctx context.Context, id string, patch repositories.TxnPatch) (models.Txn, error) {
+	var out models.Txn
+	err := r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.TxnRepository.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if out, err = r.TxnRepository.Update(ctx, id, patch); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditTransaction, id, models.AuditUpdate, before, out)
+	})
+	return out, err
+}
+
+func (r auditedTxns) Delete(ctx context.Context, id string) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.TxnRepository.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := r.TxnRepository.Delete(ctx, id); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditTransaction, id, models.AuditDelete, before, nil)
+	})
+}
+
+func (r auditedTxns) Restore(ctx context.Context, id string) (models.Txn, error) {
+	var out models.Txn
+	err := r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = r.TxnRepository.Restore(ctx, id); err != nil {
+			return err
+		}
+		return r.audit.record(ctx, models.AuditTransaction, id, models.AuditRestore, nil, out)
+	})
+	return out, err
+}
+
+// ReassignPayee records an update of each transaction that moves; ones in the
+// trash move too but are not recorded.
+func (r auditedTxns) ReassignPayee(ctx context.Context, from []string, to string) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var ids []string
+		for _, payeeID := range from {
+			err := r.TxnRepository.Each(ctx, repositories.TxnFilter{PayeeID: payeeID}, func(t models.Txn) error {
+				ids = append(ids, t.ID)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		befores := make([]models.Txn, 0, len(ids))
+		for _, id := range ids {
+			t, err := r.TxnRepository.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			befores = append(befores, t)
+		}
+		if err := r.TxnRepository.ReassignPayee(ctx, from, to); err != nil {
+			return err
+		}
+		for _, before := range befores {
+			after := before
+			after.PayeeID = to
+			after.Version++
+			if err := r.audit.record(ctx, models.AuditTransaction, before.ID, models.AuditUpdate, before, after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r auditedTxns) DeleteAll(ctx context.Context) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		before, err := r.TxnRepository.List(ctx)
+		if err != nil {
+			return err
+		}
+		if err := r.TxnRepository.DeleteAll(ctx); err != nil {
+			return err
+		}
+		return recordAll(ctx, r.audit, models.AuditTransaction, models.AuditDelete, before, txnID)
+	})
+}
+
+func (r auditedTxns) CreateMany(ctx context.Context, items []models.Txn) error {
+	return r.audit.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.TxnRepository.CreateMany(ctx, items); err != nil {
+			return err
+		}
+		return recordAll(ctx, r.audit, models.AuditTransaction, models.AuditCreate, items, txnID)
+	})
+}
+
+func txnID(t models.Txn) string { return t.ID }
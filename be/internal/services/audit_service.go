@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// AuditService keeps the audit log of categories, budgets and transactions.
+// Changes are recorded by the repositories it wraps (see Categories, Budgets
+// and Txns), so every service writing through them is covered, and each entry
+// is stored in the same database transaction as its change.
+type AuditService struct {
+	clk clock.Clock
+
+	tx      repositories.Transactor
+	entries repositories.AuditRepository
+}
+
+func NewAuditService(clk clock.Clock, tx repositories.Transactor, entries repositories.AuditRepository) *AuditService {
+	return &AuditService{clk: clk, tx: tx, entries: entries}
+}
+
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 500
+)
+
+// History returns every change of one entity, newest first. It outlives the
+// entity, so it also works for deleted and purged ones.
+func (s *AuditService) History(ctx context.Context, entity models.AuditEntity, id string) ([]models.AuditEntry, error) {
+	return s.entries.History(ctx, entity, id)
+}
+
+// AuditFeedInput is the query-string shape of GET /audit. From and To are
+// YYYY-MM-DD days in UTC, both inclusive.
+type AuditFeedInput struct {
+	From       string
+	To         string
+	EntityType models.AuditEntity
+	Cursor     string
+	Limit      int
+}
+
+func (s *AuditService) Feed(ctx context.Context, in AuditFeedInput) (repositories.AuditPage, error) {
+	q := repositories.AuditQuery{EntityType: in.EntityType, Cursor: in.Cursor, Limit: in.Limit}
+	if in.From != "" {
+		from, err := time.Parse(dateLayout, in.From)
+		if err != nil {
+			return repositories.AuditPage{}, errs.ErrValidation
+		}
+		q.From = from
+	}
+	if in.To != "" {
+		to, err := time.Parse(dateLayout, in.To)
+		if err != nil {
+			return repositories.AuditPage{}, errs.ErrValidation
+		}
+		q.To = to.AddDate(0, 0, 1)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultAuditPageSize
+	}
+	if q.Limit > MaxAuditPageSize {
+		q.Limit = MaxAuditPageSize
+	}
+	return s.entries.Feed(ctx, q)
+}
+
+// record stores one change. before is nil for a create or restore, after for a
+// delete.
+func (s *AuditService) record(ctx context.Context, entity models.AuditEntity, id string, action models.AuditAction, before any, after any) error {
+	e, err := s.entry(ctx, entity, id, action, before, after)
+	if err != nil {
+		return err
+	}
+	return s.entries.CreateMany(ctx, []models.AuditEntry{e})
+}
+
+// recordAll stores the same action on many entities: items are what was
+// created, or what was deleted.
+func recordAll[T any](ctx context.Context, s *AuditService, entity models.AuditEntity, action models.AuditAction, items []T, id func(T) string) error {
+	entries := make([]models.AuditEntry, 0, len(items))
+	for _, it := range items {
+		var before, after any = nil, it
+		if action == models.AuditDelete {
+			before, after = it, nil
+		}
+		e, err := s.entry(ctx, entity, id(it), action, before, after)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+	}
+	return s.entries.CreateMany(ctx, entries)
+}
+
+func (s *AuditService) entry(ctx context.Context, entity models.AuditEntity, id string, action models.AuditAction, before any, after any) (models.AuditEntry, error) {
+	b, err := auditFields(before)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	a, err := auditFields(after)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	return models.AuditEntry{
+		EntityType: entity,
+		EntityID:   id,
+		Action:     action,
+		Actor:      auth.Actor(ctx),
+		Before:     b,
+		After:      a,
+		Diff:       auditDiff(b, a),
+		CreatedAt:  s.clk.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// auditFields is v as the API renders it, or nil when v is.
+func auditFields(v any) (map[string]any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var auditBookkeeping = map[string]bool{"updatedAt": true, "version": true}
+
+// auditDiff lists the fields that differ between before and after, leaving out
+// updatedAt and version, which every change bumps. A field missing on one side
+// is null.
+func auditDiff(before map[string]any, after map[string]any) map[string]models.AuditChange {
+	out := map[string]models.AuditChange{}
+	for field, v := range before {
+		if w, ok := after[field]; !auditBookkeeping[field] && (!ok || !reflect.DeepEqual(v, w)) {
+			out[field] = models.AuditChange{From: v, To: w}
+		}
+	}
+	for field, w := range after {
+		if _, ok := before[field]; !auditBookkeeping[field] && !ok {
+			out[field] = models.AuditChange{From: nil, To: w}
+		}
+	}
+	return out
+}
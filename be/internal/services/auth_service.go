@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+const SessionTTL = 30 * 24 * time.Hour
+
+// dummyHash is a bcrypt hash at the cost Register uses. Login checks unknown
+// emails against it so they take as long as known ones and don't reveal which
+// emails have accounts.
+const dummyHash = "$2a$10$0Y.XU4Mtkw3DKzgxZ63DKuEIHubUh/zbX7/iCE4jsIwTEBmVeMpV6"
+
+type AuthService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	tx       repositories.Transactor
+	users    repositories.UserRepository
+	sessions repositories.SessionRepository
+}
+
+func NewAuthService(clk clock.Clock, ids id.Generator, tx repositories.Transactor, users repositories.UserRepository, sessions repositories.SessionRepository) *AuthService {
+	return &AuthService{clk: clk, ids: ids, tx: tx, users: users, sessions: sessions}
+}
+
+type CredentialsInput struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// NormalizeEmail is the form emails are stored and looked up in.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Register creates a user. The first user to register takes ownership of any
+// data that was stored before accounts existed.
+func (s *AuthService) Register(ctx context.Context, in CredentialsInput) (models.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(in.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+	u := models.User{
+		ID:        s.ids.NewID(),
+		Email:     NormalizeEmail(in.Email),
+		CreatedAt: s.clk.Now().Format(time.RFC3339),
+	}
+
+	var out models.User
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		n, err := s.users.Count(ctx)
+		if err != nil {
+			return err
+		}
+		out, err = s.users.Create(ctx, u, string(hash))
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return s.users.ClaimUnowned(ctx, out.ID)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Login checks the password and opens a session. Unknown emails and wrong
+// passwords both yield errs.ErrUnauthorized.
+func (s *AuthService) Login(ctx context.Context, in CredentialsInput) (models.AuthToken, error) {
+	u, hash, err := s.users.GetByEmail(ctx, NormalizeEmail(in.Email))
+	if errors.Is(err, errs.ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(in.Password))
+		return models.AuthToken{}, errs.ErrUnauthorized
+	}
+	if err != nil {
+		return models.AuthToken{}, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(in.Password)) != nil {
+		return models.AuthToken{}, errs.ErrUnauthorized
+	}
+
+	// Opportunistic cleanup; a failure here must not block the login.
+	_ = s.sessions.DeleteExpired(ctx, s.clk.Now())
+
+	var raw [32]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return models.AuthToken{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw[:])
+	expiresAt := s.clk.Now().Add(SessionTTL)
+	err = s.sessions.Create(ctx, repositories.Session{
+		TokenHash: hashToken(token),
+		UserID:    u.ID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return models.AuthToken{}, err
+	}
+	return models.AuthToken{
+		Token:     token,
+		ExpiresAt: expiresAt.UTC().Format(time.RFC3339),
+		User:      u,
+	}, nil
+}
+
+// Authenticate resolves a bearer token to its user ID.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (string, error) {
+	sess, err := s.sessions.Get(ctx, hashToken(token))
+	if errors.Is(err, errs.ErrNotFound) {
+		return "", errs.ErrUnauthorized
+	}
+	if err != nil {
+		return "", err
+	}
+	if !s.clk.Now().Before(sess.ExpiresAt) {
+		_ = s.sessions.Delete(ctx, sess.TokenHash)
+		return "", errs.ErrUnauthorized
+	}
+	return sess.UserID, nil
+}
+
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	return s.sessions.Delete(ctx, hashToken(token))
+}
+
+func (s *AuthService) Me(ctx context.Context, userID string) (models.User, error) {
+	return s.users.Get(ctx, userID)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestDummyHash_MatchesRegisterCost(t *testing.T) {
+	cost, err := bcrypt.Cost([]byte(dummyHash))
+	if err != nil || cost != bcrypt.DefaultCost {
+		t.Fatalf("dummy hash must cost what Register uses (%d), got %d %v", bcrypt.DefaultCost, cost, err)
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"personal-budgeting/be/internal/clock"
@@ -14,11 +15,12 @@ type BudgetService struct {
 	clk clock.Clock
 	ids id.Generator
 
+	tx      repositories.Transactor
 	budgets repositories.BudgetRepository
 }
 
-func NewBudgetService(clk clock.Clock, ids id.Generator, budgets repositories.BudgetRepository) *BudgetService {
-	return &BudgetService{clk: clk, ids: ids, budgets: budgets}
+func NewBudgetService(clk clock.Clock, ids id.Generator, tx repositories.Transactor, budgets repositories.BudgetRepository) *BudgetService {
+	return &BudgetService{clk: clk, ids: ids, tx: tx, budgets: budgets}
 }
 
 func (s *BudgetService) List(ctx context.Context) ([]models.Budget, error) {
@@ -29,6 +31,7 @@ type UpsertBudgetInput struct {
 	Month       string `json:"month"`
 	CategoryID  string `json:"categoryId"`
 	AmountCents int64  `json:"amountCents"`
+	Currency    string `json:"currency"`
 }
 
 func (s *BudgetService) Upsert(ctx context.Context, in UpsertBudgetInput) (models.Budget, error) {
@@ -40,6 +43,7 @@ func (s *BudgetService) Upsert(ctx context.Context, in UpsertBudgetInput) (model
 	now := s.clk.Now().Format(time.RFC3339)
 	if ok {
 		existing.AmountCents = in.AmountCents
+		existing.Currency = in.Currency
 		existing.UpdatedAt = now
 		return s.budgets.Upsert(ctx, existing)
 	}
@@ -49,6 +53,7 @@ func (s *BudgetService) Upsert(ctx context.Context, in UpsertBudgetInput) (model
 		Month:       in.Month,
 		CategoryID:  in.CategoryID,
 		AmountCents: in.AmountCents,
+		Currency:    in.Currency,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -58,3 +63,183 @@ func (s *BudgetService) Upsert(ctx context.Context, in UpsertBudgetInput) (model
 func (s *BudgetService) Delete(ctx context.Context, id string) error {
 	return s.budgets.Delete(ctx, id)
 }
+
+type CopyBudgetsInput struct {
+	FromMonth string                `json:"fromMonth"`
+	ToMonth   string                `json:"toMonth"`
+	Mode      models.BudgetCopyMode `json:"mode"`
+}
+
+// Copy copies every budget of FromMonth into ToMonth in one transaction.
+func (s *BudgetService) Copy(ctx context.Context, in CopyBudgetsInput) (models.BudgetApplyResult, error) {
+	var res models.BudgetApplyResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		src, err := s.budgets.ListByMonth(ctx, in.FromMonth)
+		if err != nil {
+			return err
+		}
+		lines := make([]models.BudgetTemplateLine, 0, len(src))
+		for _, b := range src {
+			lines = append(lines, models.BudgetTemplateLine{CategoryID: b.CategoryID, AmountCents: b.AmountCents, Currency: b.Currency})
+		}
+		res, err = s.apply(ctx, in.ToMonth, lines, in.Mode)
+		return err
+	})
+	return res, err
+}
+
+type ApplyTemplateInput struct {
+	Month string                `json:"month"`
+	Mode  models.BudgetCopyMode `json:"mode"`
+}
+
+// ApplyTemplate sets the template's budgets on a month in one transaction.
+func (s *BudgetService) ApplyTemplate(ctx context.Context, id string, in ApplyTemplateInput) (models.BudgetApplyResult, error) {
+	var res models.BudgetApplyResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		t, err := s.budgets.GetTemplate(ctx, id)
+		if err != nil {
+			return err
+		}
+		res, err = s.apply(ctx, in.Month, t.Lines, in.Mode)
+		return err
+	})
+	return res, err
+}
+
+// ApplyAutoTemplates applies every auto-apply template to month, never
+// touching budgets that are already set. Templates are applied by name, so when
+// two of them budget the same category the first one wins.
+func (s *BudgetService) ApplyAutoTemplates(ctx context.Context, month string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		templates, err := s.budgets.ListTemplates(ctx)
+		if err != nil {
+			return err
+		}
+		for _, t := range templates {
+			if !t.AutoApply {
+				continue
+			}
+			if _, err := s.apply(ctx, month, t.Lines, models.BudgetCopySkip); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// apply upserts lines into month. It must run inside a Transactor.
+func (s *BudgetService) apply(ctx context.Context, month string, lines []models.BudgetTemplateLine, mode models.BudgetCopyMode) (models.BudgetApplyResult, error) {
+	res := models.BudgetApplyResult{Month: month}
+	existing, err := s.budgets.ListByMonth(ctx, month)
+	if err != nil {
+		return res, err
+	}
+	byCategory := make(map[string]models.Budget, len(existing))
+	for _, b := range existing {
+		byCategory[b.CategoryID] = b
+	}
+
+	now := s.clk.Now().Format(time.RFC3339)
+	for _, l := range lines {
+		b, ok := byCategory[l.CategoryID]
+		switch {
+		case ok && mode != models.BudgetCopyOverwrite:
+			res.Skipped++
+			continue
+		case ok:
+			b.AmountCents = l.AmountCents
+			b.Currency = l.Currency
+			b.UpdatedAt = now
+			res.Updated++
+		default:
+			b = models.Budget{
+				ID:          s.ids.NewID(),
+				Month:       month,
+				CategoryID:  l.CategoryID,
+				AmountCents: l.AmountCents,
+				Currency:    l.Currency,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+			res.Created++
+		}
+		if _, err := s.budgets.Upsert(ctx, b); err != nil {
+			return res, err
+		}
+		byCategory[l.CategoryID] = b
+	}
+
+	if res.Budgets, err = s.budgets.ListByMonth(ctx, month); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func (s *BudgetService) ListTemplates(ctx context.Context) ([]models.BudgetTemplate, error) {
+	return s.budgets.ListTemplates(ctx)
+}
+
+func (s *BudgetService) GetTemplate(ctx context.Context, id string) (models.BudgetTemplate, error) {
+	return s.budgets.GetTemplate(ctx, id)
+}
+
+func (s *BudgetService) CountTemplatesByCategory(ctx context.Context, categoryID string) (int, error) {
+	return s.budgets.CountTemplatesByCategory(ctx, categoryID)
+}
+
+type CreateBudgetTemplateInput struct {
+	Name      string                      `json:"name"`
+	AutoApply bool                        `json:"autoApply"`
+	Lines     []models.BudgetTemplateLine `json:"lines"`
+}
+
+func (s *BudgetService) CreateTemplate(ctx context.Context, in CreateBudgetTemplateInput) (models.BudgetTemplate, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	t := models.BudgetTemplate{
+		ID:        s.ids.NewID(),
+		Name:      strings.TrimSpace(in.Name),
+		AutoApply: in.AutoApply,
+		Lines:     in.Lines,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var out models.BudgetTemplate
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = s.budgets.CreateTemplate(ctx, t)
+		return err
+	})
+	return out, err
+}
+
+// UpdateBudgetTemplateInput replaces all lines when Lines is present.
+type UpdateBudgetTemplateInput struct {
+	Name      *string                      `json:"name"`
+	AutoApply *bool                        `json:"autoApply"`
+	Lines     *[]models.BudgetTemplateLine `json:"lines"`
+}
+
+func (s *BudgetService) UpdateTemplate(ctx context.Context, id string, in UpdateBudgetTemplateInput) (models.BudgetTemplate, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	patch := repositories.BudgetTemplatePatch{
+		AutoApply: in.AutoApply,
+		Lines:     in.Lines,
+		UpdatedAt: &now,
+	}
+	if in.Name != nil {
+		trimmed := strings.TrimSpace(*in.Name)
+		patch.Name = &trimmed
+	}
+	var out models.BudgetTemplate
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = s.budgets.UpdateTemplate(ctx, id, patch)
+		return err
+	})
+	return out, err
+}
+
+func (s *BudgetService) DeleteTemplate(ctx context.Context, id string) error {
+	return s.budgets.DeleteTemplate(ctx, id)
+}
@@ -32,7 +32,7 @@ func TestBudgetService_Upsert_CreatesAndUpdates(t *testing.T) {
 		UpdatedAt: clk.Now().Format(time.RFC3339),
 	})
 
-	svc := NewBudgetService(clk, ids, budgetRepo)
+	svc := NewBudgetService(clk, ids, repositories.NewGormTransactor(gdb), budgetRepo)
 
 	b1, err := svc.Upsert(ctx, UpsertBudgetInput{
 		Month:       "2026-01",
@@ -61,3 +61,111 @@ func TestBudgetService_Upsert_CreatesAndUpdates(t *testing.T) {
 		t.Fatalf("expected 50000, got %d", b2.AmountCents)
 	}
 }
+
+func TestBudgetService_Copy_SkipsOrOverwrites(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	for _, id := range []string{"cat-food", "cat-rent"} {
+		_, _ = catRepo.Create(ctx, models.Category{ID: id, Type: models.CategoryExpense, Name: id})
+	}
+	svc := NewBudgetService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormBudgetRepo(gdb))
+	for _, in := range []UpsertBudgetInput{
+		{Month: "2026-01", CategoryID: "cat-food", AmountCents: 300_00},
+		{Month: "2026-01", CategoryID: "cat-rent", AmountCents: 1000_00},
+		{Month: "2026-02", CategoryID: "cat-food", AmountCents: 250_00},
+	} {
+		if _, err := svc.Upsert(ctx, in); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+
+	res, err := svc.Copy(ctx, CopyBudgetsInput{FromMonth: "2026-01", ToMonth: "2026-02", Mode: models.BudgetCopySkip})
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if res.Created != 1 || res.Skipped != 1 || res.Updated != 0 || len(res.Budgets) != 2 {
+		t.Fatalf("unexpected skip result: %+v", res)
+	}
+	if food, _, _ := svc.budgets.FindByMonthCategory(ctx, "2026-02", "cat-food"); food.AmountCents != 250_00 {
+		t.Fatalf("skip mode changed an existing budget: %+v", food)
+	}
+
+	res, err = svc.Copy(ctx, CopyBudgetsInput{FromMonth: "2026-01", ToMonth: "2026-02", Mode: models.BudgetCopyOverwrite})
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if res.Created != 0 || res.Updated != 2 {
+		t.Fatalf("unexpected overwrite result: %+v", res)
+	}
+	if food, _, _ := svc.budgets.FindByMonthCategory(ctx, "2026-02", "cat-food"); food.AmountCents != 300_00 {
+		t.Fatalf("overwrite mode kept the old amount: %+v", food)
+	}
+}
+
+func TestBudgetService_AutoApplyTemplateOnFirstTransaction(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-rent", Type: models.CategoryExpense, Name: "Rent"})
+
+	budgets := NewBudgetService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormBudgetRepo(gdb))
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormTxnRepo(gdb))
+	txns.OnNewMonth(budgets.ApplyAutoTemplates)
+
+	_, err := budgets.CreateTemplate(ctx, CreateBudgetTemplateInput{
+		Name:      "Usual",
+		AutoApply: true,
+		Lines: []models.BudgetTemplateLine{
+			{CategoryID: "cat-food", AmountCents: 300_00, Currency: "IDR"},
+			{CategoryID: "cat-rent", AmountCents: 1000_00, Currency: "IDR"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	if _, err := budgets.Upsert(ctx, UpsertBudgetInput{Month: "2026-03", CategoryID: "cat-food", AmountCents: 50_00, Currency: "IDR"}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+
+	// A transfer earlier in the month doesn't make the expense any less the first.
+	_, err = repositories.NewGormTxnRepo(gdb).Create(ctx, models.Txn{
+		ID: "leg-out", Kind: models.KindTransfer, Date: "2026-03-01", AmountCents: 20_00, Currency: "IDR",
+		TransferID: "tr-1", TransferDirection: models.TransferOut, CreatedAt: "2026-01-02T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("create transfer leg: %v", err)
+	}
+	if _, err := txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-03-05", CategoryID: "cat-food", AmountCents: 10_00}); err != nil {
+		t.Fatalf("create txn: %v", err)
+	}
+	got, err := budgets.budgets.ListByMonth(ctx, "2026-03")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	amounts := map[string]int64{}
+	for _, b := range got {
+		amounts[b.CategoryID] = b.AmountCents
+	}
+	if len(amounts) != 2 || amounts["cat-food"] != 50_00 || amounts["cat-rent"] != 1000_00 {
+		t.Fatalf("unexpected budgets after first transaction: %+v", got)
+	}
+
+	// Later transactions leave the month alone, even after edits.
+	if _, err := budgets.Upsert(ctx, UpsertBudgetInput{Month: "2026-03", CategoryID: "cat-rent", AmountCents: 900_00, Currency: "IDR"}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if _, err := txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-03-06", CategoryID: "cat-food", AmountCents: 10_00}); err != nil {
+		t.Fatalf("create txn: %v", err)
+	}
+	if rent, _, _ := budgets.budgets.FindByMonthCategory(ctx, "2026-03", "cat-rent"); rent.AmountCents != 900_00 {
+		t.Fatalf("template re-applied on a later transaction: %+v", rent)
+	}
+}
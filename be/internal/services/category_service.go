@@ -26,32 +26,49 @@ func (s *CategoryService) List(ctx context.Context) ([]models.Category, error) {
 	return s.cats.List(ctx)
 }
 
+// Tree returns every category nested under its parent.
+func (s *CategoryService) Tree(ctx context.Context) ([]models.CategoryNode, error) {
+	cats, err := s.cats.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return BuildCategoryTree(cats), nil
+}
+
 func (s *CategoryService) Get(ctx context.Context, id string) (models.Category, error) {
 	return s.cats.Get(ctx, id)
 }
 
 type CreateCategoryInput struct {
-	Type        models.CategoryType `json:"type"`
-	Name        string              `json:"name"`
-	Description string              `json:"description,omitempty"`
+	Type           models.CategoryType   `json:"type"`
+	Name           string                `json:"name"`
+	Description    string                `json:"description,omitempty"`
+	ParentID       string                `json:"parentId,omitempty"`
+	RolloverPolicy models.RolloverPolicy `json:"rolloverPolicy,omitempty"` // default none
 }
 
 func (s *CategoryService) Create(ctx context.Context, in CreateCategoryInput) (models.Category, error) {
 	now := s.clk.Now().Format(time.RFC3339)
 	c := models.Category{
-		ID:          s.ids.NewID(),
-		Type:        in.Type,
-		Name:        strings.TrimSpace(in.Name),
-		Description: strings.TrimSpace(in.Description),
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:             s.ids.NewID(),
+		Type:           in.Type,
+		Name:           strings.TrimSpace(in.Name),
+		Description:    strings.TrimSpace(in.Description),
+		ParentID:       strings.TrimSpace(in.ParentID),
+		RolloverPolicy: in.RolloverPolicy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 	return s.cats.Create(ctx, c)
 }
 
 type UpdateCategoryInput struct {
-	Name        *string `json:"name"`
-	Description *string `json:"description"`
+	Name           *string                `json:"name"`
+	Description    *string                `json:"description"`
+	ParentID       *string                `json:"parentId"` // "" makes it top-level
+	RolloverPolicy *models.RolloverPolicy `json:"rolloverPolicy"`
+	// IfVersion comes from the If-Match header; see repositories.CategoryPatch.
+	IfVersion int64 `json:"-"`
 }
 
 func (s *CategoryService) Update(ctx context.Context, id string, in UpdateCategoryInput) (models.Category, error) {
@@ -64,8 +81,14 @@ func (s *CategoryService) Update(ctx context.Context, id string, in UpdateCatego
 		d := strings.TrimSpace(*in.Description)
 		patch.Description = &d
 	}
+	if in.ParentID != nil {
+		p := strings.TrimSpace(*in.ParentID)
+		patch.ParentID = &p
+	}
+	patch.RolloverPolicy = in.RolloverPolicy
 	now := s.clk.Now().Format(time.RFC3339)
 	patch.UpdatedAt = &now
+	patch.IfVersion = in.IfVersion
 	return s.cats.Update(ctx, id, patch)
 }
 
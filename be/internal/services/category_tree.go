@@ -0,0 +1,62 @@
+package services
+
+import "personal-budgeting/be/internal/models"
+
+// BuildCategoryTree nests categories under their parents, keeping the order of
+// cats at every level. A category whose parent is missing becomes a root.
+func BuildCategoryTree(cats []models.Category) []models.CategoryNode {
+	known := make(map[string]bool, len(cats))
+	for _, c := range cats {
+		known[c.ID] = true
+	}
+	children := map[string][]models.Category{}
+	var roots []models.Category
+	for _, c := range cats {
+		if c.ParentID == "" || !known[c.ParentID] {
+			roots = append(roots, c)
+			continue
+		}
+		children[c.ParentID] = append(children[c.ParentID], c)
+	}
+	var build func(level []models.Category) []models.CategoryNode
+	build = func(level []models.Category) []models.CategoryNode {
+		out := make([]models.CategoryNode, 0, len(level))
+		for _, c := range level {
+			out = append(out, models.CategoryNode{Category: c, Children: build(children[c.ID])})
+		}
+		return out
+	}
+	return build(roots)
+}
+
+// CreatesCycle reports whether making parentID the parent of id would put id
+// among its own ancestors.
+func CreatesCycle(cats []models.Category, id, parentID string) bool {
+	byID := make(map[string]models.Category, len(cats))
+	for _, c := range cats {
+		byID[c.ID] = c
+	}
+	if parentID == id {
+		return true
+	}
+	for _, a := range categoryAncestors(byID, parentID) {
+		if a == id {
+			return true
+		}
+	}
+	return false
+}
+
+// categoryAncestors lists id's parent, grandparent and so on up to the root.
+// It stops at a missing parent, and after len(byID) steps in case the stored
+// data already holds a cycle.
+func categoryAncestors(byID map[string]models.Category, id string) []string {
+	var out []string
+	for cur := byID[id].ParentID; cur != "" && len(out) < len(byID); cur = byID[cur].ParentID {
+		if _, ok := byID[cur]; !ok {
+			break
+		}
+		out = append(out, cur)
+	}
+	return out
+}
@@ -0,0 +1,13 @@
+package services
+
+import "time"
+
+// monthBounds returns the first and last YYYY-MM-DD keys of a YYYY-MM month.
+// The month is expected to be validated already.
+func monthBounds(month string) (string, string) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return month + "-01", month + "-31"
+	}
+	return start.Format("2006-01-02"), start.AddDate(0, 1, -1).Format("2006-01-02")
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/money"
+	"personal-budgeting/be/internal/repositories"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV   ExportFormat = "csv"
+	ExportJSONL ExportFormat = "jsonl"
+)
+
+// ExportService writes transactions straight from a database cursor, so large
+// exports never hold more than one row in memory.
+type ExportService struct {
+	cats repositories.CategoryRepository
+	txns repositories.TxnRepository
+}
+
+func NewExportService(cats repositories.CategoryRepository, txns repositories.TxnRepository) *ExportService {
+	return &ExportService{cats: cats, txns: txns}
+}
+
+type ExportInput struct {
+	Filter    repositories.TxnFilter
+	Format    ExportFormat
+	Delimiter rune               // CSV only; default ','
+	Decimal   money.DecimalStyle // CSV only; default comma
+}
+
+// exportLine is the JSON Lines row shape.
+type exportLine struct {
+	ID           string                 `json:"id"`
+	Date         string                 `json:"date"`
+	Kind         models.TransactionKind `json:"kind"`
+	CategoryID   string                 `json:"categoryId"`
+	CategoryName string                 `json:"categoryName"`
+	AmountCents  int64                  `json:"amountCents"`
+	Amount       string                 `json:"amount"`
+	Currency     string                 `json:"currency"`
+	Note         string                 `json:"note"`
+
+	TransferDirection models.TransferDirection `json:"transferDirection,omitempty"`
+}
+
+// Export writes every transaction matching in.Filter to w. In CSV the amount is
+// signed (expenses and outgoing transfers negative) so spreadsheet sums give the net.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, in ExportInput) error {
+	cats, err := s.cats.List(ctx)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(cats))
+	for _, c := range cats {
+		names[c.ID] = c.Name
+	}
+
+	if in.Format == ExportJSONL {
+		enc := json.NewEncoder(w)
+		return s.txns.Each(ctx, in.Filter, func(t models.Txn) error {
+			return enc.Encode(exportLine{
+				ID:           t.ID,
+				Date:         t.Date,
+				Kind:         t.Kind,
+				CategoryID:   t.CategoryID,
+				CategoryName: names[t.CategoryID],
+				AmountCents:  t.AmountCents,
+				Amount:       money.FormatCents(t.AmountCents, money.DecimalDot),
+				Currency:     t.Currency,
+				Note:         t.Note,
+
+				TransferDirection: t.TransferDirection,
+			})
+		})
+	}
+
+	cw := csv.NewWriter(w)
+	if in.Delimiter != 0 {
+		cw.Comma = in.Delimiter
+	}
+	if in.Decimal == "" {
+		in.Decimal = money.DecimalComma
+	}
+	if err := cw.Write([]string{"date", "kind", "category", "amount", "currency", "note", "id"}); err != nil {
+		return err
+	}
+	err = s.txns.Each(ctx, in.Filter, func(t models.Txn) error {
+		if err := cw.Write([]string{t.Date, string(t.Kind), names[t.CategoryID], money.FormatCents(signedAmount(t), in.Decimal), t.Currency, t.Note, t.ID}); err != nil {
+			return err
+		}
+		return cw.Error()
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"personal-budgeting/be/internal/imports"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/money"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// ImportService validates parsed import candidates and commits them through TxnService.
+// It is format-agnostic: parsers in internal/imports produce the candidates.
+type ImportService struct {
+	tx     repositories.Transactor
+	cats   repositories.CategoryRepository
+	txns   *TxnService
+	rules  *RuleService
+	payees *PayeeService
+}
+
+func NewImportService(tx repositories.Transactor, cats repositories.CategoryRepository, txns *TxnService, rules *RuleService, payees *PayeeService) *ImportService {
+	return &ImportService{tx: tx, cats: cats, txns: txns, rules: rules, payees: payees}
+}
+
+// ImportOptions chooses categories for rows. Precedence: RowCategories, then the
+// category named in the file, then the first matching rule, then the default for
+// the row's kind. Every row is in Currency.
+type ImportOptions struct {
+	Currency                 string         `json:"currency,omitempty"`
+	DefaultIncomeCategoryID  string         `json:"defaultIncomeCategoryId,omitempty"`
+	DefaultExpenseCategoryID string         `json:"defaultExpenseCategoryId,omitempty"`
+	RowCategories            map[int]string `json:"rowCategories,omitempty"`
+}
+
+// Preview validates candidates without writing anything.
+func (s *ImportService) Preview(ctx context.Context, cands []imports.Candidate, opts ImportOptions) (models.ImportResult, error) {
+	return s.run(ctx, cands, opts, true)
+}
+
+// Commit creates a transaction for every valid candidate. Invalid rows and rows whose
+// ExternalID was already imported are reported and skipped, so re-importing is safe.
+// The rows are created in one database transaction: if one fails, none is kept.
+func (s *ImportService) Commit(ctx context.Context, cands []imports.Candidate, opts ImportOptions) (models.ImportResult, error) {
+	var out models.ImportResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = s.run(ctx, cands, opts, false)
+		return err
+	})
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+	return out, nil
+}
+
+func (s *ImportService) run(ctx context.Context, cands []imports.Candidate, opts ImportOptions, dryRun bool) (models.ImportResult, error) {
+	resolver, err := s.newCategoryResolver(ctx)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+	eng, err := s.rules.Engine(ctx)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+	matcher, err := s.payees.Matcher(ctx)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+
+	var extIDs []string
+	for _, c := range cands {
+		if c.ExternalID != "" {
+			extIDs = append(extIDs, c.ExternalID)
+		}
+	}
+	seen, err := s.txns.ExistingExternalIDs(ctx, extIDs)
+	if err != nil {
+		return models.ImportResult{}, err
+	}
+
+	ccy, _ := money.LookupCurrency(opts.Currency)
+
+	out := models.ImportResult{DryRun: dryRun, Total: len(cands), Rows: make([]models.ImportRow, 0, len(cands))}
+	for _, c := range cands {
+		row := models.ImportRow{
+			Row:         c.Row,
+			Kind:        c.Kind,
+			Date:        c.Date,
+			AmountCents: c.AmountCents,
+			Note:        c.Note,
+			ExternalID:  c.ExternalID,
+			Errors:      append([]string(nil), c.Errors...),
+		}
+		if c.ExternalID != "" && seen[c.ExternalID] {
+			row.Status = models.ImportRowDuplicate
+			out.Duplicates++
+			out.Rows = append(out.Rows, row)
+			continue
+		}
+		if len(row.Errors) == 0 && !ccy.ValidCents(row.AmountCents) {
+			row.Errors = append(row.Errors, fmt.Sprintf("amount has more decimals than %s allows", opts.Currency))
+		}
+		if len(row.Errors) == 0 {
+			if p, ok := matcher.Match(c.Note); ok {
+				row.PayeeID = p.ID
+			}
+			ruled := eng.Apply(models.Txn{Kind: c.Kind, AmountCents: c.AmountCents, Note: c.Note})
+			row.Note, row.TagIDs = ruled.Note, ruled.TagIDs
+			catID, err := resolver.resolve(c, ruled.CategoryID, opts)
+			if err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			}
+			row.CategoryID = catID
+		}
+		if len(row.Errors) > 0 {
+			row.Status = models.ImportRowInvalid
+			out.Invalid++
+			out.Rows = append(out.Rows, row)
+			continue
+		}
+
+		row.Status = models.ImportRowOK
+		out.Valid++
+		if c.ExternalID != "" {
+			// Also skips repeats within the same file.
+			seen[c.ExternalID] = true
+		}
+		if !dryRun {
+			t, err := s.txns.Create(ctx, CreateTxnInput{
+				Kind:        row.Kind,
+				Date:        row.Date,
+				CategoryID:  row.CategoryID,
+				AmountCents: row.AmountCents,
+				Currency:    opts.Currency,
+				Note:        row.Note,
+				ExternalID:  row.ExternalID,
+				PayeeID:     row.PayeeID,
+				TagIDs:      row.TagIDs,
+			})
+			if err != nil {
+				return models.ImportResult{}, err
+			}
+			row.Status = models.ImportRowImported
+			row.TxnID = t.ID
+			out.Imported++
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return out, nil
+}
+
+type categoryResolver struct {
+	byID   map[string]models.Category
+	byName map[string][]models.Category // lowercased name
+}
+
+func (s *ImportService) newCategoryResolver(ctx context.Context) (categoryResolver, error) {
+	cats, err := s.cats.List(ctx)
+	if err != nil {
+		return categoryResolver{}, err
+	}
+	r := categoryResolver{byID: map[string]models.Category{}, byName: map[string][]models.Category{}}
+	for _, c := range cats {
+		r.byID[c.ID] = c
+		key := strings.ToLower(c.Name)
+		r.byName[key] = append(r.byName[key], c)
+	}
+	return r, nil
+}
+
+// resolve picks the row's category; ruleCatID is what the rules chose, if anything.
+func (r categoryResolver) resolve(c imports.Candidate, ruleCatID string, opts ImportOptions) (string, error) {
+	want := models.CategoryExpense
+	if c.Kind == models.KindIncome {
+		want = models.CategoryIncome
+	}
+
+	ref := strings.TrimSpace(opts.RowCategories[c.Row])
+	if ref == "" {
+		ref = strings.TrimSpace(c.Category)
+	}
+	if ref == "" {
+		ref = ruleCatID
+	}
+	if ref == "" {
+		if c.Kind == models.KindIncome {
+			ref = opts.DefaultIncomeCategoryID
+		} else {
+			ref = opts.DefaultExpenseCategoryID
+		}
+	}
+	if ref == "" {
+		return "", fmt.Errorf("no %s category chosen", want)
+	}
+
+	if cat, ok := r.byID[ref]; ok {
+		if cat.Type != want {
+			return "", fmt.Errorf("category %q is not an %s category", cat.Name, want)
+		}
+		return cat.ID, nil
+	}
+	for _, cat := range r.byName[strings.ToLower(ref)] {
+		if cat.Type == want {
+			return cat.ID, nil
+		}
+	}
+	return "", fmt.Errorf("unknown %s category %q", want, ref)
+}
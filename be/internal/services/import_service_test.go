@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/imports"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/testutil"
+)
+
+func TestImportService_FailingNewMonthHookKeepsImport(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	tx := repositories.NewGormTransactor(gdb)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Food"})
+
+	txns := NewTxnService(clk, ids, tx, txnRepo)
+	// The hook writes before failing; its write must be undone, the import's kept.
+	txns.OnNewMonth(func(ctx context.Context, month string) error {
+		if _, err := catRepo.Create(ctx, models.Category{ID: "cat-hook", Type: models.CategoryExpense, Name: "Hook"}); err != nil {
+			return err
+		}
+		return errors.New("hook failed")
+	})
+	rules := NewRuleService(clk, ids, tx, repositories.NewGormRuleRepo(gdb), catRepo, repositories.NewGormTagRepo(gdb), txnRepo)
+	payees := NewPayeeService(clk, ids, tx, repositories.NewGormPayeeRepo(gdb), txnRepo)
+	svc := NewImportService(tx, catRepo, txns, rules, payees)
+
+	res, err := svc.Commit(ctx, []imports.Candidate{
+		{Row: 1, Kind: models.KindExpense, Date: "2026-03-05", AmountCents: 10_00, Category: "cat-food"},
+		{Row: 2, Kind: models.KindExpense, Date: "2026-03-06", AmountCents: 20_00, Category: "cat-food"},
+	}, ImportOptions{})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if res.Imported != 2 {
+		t.Fatalf("expected 2 imported, got %+v", res)
+	}
+	got, err := txnRepo.List(ctx)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("expected the import committed, got %d (%v)", len(got), err)
+	}
+	if _, err := catRepo.Get(ctx, "cat-hook"); !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("expected the failed hook's write rolled back, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+type PayeeService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	tx     repositories.Transactor
+	payees repositories.PayeeRepository
+	txns   repositories.TxnRepository
+}
+
+func NewPayeeService(clk clock.Clock, ids id.Generator, tx repositories.Transactor, payees repositories.PayeeRepository, txns repositories.TxnRepository) *PayeeService {
+	return &PayeeService{clk: clk, ids: ids, tx: tx, payees: payees, txns: txns}
+}
+
+// NormalizePayee reduces raw text to the form aliases are stored and matched
+// in: lowercase words of letters and digits, with number-only words such as
+// store or terminal numbers dropped. "INDOMARET 123" becomes "indomaret".
+func NormalizePayee(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if strings.IndexFunc(w, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+			out = append(out, w)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func (s *PayeeService) List(ctx context.Context) ([]models.Payee, error) {
+	return s.payees.List(ctx)
+}
+
+func (s *PayeeService) Get(ctx context.Context, id string) (models.Payee, error) {
+	return s.payees.Get(ctx, id)
+}
+
+// CreatePayeeInput takes aliases already normalized.
+type CreatePayeeInput struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+// Create fails with errs.ErrConflict when the name or an alias already belongs
+// to another payee.
+func (s *PayeeService) Create(ctx context.Context, in CreatePayeeInput) (models.Payee, error) {
+	var out models.Payee
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p := models.Payee{ID: s.ids.NewID(), Name: strings.TrimSpace(in.Name), Aliases: payeeAliases(in.Name, in.Aliases)}
+		if err := s.checkUnique(ctx, p); err != nil {
+			return err
+		}
+		now := s.clk.Now().Format(time.RFC3339)
+		p.CreatedAt, p.UpdatedAt = now, now
+		var err error
+		out, err = s.payees.Create(ctx, p)
+		return err
+	})
+	return out, err
+}
+
+// UpdatePayeeInput replaces every alias when Aliases is present.
+type UpdatePayeeInput struct {
+	Name    *string   `json:"name"`
+	Aliases *[]string `json:"aliases"`
+}
+
+func (s *PayeeService) Update(ctx context.Context, id string, in UpdatePayeeInput) (models.Payee, error) {
+	var out models.Payee
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		p, err := s.payees.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		now := s.clk.Now().Format(time.RFC3339)
+		patch := repositories.PayeePatch{UpdatedAt: &now}
+		if in.Name != nil {
+			p.Name = strings.TrimSpace(*in.Name)
+			patch.Name = &p.Name
+		}
+		if in.Aliases != nil {
+			p.Aliases = *in.Aliases
+		}
+		if in.Name != nil || in.Aliases != nil {
+			p.Aliases = payeeAliases(p.Name, p.Aliases)
+			patch.Aliases = &p.Aliases
+		}
+		if err := s.checkUnique(ctx, p); err != nil {
+			return err
+		}
+		out, err = s.payees.Update(ctx, id, patch)
+		return err
+	})
+	return out, err
+}
+
+// Delete clears the payee from its transactions, through the transaction
+// repository so the change is versioned and audited, then deletes it.
+func (s *PayeeService) Delete(ctx context.Context, id string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.txns.ReassignPayee(ctx, []string{id}, ""); err != nil {
+			return err
+		}
+		return s.payees.Delete(ctx, id)
+	})
+}
+
+// Merge folds sources into the target payee in one transaction: their
+// transactions move over, their names and aliases become aliases of the target,
+// and they are deleted.
+func (s *PayeeService) Merge(ctx context.Context, targetID string, sourceIDs []string) (models.Payee, error) {
+	var out models.Payee
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		target, err := s.payees.Get(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		aliases := target.Aliases
+		for _, id := range sourceIDs {
+			src, err := s.payees.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			aliases = append(aliases, NormalizePayee(src.Name))
+			aliases = append(aliases, src.Aliases...)
+		}
+		if err := s.txns.ReassignPayee(ctx, sourceIDs, targetID); err != nil {
+			return err
+		}
+		for _, id := range sourceIDs {
+			if err := s.payees.Delete(ctx, id); err != nil {
+				return err
+			}
+		}
+		aliases = payeeAliases(target.Name, aliases)
+		now := s.clk.Now().Format(time.RFC3339)
+		out, err = s.payees.Update(ctx, targetID, repositories.PayeePatch{Aliases: &aliases, UpdatedAt: &now})
+		return err
+	})
+	return out, err
+}
+
+// checkUnique requires p's normalized name and aliases to be free among the
+// user's other payees.
+func (s *PayeeService) checkUnique(ctx context.Context, p models.Payee) error {
+	all, err := s.payees.List(ctx)
+	if err != nil {
+		return err
+	}
+	keys := payeeKeys(p)
+	for _, other := range all {
+		if other.ID == p.ID {
+			continue
+		}
+		for _, k := range payeeKeys(other) {
+			if slices.Contains(keys, k) {
+				return errs.ErrConflict
+			}
+		}
+	}
+	return nil
+}
+
+// Matcher snapshots the user's payees for matching raw note text.
+func (s *PayeeService) Matcher(ctx context.Context) (*PayeeMatcher, error) {
+	payees, err := s.payees.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewPayeeMatcher(payees), nil
+}
+
+// PayeeMatcher finds the payee behind raw text. A payee matches when its
+// normalized name or one of its aliases appears in the normalized text as whole
+// words; the longest such key wins, so "indomaret kemang" beats "indomaret".
+type PayeeMatcher struct {
+	keys []payeeKey
+}
+
+type payeeKey struct {
+	key   string
+	payee models.Payee
+}
+
+func NewPayeeMatcher(payees []models.Payee) *PayeeMatcher {
+	m := &PayeeMatcher{}
+	for _, p := range payees {
+		for _, k := range payeeKeys(p) {
+			m.keys = append(m.keys, payeeKey{key: k, payee: p})
+		}
+	}
+	// Longest key first; the sort is stable, so ties keep payees in name order.
+	slices.SortStableFunc(m.keys, func(a, b payeeKey) int { return len(b.key) - len(a.key) })
+	return m
+}
+
+// Match returns the payee for text, if any.
+func (m *PayeeMatcher) Match(text string) (models.Payee, bool) {
+	norm := NormalizePayee(text)
+	if norm == "" {
+		return models.Payee{}, false
+	}
+	padded := " " + norm + " "
+	for _, k := range m.keys {
+		if strings.Contains(padded, " "+k.key+" ") {
+			return k.payee, true
+		}
+	}
+	return models.Payee{}, false
+}
+
+// payeeKeys lists what a payee is matched by: its normalized name and aliases.
+func payeeKeys(p models.Payee) []string {
+	keys := make([]string, 0, len(p.Aliases)+1)
+	if n := NormalizePayee(p.Name); n != "" {
+		keys = append(keys, n)
+	}
+	return append(keys, p.Aliases...)
+}
+
+// payeeAliases de-duplicates aliases and drops empty ones and the one equal to
+// the normalized name, which matches anyway.
+func payeeAliases(name string, aliases []string) []string {
+	self := NormalizePayee(name)
+	out := make([]string, 0, len(aliases))
+	for _, a := range aliases {
+		if a == "" || a == self || slices.Contains(out, a) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
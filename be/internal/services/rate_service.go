@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// RateService manages the locally maintained exchange-rate table that reports
+// convert with. Rates are entered by hand (or by a script); nothing is fetched.
+type RateService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	rates repositories.RateRepository
+}
+
+func NewRateService(clk clock.Clock, ids id.Generator, rates repositories.RateRepository) *RateService {
+	return &RateService{clk: clk, ids: ids, rates: rates}
+}
+
+func (s *RateService) List(ctx context.Context, f repositories.RateFilter) ([]models.ExchangeRate, error) {
+	return s.rates.List(ctx, f)
+}
+
+type UpsertRateInput struct {
+	Date  string  `json:"date"`
+	Base  string  `json:"base"`
+	Quote string  `json:"quote"`
+	Rate  float64 `json:"rate"`
+}
+
+// Upsert stores the rate for (base, quote, date), replacing any earlier value for that day.
+func (s *RateService) Upsert(ctx context.Context, in UpsertRateInput) (models.ExchangeRate, error) {
+	return s.rates.Upsert(ctx, models.ExchangeRate{
+		ID:        s.ids.NewID(),
+		Date:      in.Date,
+		Base:      in.Base,
+		Quote:     in.Quote,
+		Rate:      in.Rate,
+		CreatedAt: s.clk.Now().Format(time.RFC3339),
+	})
+}
+
+func (s *RateService) Delete(ctx context.Context, id string) error {
+	return s.rates.Delete(ctx, id)
+}
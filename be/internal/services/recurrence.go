@@ -0,0 +1,102 @@
+package services
+
+import (
+	"time"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/validate"
+)
+
+const dateLayout = "2006-01-02"
+
+// ValidRecurringSchedule checks the frequency, its parameter and the date range.
+func ValidRecurringSchedule(r models.RecurringRule) bool {
+	if !r.Frequency.Valid() || !validate.DateKey(r.StartDate) {
+		return false
+	}
+	if r.EndDate != "" && (!validate.DateKey(r.EndDate) || r.EndDate < r.StartDate) {
+		return false
+	}
+	switch r.Frequency {
+	case models.FreqMonthly:
+		return r.DayOfMonth >= 1 && r.DayOfMonth <= 31 && r.IntervalDays == 0
+	case models.FreqEveryNDays:
+		return r.IntervalDays >= 1 && r.IntervalDays <= 366 && r.DayOfMonth == 0
+	}
+	return r.DayOfMonth == 0 && r.IntervalDays == 0
+}
+
+// occurrences lists the rule's dates after `after` (exclusive; "" means from the
+// start) up to `through` (inclusive; "" means no bound), stopping after limit
+// dates when limit > 0. The rule's own start and end dates always apply.
+func occurrences(r models.RecurringRule, after string, through string, limit int) []string {
+	start, err := time.Parse(dateLayout, r.StartDate)
+	if err != nil {
+		return nil
+	}
+	end := r.EndDate
+	if through != "" && (end == "" || through < end) {
+		end = through
+	}
+	if end == "" && limit <= 0 {
+		return nil
+	}
+
+	var out []string
+	for i := 0; ; i++ {
+		d, ok := nthCandidate(r, start, i)
+		if !ok {
+			return out
+		}
+		key := d.Format(dateLayout)
+		if end != "" && key > end {
+			return out
+		}
+		if key < r.StartDate || key <= after {
+			continue
+		}
+		out = append(out, key)
+		if limit > 0 && len(out) == limit {
+			return out
+		}
+	}
+}
+
+// nthCandidate returns the i-th scheduled date counting from start's period.
+// Candidates never decrease; early ones may fall before start and are skipped.
+func nthCandidate(r models.RecurringRule, start time.Time, i int) (time.Time, bool) {
+	switch r.Frequency {
+	case models.FreqMonthly:
+		if r.DayOfMonth < 1 {
+			return time.Time{}, false
+		}
+		return dayInMonth(start.Year(), start.Month()+time.Month(i), r.DayOfMonth), true
+	case models.FreqLastBusinessDay:
+		d := dayInMonth(start.Year(), start.Month()+time.Month(i), 31)
+		for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			d = d.AddDate(0, 0, -1)
+		}
+		return d, true
+	case models.FreqYearly:
+		return dayInMonth(start.Year()+i, start.Month(), start.Day()), true
+	case models.FreqWeekly:
+		return start.AddDate(0, 0, 7*i), true
+	case models.FreqEveryNDays:
+		if r.IntervalDays < 1 {
+			return time.Time{}, false
+		}
+		return start.AddDate(0, 0, r.IntervalDays*i), true
+	}
+	return time.Time{}, false
+}
+
+// dayInMonth is day of the given month, clamped to the month's last day.
+// month may overflow past December; time.Date normalises it.
+func dayInMonth(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
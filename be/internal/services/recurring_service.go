@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// RecurringService stores recurring rules and turns their due occurrences into
+// transactions. Materializing is idempotent: each occurrence has a fixed
+// ExternalID, so a rerun (or a second process) never duplicates it.
+type RecurringService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	tx    repositories.Transactor
+	rules repositories.RecurringRuleRepository
+	txns  *TxnService
+}
+
+func NewRecurringService(clk clock.Clock, ids id.Generator, tx repositories.Transactor, rules repositories.RecurringRuleRepository, txns *TxnService) *RecurringService {
+	return &RecurringService{clk: clk, ids: ids, tx: tx, rules: rules, txns: txns}
+}
+
+// MaxRecurringPreview caps how many upcoming dates Preview returns.
+const MaxRecurringPreview = 100
+
+func (s *RecurringService) List(ctx context.Context) ([]models.RecurringRule, error) {
+	return s.rules.List(ctx)
+}
+
+func (s *RecurringService) Get(ctx context.Context, id string) (models.RecurringRule, error) {
+	return s.rules.Get(ctx, id)
+}
+
+type CreateRecurringRuleInput struct {
+	Name         string                    `json:"name"`
+	Kind         models.TransactionKind    `json:"kind"`
+	CategoryID   string                    `json:"categoryId"`
+	AccountID    string                    `json:"accountId,omitempty"`
+	AmountCents  int64                     `json:"amountCents"`
+	Currency     string                    `json:"currency,omitempty"`
+	Note         string                    `json:"note,omitempty"`
+	Frequency    models.RecurringFrequency `json:"frequency"`
+	DayOfMonth   int                       `json:"dayOfMonth,omitempty"`
+	IntervalDays int                       `json:"intervalDays,omitempty"`
+	StartDate    string                    `json:"startDate"`
+	EndDate      string                    `json:"endDate,omitempty"`
+}
+
+// Rule returns the rule the input describes, without ID or timestamps.
+func (in CreateRecurringRuleInput) Rule() models.RecurringRule {
+	return models.RecurringRule{
+		Name:         strings.TrimSpace(in.Name),
+		Kind:         in.Kind,
+		CategoryID:   strings.TrimSpace(in.CategoryID),
+		AccountID:    strings.TrimSpace(in.AccountID),
+		AmountCents:  in.AmountCents,
+		Currency:     in.Currency,
+		Note:         strings.TrimSpace(in.Note),
+		Frequency:    in.Frequency,
+		DayOfMonth:   in.DayOfMonth,
+		IntervalDays: in.IntervalDays,
+		StartDate:    in.StartDate,
+		EndDate:      in.EndDate,
+	}
+}
+
+func (s *RecurringService) Create(ctx context.Context, in CreateRecurringRuleInput) (models.RecurringRule, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	r := in.Rule()
+	r.ID = s.ids.NewID()
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	return s.rules.Create(ctx, r)
+}
+
+// UpdateRecurringRuleInput changes the template or pauses a rule. The schedule
+// itself is fixed; replace the rule to change it.
+type UpdateRecurringRuleInput struct {
+	Name        *string `json:"name"`
+	CategoryID  *string `json:"categoryId"`
+	AmountCents *int64  `json:"amountCents"`
+	Note        *string `json:"note"`
+	EndDate     *string `json:"endDate"` // "" removes the end date
+	Paused      *bool   `json:"paused"`
+}
+
+// Apply returns r with the patch applied, for validating the result.
+func (in UpdateRecurringRuleInput) Apply(r models.RecurringRule) models.RecurringRule {
+	if in.Name != nil {
+		r.Name = strings.TrimSpace(*in.Name)
+	}
+	if in.CategoryID != nil {
+		r.CategoryID = strings.TrimSpace(*in.CategoryID)
+	}
+	if in.AmountCents != nil {
+		r.AmountCents = *in.AmountCents
+	}
+	if in.Note != nil {
+		r.Note = strings.TrimSpace(*in.Note)
+	}
+	if in.EndDate != nil {
+		r.EndDate = strings.TrimSpace(*in.EndDate)
+	}
+	if in.Paused != nil {
+		r.Paused = *in.Paused
+	}
+	return r
+}
+
+func (s *RecurringService) Update(ctx context.Context, id string, in UpdateRecurringRuleInput) (models.RecurringRule, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	patch := repositories.RecurringRulePatch{
+		AmountCents: in.AmountCents,
+		Paused:      in.Paused,
+		UpdatedAt:   &now,
+	}
+	if in.Name != nil {
+		trimmed := strings.TrimSpace(*in.Name)
+		patch.Name = &trimmed
+	}
+	if in.CategoryID != nil {
+		trimmed := strings.TrimSpace(*in.CategoryID)
+		patch.CategoryID = &trimmed
+	}
+	if in.Note != nil {
+		trimmed := strings.TrimSpace(*in.Note)
+		patch.Note = &trimmed
+	}
+	if in.EndDate != nil {
+		trimmed := strings.TrimSpace(*in.EndDate)
+		patch.EndDate = &trimmed
+	}
+	return s.rules.Update(ctx, id, patch)
+}
+
+// Delete removes the rule; transactions it already created stay.
+func (s *RecurringService) Delete(ctx context.Context, id string) error {
+	return s.rules.Delete(ctx, id)
+}
+
+func (s *RecurringService) CountByCategory(ctx context.Context, categoryID string) (int, error) {
+	return s.rules.CountByCategory(ctx, categoryID)
+}
+
+func (s *RecurringService) CountByAccount(ctx context.Context, accountID string) (int, error) {
+	return s.rules.CountByAccount(ctx, accountID)
+}
+
+// Preview lists up to count occurrence dates from today on, whether or not they
+// have been materialized yet. Paused rules still show their schedule.
+func (s *RecurringService) Preview(ctx context.Context, id string, count int) (models.RecurringPreview, error) {
+	r, err := s.rules.Get(ctx, id)
+	if err != nil {
+		return models.RecurringPreview{}, err
+	}
+	yesterday := s.clk.Now().AddDate(0, 0, -1).Format(dateLayout)
+	dates := occurrences(r, yesterday, "", count)
+	if dates == nil {
+		dates = []string{}
+	}
+	return models.RecurringPreview{RuleID: r.ID, Dates: dates}, nil
+}
+
+// occurrenceExternalID is the ExternalID of the transaction for one occurrence.
+func occurrenceExternalID(ruleID string, date string) string {
+	return fmt.Sprintf("recurring:%s:%s", ruleID, date)
+}
+
+// Materialize creates transactions for every occurrence of the user's unpaused
+// rules up to and including today. It returns how many it created. A failing
+// rule doesn't stop the others; their errors are joined.
+func (s *RecurringService) Materialize(ctx context.Context) (int, error) {
+	today := s.clk.Now().Format(dateLayout)
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	var errs []error
+	for _, r := range rules {
+		if r.Paused || r.LastRunDate >= today {
+			continue
+		}
+		n, err := s.materializeRule(ctx, r, today)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recurring rule %s: %w", r.ID, err))
+			continue
+		}
+		created += n
+	}
+	return created, errors.Join(errs...)
+}
+
+func (s *RecurringService) materializeRule(ctx context.Context, r models.RecurringRule, today string) (int, error) {
+	dates := occurrences(r, r.LastRunDate, today, 0)
+	created := 0
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		created = 0
+		extIDs := make([]string, 0, len(dates))
+		for _, d := range dates {
+			extIDs = append(extIDs, occurrenceExternalID(r.ID, d))
+		}
+		seen, err := s.txns.ExistingExternalIDs(ctx, extIDs)
+		if err != nil {
+			return err
+		}
+		for i, d := range dates {
+			if seen[extIDs[i]] {
+				continue
+			}
+			_, err := s.txns.Create(ctx, CreateTxnInput{
+				Kind:        r.Kind,
+				Date:        d,
+				CategoryID:  r.CategoryID,
+				AccountID:   r.AccountID,
+				AmountCents: r.AmountCents,
+				Currency:    r.Currency,
+				Note:        r.Note,
+				ExternalID:  extIDs[i],
+			})
+			if err != nil {
+				return err
+			}
+			created++
+		}
+		now := s.clk.Now().Format(time.RFC3339)
+		_, err = s.rules.Update(ctx, r.ID, repositories.RecurringRulePatch{LastRunDate: &today, UpdatedAt: &now})
+		return err
+	})
+	return created, err
+}
+
+// RunDue materializes due occurrences for every user that has any.
+func (s *RecurringService) RunDue(ctx context.Context) (int, error) {
+	owners, err := s.rules.DueOwners(ctx, s.clk.Now().Format(dateLayout))
+	if err != nil {
+		return 0, err
+	}
+	created := 0
+	var errs []error
+	for _, uid := range owners {
+		n, err := s.Materialize(auth.WithActor(auth.WithUserID(ctx, uid), "recurring"))
+		created += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return created, errors.Join(errs...)
+}
+
+// Run calls RunDue now and then every interval until ctx is cancelled. It is
+// meant to be started in its own goroutine by the API process.
+func (s *RecurringService) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if n, err := s.RunDue(ctx); err != nil {
+			log.Printf("recurring: %v", err)
+		} else if n > 0 {
+			log.Printf("recurring: created %d transactions", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/testutil"
+)
+
+func TestOccurrences(t *testing.T) {
+	cases := []struct {
+		name    string
+		rule    models.RecurringRule
+		after   string
+		through string
+		limit   int
+		want    []string
+	}{
+		{
+			name:    "monthly on the 31st clamps to short months",
+			rule:    models.RecurringRule{Frequency: models.FreqMonthly, DayOfMonth: 31, StartDate: "2026-01-15"},
+			through: "2026-04-30",
+			want:    []string{"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30"},
+		},
+		{
+			name:    "monthly stops at the end date",
+			rule:    models.RecurringRule{Frequency: models.FreqMonthly, DayOfMonth: 31, StartDate: "2026-01-15", EndDate: "2026-03-15"},
+			through: "2026-12-31",
+			want:    []string{"2026-01-31", "2026-02-28"},
+		},
+		{
+			name:    "last business day skips weekends",
+			rule:    models.RecurringRule{Frequency: models.FreqLastBusinessDay, StartDate: "2026-01-01"},
+			through: "2026-03-31",
+			want:    []string{"2026-01-30", "2026-02-27", "2026-03-31"},
+		},
+		{
+			name:    "yearly from a leap day",
+			rule:    models.RecurringRule{Frequency: models.FreqYearly, StartDate: "2024-02-29"},
+			through: "2027-03-01",
+			want:    []string{"2024-02-29", "2025-02-28", "2026-02-28", "2027-02-28"},
+		},
+		{
+			name:  "weekly with a limit",
+			rule:  models.RecurringRule{Frequency: models.FreqWeekly, StartDate: "2026-01-01"},
+			limit: 3,
+			want:  []string{"2026-01-01", "2026-01-08", "2026-01-15"},
+		},
+		{
+			name:    "every n days after a previous run",
+			rule:    models.RecurringRule{Frequency: models.FreqEveryNDays, IntervalDays: 10, StartDate: "2026-01-01"},
+			after:   "2026-01-15",
+			through: "2026-02-10",
+			want:    []string{"2026-01-21", "2026-01-31", "2026-02-10"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := occurrences(tc.rule, tc.after, tc.through, tc.limit)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRecurringService_RunDueIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+	ruleRepo := repositories.NewGormRecurringRuleRepo(gdb)
+	tx := repositories.NewGormTransactor(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-rent", Type: models.CategoryExpense, Name: "Rent"})
+
+	at := func(date string) *RecurringService {
+		d, _ := time.Parse("2006-01-02", date)
+		clk := testutil.FixedClock{T: d.Add(9 * time.Hour)}
+		return NewRecurringService(clk, ids, tx, ruleRepo, NewTxnService(clk, ids, tx, txnRepo))
+	}
+
+	rule, err := at("2026-01-01").Create(ctx, CreateRecurringRuleInput{
+		Name: "Rent", Kind: models.KindExpense, CategoryID: "cat-rent", AmountCents: 3_000_000_00,
+		Frequency: models.FreqMonthly, DayOfMonth: 1, StartDate: "2026-01-01",
+	})
+	if err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	// First run on 15 March catches up January to March.
+	if n, err := at("2026-03-15").RunDue(ctx); err != nil || n != 3 {
+		t.Fatalf("first run: n=%d err=%v", n, err)
+	}
+	// Running again the same day, or after LastRunDate is lost, adds nothing.
+	if n, err := at("2026-03-15").RunDue(ctx); err != nil || n != 0 {
+		t.Fatalf("second run: n=%d err=%v", n, err)
+	}
+	empty := ""
+	if _, err := ruleRepo.Update(ctx, rule.ID, repositories.RecurringRulePatch{LastRunDate: &empty}); err != nil {
+		t.Fatalf("reset last run: %v", err)
+	}
+	if n, err := at("2026-03-20").RunDue(ctx); err != nil || n != 0 {
+		t.Fatalf("rerun after reset: n=%d err=%v", n, err)
+	}
+	if n, err := at("2026-04-01").RunDue(ctx); err != nil || n != 1 {
+		t.Fatalf("april run: n=%d err=%v", n, err)
+	}
+
+	all, err := txnRepo.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 4 || all[0].ExternalID != "recurring:"+rule.ID+":2026-01-01" {
+		t.Fatalf("unexpected transactions: %+v", all)
+	}
+
+	preview, err := at("2026-04-02").Preview(ctx, rule.ID, 2)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if !reflect.DeepEqual(preview.Dates, []string{"2026-05-01", "2026-06-01"}) {
+		t.Fatalf("unexpected preview: %v", preview.Dates)
+	}
+}
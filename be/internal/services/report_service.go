@@ -0,0 +1,507 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+type ReportService struct {
+	reports repositories.ReportRepository
+	cats    repositories.CategoryRepository
+
+	// home is the currency reports convert to unless the caller asks for another.
+	home string
+}
+
+func NewReportService(reports repositories.ReportRepository, cats repositories.CategoryRepository, homeCurrency string) *ReportService {
+	if homeCurrency == "" {
+		homeCurrency = models.DefaultCurrency
+	}
+	return &ReportService{reports: reports, cats: cats, home: homeCurrency}
+}
+
+func (s *ReportService) currency(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return s.home
+}
+
+// ReportRange selects either a whole month (YYYY-MM) or an inclusive From/To date range.
+// Currency overrides the home currency for this report.
+type ReportRange struct {
+	Month    string
+	From     string
+	To       string
+	Currency string
+}
+
+func (r ReportRange) bounds() (string, string) {
+	if r.Month != "" {
+		return monthBounds(r.Month)
+	}
+	return r.From, r.To
+}
+
+func (s *ReportService) Summary(ctx context.Context, rng ReportRange) (models.Summary, error) {
+	from, to := rng.bounds()
+	ccy := s.currency(rng.Currency)
+	totals, err := s.reports.CategoryTotals(ctx, from, to, ccy)
+	if err != nil {
+		return models.Summary{}, err
+	}
+	missing, err := s.reports.MissingRates(ctx, from, to, ccy)
+	if err != nil {
+		return models.Summary{}, err
+	}
+	byID, err := s.categoriesByID(ctx)
+	if err != nil {
+		return models.Summary{}, err
+	}
+	out := models.Summary{From: from, To: to, Currency: ccy, MissingRates: missing}
+	for _, t := range totals {
+		switch t.Kind {
+		case models.KindIncome:
+			out.IncomeCents += t.TotalCents
+		case models.KindExpense:
+			out.ExpenseCents += t.TotalCents
+		}
+	}
+	out.NetCents = out.IncomeCents - out.ExpenseCents
+	out.Categories = rollUpTotals(totals, byID)
+	return out, nil
+}
+
+// Tags reports expense spend per tag over rng.
+func (s *ReportService) Tags(ctx context.Context, rng ReportRange) (models.TagReport, error) {
+	from, to := rng.bounds()
+	ccy := s.currency(rng.Currency)
+	totals, err := s.reports.TagTotals(ctx, from, to, ccy)
+	if err != nil {
+		return models.TagReport{}, err
+	}
+	missing, err := s.reports.MissingRates(ctx, from, to, ccy)
+	if err != nil {
+		return models.TagReport{}, err
+	}
+	return models.TagReport{From: from, To: to, Tags: totals, Currency: ccy, MissingRates: missing}, nil
+}
+
+const (
+	DefaultPayeeReportSize = 10
+	MaxPayeeReportSize     = 100
+)
+
+// Payees reports the limit payees with the most expense spend over rng.
+func (s *ReportService) Payees(ctx context.Context, rng ReportRange, limit int) (models.PayeeReport, error) {
+	if limit <= 0 {
+		limit = DefaultPayeeReportSize
+	}
+	from, to := rng.bounds()
+	ccy := s.currency(rng.Currency)
+	totals, err := s.reports.PayeeTotals(ctx, from, to, ccy, limit)
+	if err != nil {
+		return models.PayeeReport{}, err
+	}
+	missing, err := s.reports.MissingRates(ctx, from, to, ccy)
+	if err != nil {
+		return models.PayeeReport{}, err
+	}
+	return models.PayeeReport{From: from, To: to, Payees: totals, Currency: ccy, MissingRates: missing}, nil
+}
+
+func (s *ReportService) categoriesByID(ctx context.Context) (map[string]models.Category, error) {
+	cats, err := s.cats.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.Category, len(cats))
+	for _, c := range cats {
+		byID[c.ID] = c
+	}
+	return byID, nil
+}
+
+// rollUpTotals adds each category's total into all of its ancestors, adding
+// rows for ancestors without transactions of their own. Rows keep the
+// repository's order: by kind, then largest total first.
+func rollUpTotals(totals []models.CategoryTotal, byID map[string]models.Category) []models.CategoryTotal {
+	out := make([]models.CategoryTotal, 0, len(totals))
+	idx := map[string]int{}
+	row := func(id string, kind models.TransactionKind) *models.CategoryTotal {
+		i, ok := idx[id]
+		if !ok {
+			i = len(out)
+			idx[id] = i
+			out = append(out, models.CategoryTotal{CategoryID: id, CategoryName: byID[id].Name, ParentID: byID[id].ParentID, Kind: kind})
+		}
+		return &out[i]
+	}
+	for _, t := range totals {
+		r := row(t.CategoryID, t.Kind)
+		r.CategoryName = t.CategoryName
+		r.TotalCents += t.TotalCents
+		r.Count += t.Count
+	}
+	for _, t := range totals {
+		for _, a := range categoryAncestors(byID, t.CategoryID) {
+			r := row(a, t.Kind)
+			r.TotalCents += t.TotalCents
+			r.Count += t.Count
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].TotalCents > out[j].TotalCents
+	})
+	return out
+}
+
+// missingRates lists the currencies that could not be converted, of transactions
+// within [from, to] and of budgets for months in [fromMonth, toMonth].
+func (s *ReportService) missingRates(ctx context.Context, from string, to string, fromMonth string, toMonth string, ccy string) ([]string, error) {
+	missing, err := s.reports.MissingRates(ctx, from, to, ccy)
+	if err != nil {
+		return nil, err
+	}
+	budgets, err := s.reports.MissingBudgetRates(ctx, fromMonth, toMonth, ccy)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range budgets {
+		if !slices.Contains(missing, c) {
+			missing = append(missing, c)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// BudgetVsActual compares each expense category's budget for month with what was spent.
+// An empty currency means the home currency.
+func (s *ReportService) BudgetVsActual(ctx context.Context, month string, currency string) (models.BudgetReport, error) {
+	from, to := monthBounds(month)
+	ccy := s.currency(currency)
+	lines, err := s.reports.BudgetActuals(ctx, month, from, to, ccy)
+	if err != nil {
+		return models.BudgetReport{}, err
+	}
+	missing, err := s.missingRates(ctx, from, to, month, month, ccy)
+	if err != nil {
+		return models.BudgetReport{}, err
+	}
+	carried, err := s.carriedBalances(ctx, month, ccy)
+	if err != nil {
+		return models.BudgetReport{}, err
+	}
+	for i := range lines {
+		if c, ok := carried[lines[i].CategoryID]; ok {
+			lines[i].CarriedCents = c.cents
+			delete(carried, lines[i].CategoryID)
+		}
+	}
+	// A carried balance shows up even in a month with no budget or spend.
+	for id, c := range carried {
+		if c.cents != 0 {
+			lines = append(lines, models.BudgetLine{CategoryID: id, CategoryName: c.name, CarriedCents: c.cents})
+		}
+	}
+	byID, err := s.categoriesByID(ctx)
+	if err != nil {
+		return models.BudgetReport{}, err
+	}
+	lines = rollUpBudgetLines(lines, byID)
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].CategoryName != lines[j].CategoryName {
+			return lines[i].CategoryName < lines[j].CategoryName
+		}
+		return lines[i].CategoryID < lines[j].CategoryID
+	})
+
+	out := models.BudgetReport{Month: month, Lines: lines, Currency: ccy, MissingRates: missing}
+	for i := range out.Lines {
+		fillBudgetLine(&out.Lines[i])
+		if out.Lines[i].ParentID != "" {
+			continue
+		}
+		out.Totals.BudgetedCents += out.Lines[i].BudgetedCents
+		out.Totals.CarriedCents += out.Lines[i].CarriedCents
+		out.Totals.SpentCents += out.Lines[i].SpentCents
+	}
+	fillBudgetLine(&out.Totals)
+	return out, nil
+}
+
+// rollUpBudgetLines adds each line's budget, carry and spend into all of its
+// ancestors, adding lines for ancestors that have none of their own.
+func rollUpBudgetLines(lines []models.BudgetLine, byID map[string]models.Category) []models.BudgetLine {
+	own := len(lines)
+	idx := make(map[string]int, own)
+	for i := range lines {
+		idx[lines[i].CategoryID] = i
+		lines[i].ParentID = byID[lines[i].CategoryID].ParentID
+	}
+	for i := 0; i < own; i++ {
+		l := lines[i]
+		for _, a := range categoryAncestors(byID, l.CategoryID) {
+			j, ok := idx[a]
+			if !ok {
+				j = len(lines)
+				idx[a] = j
+				lines = append(lines, models.BudgetLine{CategoryID: a, CategoryName: byID[a].Name, ParentID: byID[a].ParentID})
+			}
+			lines[j].BudgetedCents += l.BudgetedCents
+			lines[j].CarriedCents += l.CarriedCents
+			lines[j].SpentCents += l.SpentCents
+		}
+	}
+	return lines
+}
+
+type carriedBalance struct {
+	name  string
+	cents int64
+}
+
+// carriedBalances works out what each rollover category brings into month. The
+// envelope starts in the category's first budgeted month; from then on each
+// month's budget minus its spend is added, and carry_positive resets a negative
+// balance to zero before it moves on.
+func (s *ReportService) carriedBalances(ctx context.Context, month string, ccy string) (map[string]carriedBalance, error) {
+	cats, err := s.cats.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies := map[string]models.Category{}
+	var ids []string
+	for _, c := range cats {
+		if c.Type == models.CategoryExpense && c.RolloverPolicy != "" && c.RolloverPolicy != models.RolloverNone {
+			policies[c.ID] = c
+			ids = append(ids, c.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	start, _ := monthBounds(month)
+	prevMonth := start[:7]
+	if t, err := time.Parse("2006-01", prevMonth); err == nil {
+		prevMonth = t.AddDate(0, -1, 0).Format("2006-01")
+	}
+	budgets, err := s.reports.MonthlyBudgets(ctx, "0000-01", prevMonth, ids, ccy)
+	if err != nil || len(budgets) == 0 {
+		return nil, err
+	}
+	first := map[string]string{}
+	budgeted := map[string]map[string]int64{}
+	earliest := prevMonth
+	for _, b := range budgets {
+		if f, ok := first[b.CategoryID]; !ok || b.Month < f {
+			first[b.CategoryID] = b.Month
+		}
+		if b.Month < earliest {
+			earliest = b.Month
+		}
+		if budgeted[b.CategoryID] == nil {
+			budgeted[b.CategoryID] = map[string]int64{}
+		}
+		budgeted[b.CategoryID][b.Month] += b.AmountCents
+	}
+	from, _ := monthBounds(earliest)
+	_, to := monthBounds(prevMonth)
+	spends, err := s.reports.MonthlyExpenses(ctx, from, to, ids, ccy)
+	if err != nil {
+		return nil, err
+	}
+	spent := map[string]map[string]int64{}
+	for _, a := range spends {
+		if spent[a.CategoryID] == nil {
+			spent[a.CategoryID] = map[string]int64{}
+		}
+		spent[a.CategoryID][a.Month] += a.AmountCents
+	}
+
+	out := make(map[string]carriedBalance, len(first))
+	for id, firstMonth := range first {
+		cat := policies[id]
+		var bal int64
+		for _, m := range monthRange(firstMonth, prevMonth) {
+			bal += budgeted[id][m] - spent[id][m]
+			if bal < 0 && cat.RolloverPolicy == models.RolloverCarryPositive {
+				bal = 0
+			}
+		}
+		out[id] = carriedBalance{name: cat.Name, cents: bal}
+	}
+	return out, nil
+}
+
+// fillBudgetLine derives remaining, percent used and the over-budget flag
+// from BudgetedCents, CarriedCents and SpentCents.
+func fillBudgetLine(l *models.BudgetLine) {
+	available := l.BudgetedCents + l.CarriedCents
+	l.RemainingCents = available - l.SpentCents
+	l.OverBudget = l.SpentCents > available
+	l.PercentUsed = nil
+	if available > 0 {
+		pct := math.Round(float64(l.SpentCents)*1000/float64(available)) / 10
+		l.PercentUsed = &pct
+	}
+}
+
+const (
+	DefaultTrendWindow = 3
+	MaxTrendMonths     = 120
+)
+
+type TrendInput struct {
+	From        string   // YYYY-MM, inclusive
+	To          string   // YYYY-MM, inclusive
+	CategoryIDs []string // empty means every expense category with activity in range
+	Window      int      // rolling average window in months
+	Currency    string   // empty means the home currency
+}
+
+// Trends returns a per-category monthly series of expense spend and budget.
+// Months without activity are reported as zero so every series has the same length.
+func (s *ReportService) Trends(ctx context.Context, in TrendInput) (models.TrendReport, error) {
+	months := monthRange(in.From, in.To)
+	if in.Window <= 0 {
+		in.Window = DefaultTrendWindow
+	}
+	from, _ := monthBounds(in.From)
+	_, to := monthBounds(in.To)
+	ccy := s.currency(in.Currency)
+	byID, err := s.categoriesByID(ctx)
+	if err != nil {
+		return models.TrendReport{}, err
+	}
+	// Asking for a parent brings its subcategories' activity along.
+	queryIDs := in.CategoryIDs
+	if len(in.CategoryIDs) > 0 {
+		wanted := map[string]bool{}
+		for _, id := range in.CategoryIDs {
+			wanted[id] = true
+		}
+		for id := range byID {
+			if wanted[id] {
+				continue
+			}
+			for _, a := range categoryAncestors(byID, id) {
+				if wanted[a] {
+					queryIDs = append(queryIDs, id)
+					break
+				}
+			}
+		}
+	}
+
+	spent, err := s.reports.MonthlyExpenses(ctx, from, to, queryIDs, ccy)
+	if err != nil {
+		return models.TrendReport{}, err
+	}
+	budgeted, err := s.reports.MonthlyBudgets(ctx, in.From, in.To, queryIDs, ccy)
+	if err != nil {
+		return models.TrendReport{}, err
+	}
+	missing, err := s.missingRates(ctx, from, to, in.From, in.To, ccy)
+	if err != nil {
+		return models.TrendReport{}, err
+	}
+
+	type cell struct{ spent, budgeted int64 }
+	byCat := map[string]map[string]*cell{}
+	at := func(catID, month string) *cell {
+		m, ok := byCat[catID]
+		if !ok {
+			m = map[string]*cell{}
+			byCat[catID] = m
+		}
+		c, ok := m[month]
+		if !ok {
+			c = &cell{}
+			m[month] = c
+		}
+		return c
+	}
+	for _, a := range spent {
+		at(a.CategoryID, a.Month).spent += a.AmountCents
+		for _, anc := range categoryAncestors(byID, a.CategoryID) {
+			at(anc, a.Month).spent += a.AmountCents
+		}
+	}
+	for _, a := range budgeted {
+		at(a.CategoryID, a.Month).budgeted += a.AmountCents
+		for _, anc := range categoryAncestors(byID, a.CategoryID) {
+			at(anc, a.Month).budgeted += a.AmountCents
+		}
+	}
+	catIDs := in.CategoryIDs
+	if len(catIDs) == 0 {
+		for id := range byCat {
+			catIDs = append(catIDs, id)
+		}
+	}
+
+	out := models.TrendReport{
+		From:         in.From,
+		To:           in.To,
+		Window:       in.Window,
+		Categories:   make([]models.CategoryTrend, 0, len(catIDs)),
+		Currency:     ccy,
+		MissingRates: missing,
+	}
+	for _, id := range catIDs {
+		cat, err := s.cats.Get(ctx, id)
+		if err != nil {
+			return models.TrendReport{}, err
+		}
+		tr := models.CategoryTrend{CategoryID: id, CategoryName: cat.Name, ParentID: cat.ParentID, Points: make([]models.TrendPoint, 0, len(months))}
+		var windowSum int64
+		for i, m := range months {
+			p := models.TrendPoint{Month: m}
+			if c, ok := byCat[id][m]; ok {
+				p.SpentCents, p.BudgetedCents = c.spent, c.budgeted
+			}
+			windowSum += p.SpentCents
+			if i >= in.Window {
+				windowSum -= tr.Points[i-in.Window].SpentCents
+			}
+			n := min(i+1, in.Window)
+			p.RollingAvgCents = int64(math.Round(float64(windowSum) / float64(n)))
+			tr.TotalSpentCents += p.SpentCents
+			tr.Points = append(tr.Points, p)
+		}
+		out.Categories = append(out.Categories, tr)
+	}
+	sort.SliceStable(out.Categories, func(i, j int) bool {
+		if out.Categories[i].CategoryName != out.Categories[j].CategoryName {
+			return out.Categories[i].CategoryName < out.Categories[j].CategoryName
+		}
+		return out.Categories[i].CategoryID < out.Categories[j].CategoryID
+	})
+	return out, nil
+}
+
+// monthRange lists every YYYY-MM key from `from` to `to`, inclusive.
+func monthRange(from, to string) []string {
+	start, err1 := time.Parse("2006-01", from)
+	end, err2 := time.Parse("2006-01", to)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+	var out []string
+	for m := start; !m.After(end); m = m.AddDate(0, 1, 0) {
+		out = append(out, m.Format("2006-01"))
+	}
+	return out
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/testutil"
+)
+
+func TestReportService_Summary(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-rent", Type: models.CategoryExpense, Name: "Rent"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-salary", Type: models.CategoryIncome, Name: "Salary"})
+
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), txnRepo)
+	for _, in := range []CreateTxnInput{
+		{Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "cat-food", AmountCents: 100_00},
+		{Kind: models.KindExpense, Date: "2026-01-31", CategoryID: "cat-food", AmountCents: 50_00},
+		{Kind: models.KindExpense, Date: "2026-01-01", CategoryID: "cat-rent", AmountCents: 1000_00},
+		{Kind: models.KindIncome, Date: "2026-01-25", CategoryID: "cat-salary", AmountCents: 5000_00},
+		{Kind: models.KindExpense, Date: "2026-02-01", CategoryID: "cat-food", AmountCents: 999_00},
+	} {
+		if _, err := txns.Create(ctx, in); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	svc := NewReportService(repositories.NewGormReportRepo(gdb), catRepo, models.DefaultCurrency)
+	sum, err := svc.Summary(ctx, ReportRange{Month: "2026-01"})
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	if sum.From != "2026-01-01" || sum.To != "2026-01-31" {
+		t.Fatalf("unexpected range %s..%s", sum.From, sum.To)
+	}
+	if sum.IncomeCents != 5000_00 || sum.ExpenseCents != 1150_00 || sum.NetCents != 3850_00 {
+		t.Fatalf("unexpected totals: %+v", sum)
+	}
+	if len(sum.Categories) != 3 {
+		t.Fatalf("expected 3 category totals, got %d", len(sum.Categories))
+	}
+	for _, ct := range sum.Categories {
+		if ct.CategoryID == "cat-food" && (ct.TotalCents != 150_00 || ct.Count != 2 || ct.CategoryName != "Food") {
+			t.Fatalf("unexpected food total: %+v", ct)
+		}
+	}
+}
+
+func TestReportService_BudgetVsActual(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-fun", Type: models.CategoryExpense, Name: "Fun"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-rent", Type: models.CategoryExpense, Name: "Rent"})
+
+	budgets := NewBudgetService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormBudgetRepo(gdb))
+	_, _ = budgets.Upsert(ctx, UpsertBudgetInput{Month: "2026-01", CategoryID: "cat-food", AmountCents: 200_00})
+	_, _ = budgets.Upsert(ctx, UpsertBudgetInput{Month: "2026-01", CategoryID: "cat-rent", AmountCents: 1000_00})
+
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormTxnRepo(gdb))
+	_, _ = txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "cat-food", AmountCents: 250_00})
+	_, _ = txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-01-06", CategoryID: "cat-fun", AmountCents: 40_00})
+	_, _ = txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-01-01", CategoryID: "cat-rent", AmountCents: 500_00})
+
+	svc := NewReportService(repositories.NewGormReportRepo(gdb), catRepo, models.DefaultCurrency)
+	rep, err := svc.BudgetVsActual(ctx, "2026-01", "")
+	if err != nil {
+		t.Fatalf("budget vs actual: %v", err)
+	}
+	if len(rep.Lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(rep.Lines))
+	}
+	food, fun, rent := rep.Lines[0], rep.Lines[1], rep.Lines[2]
+	if food.RemainingCents != -50_00 || !food.OverBudget || food.PercentUsed == nil || *food.PercentUsed != 125 {
+		t.Fatalf("unexpected food line: %+v", food)
+	}
+	if fun.BudgetedCents != 0 || !fun.OverBudget || fun.PercentUsed != nil {
+		t.Fatalf("unexpected unbudgeted line: %+v", fun)
+	}
+	if rent.RemainingCents != 500_00 || rent.OverBudget || *rent.PercentUsed != 50 {
+		t.Fatalf("unexpected rent line: %+v", rent)
+	}
+	if rep.Totals.BudgetedCents != 1200_00 || rep.Totals.SpentCents != 790_00 || rep.Totals.RemainingCents != 410_00 {
+		t.Fatalf("unexpected totals: %+v", rep.Totals)
+	}
+}
+
+func TestReportService_BudgetVsActual_Rollover(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-car", Type: models.CategoryExpense, Name: "Car", RolloverPolicy: models.RolloverCarryPositive})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-fun", Type: models.CategoryExpense, Name: "Fun", RolloverPolicy: models.RolloverCarryBoth})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Food"})
+
+	budgets := NewBudgetService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormBudgetRepo(gdb))
+	for _, in := range []UpsertBudgetInput{
+		{Month: "2026-01", CategoryID: "cat-car", AmountCents: 100_00},
+		{Month: "2026-02", CategoryID: "cat-car", AmountCents: 100_00},
+		{Month: "2026-01", CategoryID: "cat-fun", AmountCents: 100_00},
+		{Month: "2026-02", CategoryID: "cat-fun", AmountCents: 100_00},
+		{Month: "2026-01", CategoryID: "cat-food", AmountCents: 100_00},
+		{Month: "2026-03", CategoryID: "cat-food", AmountCents: 100_00},
+	} {
+		if _, err := budgets.Upsert(ctx, in); err != nil {
+			t.Fatalf("upsert budget: %v", err)
+		}
+	}
+
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormTxnRepo(gdb))
+	for _, in := range []CreateTxnInput{
+		{Kind: models.KindExpense, Date: "2026-01-10", CategoryID: "cat-car", AmountCents: 150_00},
+		{Kind: models.KindExpense, Date: "2026-02-10", CategoryID: "cat-car", AmountCents: 30_00},
+		{Kind: models.KindExpense, Date: "2026-01-10", CategoryID: "cat-fun", AmountCents: 150_00},
+		{Kind: models.KindExpense, Date: "2026-02-10", CategoryID: "cat-fun", AmountCents: 30_00},
+		{Kind: models.KindExpense, Date: "2026-01-10", CategoryID: "cat-food", AmountCents: 50_00},
+	} {
+		if _, err := txns.Create(ctx, in); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	svc := NewReportService(repositories.NewGormReportRepo(gdb), catRepo, models.DefaultCurrency)
+	rep, err := svc.BudgetVsActual(ctx, "2026-03", "")
+	if err != nil {
+		t.Fatalf("budget vs actual: %v", err)
+	}
+	if len(rep.Lines) != 3 {
+		t.Fatalf("expected 3 lines, got %+v", rep.Lines)
+	}
+	car, food, fun := rep.Lines[0], rep.Lines[1], rep.Lines[2]
+	// Car forgives January's overspend, then keeps February's 70.
+	if car.CategoryID != "cat-car" || car.CarriedCents != 70_00 || car.RemainingCents != 70_00 || car.OverBudget {
+		t.Fatalf("unexpected car line: %+v", car)
+	}
+	// Fun carries the overspend too: -50 + 70.
+	if fun.CategoryID != "cat-fun" || fun.CarriedCents != 20_00 || fun.RemainingCents != 20_00 {
+		t.Fatalf("unexpected fun line: %+v", fun)
+	}
+	if food.CarriedCents != 0 || food.RemainingCents != 100_00 {
+		t.Fatalf("unexpected food line: %+v", food)
+	}
+	if rep.Totals.CarriedCents != 90_00 || rep.Totals.RemainingCents != 190_00 {
+		t.Fatalf("unexpected totals: %+v", rep.Totals)
+	}
+}
+
+func TestReportService_BudgetVsActual_RollsUpSubcategories(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-groceries", Type: models.CategoryExpense, Name: "Groceries", ParentID: "cat-food"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-dining", Type: models.CategoryExpense, Name: "Restaurants", ParentID: "cat-food"})
+
+	budgets := NewBudgetService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormBudgetRepo(gdb))
+	_, _ = budgets.Upsert(ctx, UpsertBudgetInput{Month: "2026-01", CategoryID: "cat-groceries", AmountCents: 300_00})
+	_, _ = budgets.Upsert(ctx, UpsertBudgetInput{Month: "2026-01", CategoryID: "cat-dining", AmountCents: 100_00})
+
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormTxnRepo(gdb))
+	_, _ = txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "cat-groceries", AmountCents: 120_00})
+	_, _ = txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-01-06", CategoryID: "cat-dining", AmountCents: 150_00})
+
+	svc := NewReportService(repositories.NewGormReportRepo(gdb), catRepo, models.DefaultCurrency)
+	rep, err := svc.BudgetVsActual(ctx, "2026-01", "")
+	if err != nil {
+		t.Fatalf("budget vs actual: %v", err)
+	}
+	byID := map[string]models.BudgetLine{}
+	for _, l := range rep.Lines {
+		byID[l.CategoryID] = l
+	}
+	food := byID["cat-food"]
+	if food.BudgetedCents != 400_00 || food.SpentCents != 270_00 || food.ParentID != "" {
+		t.Fatalf("unexpected parent line: %+v", food)
+	}
+	if dining := byID["cat-dining"]; dining.ParentID != "cat-food" || !dining.OverBudget {
+		t.Fatalf("unexpected child line: %+v", dining)
+	}
+	if rep.Totals.BudgetedCents != 400_00 || rep.Totals.SpentCents != 270_00 {
+		t.Fatalf("totals counted children twice: %+v", rep.Totals)
+	}
+}
+
+func TestReportService_Trends_FillsEmptyMonths(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Groceries"})
+
+	budgets := NewBudgetService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormBudgetRepo(gdb))
+	_, _ = budgets.Upsert(ctx, UpsertBudgetInput{Month: "2025-11", CategoryID: "cat-food", AmountCents: 300_00})
+
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormTxnRepo(gdb))
+	_, _ = txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2025-11-05", CategoryID: "cat-food", AmountCents: 300_00})
+	_, _ = txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-01-10", CategoryID: "cat-food", AmountCents: 600_00})
+
+	svc := NewReportService(repositories.NewGormReportRepo(gdb), catRepo, models.DefaultCurrency)
+	rep, err := svc.Trends(ctx, TrendInput{From: "2025-11", To: "2026-01", Window: 2})
+	if err != nil {
+		t.Fatalf("trends: %v", err)
+	}
+	if len(rep.Categories) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(rep.Categories))
+	}
+	pts := rep.Categories[0].Points
+	if len(pts) != 3 || pts[1].Month != "2025-12" || pts[1].SpentCents != 0 {
+		t.Fatalf("expected zero-filled December, got %+v", pts)
+	}
+	if pts[0].BudgetedCents != 300_00 || pts[0].RollingAvgCents != 300_00 {
+		t.Fatalf("unexpected first point: %+v", pts[0])
+	}
+	if pts[1].RollingAvgCents != 150_00 || pts[2].RollingAvgCents != 300_00 {
+		t.Fatalf("unexpected rolling averages: %+v", pts)
+	}
+	if rep.Categories[0].TotalSpentCents != 900_00 {
+		t.Fatalf("unexpected total: %d", rep.Categories[0].TotalSpentCents)
+	}
+}
+
+func TestReportService_Trends_OrdersSameNamesByID(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormTxnRepo(gdb))
+	for _, id := range []string{"cat-c", "cat-a", "cat-b"} {
+		_, _ = catRepo.Create(ctx, models.Category{ID: id, Type: models.CategoryExpense, Name: "Other"})
+		_, _ = txns.Create(ctx, CreateTxnInput{Kind: models.KindExpense, Date: "2026-01-05", CategoryID: id, AmountCents: 100_00})
+	}
+
+	svc := NewReportService(repositories.NewGormReportRepo(gdb), catRepo, models.DefaultCurrency)
+	for range 10 {
+		rep, err := svc.Trends(ctx, TrendInput{From: "2026-01", To: "2026-01"})
+		if err != nil {
+			t.Fatalf("trends: %v", err)
+		}
+		if len(rep.Categories) != 3 || rep.Categories[0].CategoryID != "cat-a" || rep.Categories[1].CategoryID != "cat-b" || rep.Categories[2].CategoryID != "cat-c" {
+			t.Fatalf("expected same-named series ordered by ID, got %+v", rep.Categories)
+		}
+	}
+}
+
+func TestReportService_ConvertsToHomeCurrency(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-food", Type: models.CategoryExpense, Name: "Food"})
+
+	rates := NewRateService(clk, ids, repositories.NewGormRateRepo(gdb))
+	for _, in := range []UpsertRateInput{
+		{Date: "2026-01-01", Base: "USD", Quote: "IDR", Rate: 15000},
+		{Date: "2026-01-20", Base: "USD", Quote: "IDR", Rate: 16000},
+	} {
+		if _, err := rates.Upsert(ctx, in); err != nil {
+			t.Fatalf("upsert rate: %v", err)
+		}
+	}
+
+	txns := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), repositories.NewGormTxnRepo(gdb))
+	for _, in := range []CreateTxnInput{
+		{Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "cat-food", AmountCents: 100_000_00, Currency: "IDR"},
+		{Kind: models.KindExpense, Date: "2026-01-10", CategoryID: "cat-food", AmountCents: 10_00, Currency: "USD"},
+		{Kind: models.KindExpense, Date: "2026-01-25", CategoryID: "cat-food", AmountCents: 2_50, Currency: "USD"},
+		{Kind: models.KindExpense, Date: "2026-01-26", CategoryID: "cat-food", AmountCents: 5_00, Currency: "SGD"},
+	} {
+		if _, err := txns.Create(ctx, in); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	svc := NewReportService(repositories.NewGormReportRepo(gdb), catRepo, "IDR")
+	sum, err := svc.Summary(ctx, ReportRange{Month: "2026-01"})
+	if err != nil {
+		t.Fatalf("summary: %v", err)
+	}
+	// 100,000 + 10 USD at 15,000 + 2.50 USD at 16,000; SGD has no rate.
+	if sum.Currency != "IDR" || sum.ExpenseCents != 290_000_00 {
+		t.Fatalf("unexpected IDR summary: %+v", sum)
+	}
+	if len(sum.MissingRates) != 1 || sum.MissingRates[0] != "SGD" {
+		t.Fatalf("expected SGD to be reported missing, got %v", sum.MissingRates)
+	}
+
+	// The same rates work inverted when reporting in USD.
+	usd, err := svc.Summary(ctx, ReportRange{From: "2026-01-01", To: "2026-01-20", Currency: "USD"})
+	if err != nil {
+		t.Fatalf("usd summary: %v", err)
+	}
+	if usd.Currency != "USD" || usd.ExpenseCents != 16_67 {
+		t.Fatalf("unexpected USD summary: %+v", usd)
+	}
+}
+
+func TestReportService_ReportsBudgetsWithoutRate(t *testing.T) {
+	ctx := context.Background()
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-travel", Type: models.CategoryExpense, Name: "Travel"})
+	_, err := repositories.NewGormBudgetRepo(gdb).Upsert(ctx, models.Budget{
+		ID: "b-1", Month: "2026-01", CategoryID: "cat-travel", AmountCents: 200_00, Currency: "USD",
+		CreatedAt: "2026-01-01T00:00:00Z", UpdatedAt: "2026-01-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("upsert budget: %v", err)
+	}
+
+	svc := NewReportService(repositories.NewGormReportRepo(gdb), catRepo, "IDR")
+	rep, err := svc.BudgetVsActual(ctx, "2026-01", "")
+	if err != nil {
+		t.Fatalf("budget vs actual: %v", err)
+	}
+	if len(rep.MissingRates) != 1 || rep.MissingRates[0] != "USD" {
+		t.Fatalf("expected the USD budget to be reported missing, got %v", rep.MissingRates)
+	}
+	trends, err := svc.Trends(ctx, TrendInput{From: "2025-12", To: "2026-02"})
+	if err != nil {
+		t.Fatalf("trends: %v", err)
+	}
+	if len(trends.MissingRates) != 1 || trends.MissingRates[0] != "USD" {
+		t.Fatalf("expected trends to report USD missing, got %v", trends.MissingRates)
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"personal-budgeting/be/internal/models"
+)
+
+// RuleEngine applies a snapshot of categorization rules. Actions pointing at a
+// category or tag that no longer exists are skipped.
+type RuleEngine struct {
+	rules []compiledRule
+	cats  map[string]models.Category
+	tags  map[string]bool
+}
+
+type compiledRule struct {
+	models.Rule
+	contains string         // lowercased NoteContains
+	re       *regexp.Regexp // case-insensitive NoteRegex
+}
+
+// CompileRuleRegex compiles a NoteRegex the way rules match it.
+func CompileRuleRegex(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + expr)
+}
+
+func newRuleEngine(rules []models.Rule, cats []models.Category, tags []models.Tag) *RuleEngine {
+	e := &RuleEngine{cats: make(map[string]models.Category, len(cats)), tags: make(map[string]bool, len(tags))}
+	for _, c := range cats {
+		e.cats[c.ID] = c
+	}
+	for _, t := range tags {
+		e.tags[t.ID] = true
+	}
+	for _, r := range rules {
+		cr := compiledRule{Rule: r, contains: strings.ToLower(r.Conditions.NoteContains)}
+		if r.Conditions.NoteRegex != "" {
+			re, err := CompileRuleRegex(r.Conditions.NoteRegex)
+			if err != nil {
+				continue // rejected on save; never matches
+			}
+			cr.re = re
+		}
+		e.rules = append(e.rules, cr)
+	}
+	return e
+}
+
+// Apply runs every unpaused rule, in order, over a new transaction. Rules only
+// fill in what the user left open: the first matching rule with a fitting
+// category sets it if CategoryID is empty, and the first one with SetNote
+// rewrites the note. Tags of all matching rules are added. Conditions always
+// see the transaction as it came in.
+func (e *RuleEngine) Apply(t models.Txn) models.Txn {
+	in := t
+	t.TagIDs = slices.Clone(t.TagIDs)
+	noteSet := false
+	for _, r := range e.rules {
+		if r.Paused || !r.matches(in) {
+			continue
+		}
+		if t.CategoryID == "" {
+			if id, ok := e.category(r, in); ok {
+				t.CategoryID = id
+			}
+		}
+		if !noteSet && r.Actions.SetNote != "" {
+			t.Note = r.note(in.Note)
+			noteSet = true
+		}
+		t.TagIDs = e.addTags(t.TagIDs, r.Actions.TagIDs)
+	}
+	return t
+}
+
+// applyRule runs one rule over an existing transaction, overriding its category
+// and note. ok is false when the rule doesn't match.
+func (e *RuleEngine) applyRule(r compiledRule, t models.Txn) (models.Txn, bool) {
+	if !r.matches(t) {
+		return t, false
+	}
+	out := t
+	if id, ok := e.category(r, t); ok {
+		out.CategoryID = id
+	}
+	if r.Actions.SetNote != "" {
+		out.Note = r.note(t.Note)
+	}
+	out.TagIDs = e.addTags(slices.Clone(t.TagIDs), r.Actions.TagIDs)
+	return out, true
+}
+
+func (r compiledRule) matches(t models.Txn) bool {
+	c := r.Conditions
+	switch {
+	case t.Kind != models.KindIncome && t.Kind != models.KindExpense:
+		return false
+	case c.Kind != "" && c.Kind != t.Kind:
+		return false
+	case c.AccountID != "" && c.AccountID != t.AccountID:
+		return false
+	case c.MinAmountCents != nil && t.AmountCents < *c.MinAmountCents:
+		return false
+	case c.MaxAmountCents != nil && t.AmountCents > *c.MaxAmountCents:
+		return false
+	case r.contains != "" && !strings.Contains(strings.ToLower(t.Note), r.contains):
+		return false
+	case r.re != nil && !r.re.MatchString(t.Note):
+		return false
+	}
+	return true
+}
+
+// note expands SetNote against the NoteRegex match, if there is one.
+func (r compiledRule) note(note string) string {
+	if r.re == nil {
+		return r.Actions.SetNote
+	}
+	m := r.re.FindStringSubmatchIndex(note)
+	return string(r.re.ExpandString(nil, r.Actions.SetNote, note, m))
+}
+
+// category returns the rule's category when it can be set on t.
+func (e *RuleEngine) category(r compiledRule, t models.Txn) (string, bool) {
+	if r.Actions.CategoryID == "" || len(t.Splits) > 0 {
+		return "", false
+	}
+	cat, ok := e.cats[r.Actions.CategoryID]
+	if !ok || string(cat.Type) != string(t.Kind) {
+		return "", false
+	}
+	return cat.ID, true
+}
+
+func (e *RuleEngine) addTags(have, add []string) []string {
+	for _, id := range add {
+		if !e.tags[id] || slices.Contains(have, id) {
+			continue
+		}
+		have = append(have, id)
+	}
+	return have
+}
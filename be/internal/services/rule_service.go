@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// RuleService stores categorization rules and runs them over existing
+// transactions. New transactions go through Engine.
+type RuleService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	tx    repositories.Transactor
+	rules repositories.RuleRepository
+	cats  repositories.CategoryRepository
+	tags  repositories.TagRepository
+	txns  repositories.TxnRepository
+}
+
+func NewRuleService(clk clock.Clock, ids id.Generator, tx repositories.Transactor, rules repositories.RuleRepository, cats repositories.CategoryRepository, tags repositories.TagRepository, txns repositories.TxnRepository) *RuleService {
+	return &RuleService{clk: clk, ids: ids, tx: tx, rules: rules, cats: cats, tags: tags, txns: txns}
+}
+
+func (s *RuleService) List(ctx context.Context) ([]models.Rule, error) {
+	return s.rules.List(ctx)
+}
+
+func (s *RuleService) Get(ctx context.Context, id string) (models.Rule, error) {
+	return s.rules.Get(ctx, id)
+}
+
+func (s *RuleService) CountByCategory(ctx context.Context, categoryID string) (int, error) {
+	return s.rules.CountByCategory(ctx, categoryID)
+}
+
+func (s *RuleService) CountByAccount(ctx context.Context, accountID string) (int, error) {
+	return s.rules.CountByAccount(ctx, accountID)
+}
+
+type CreateRuleInput struct {
+	Name       string                `json:"name"`
+	Priority   int                   `json:"priority"`
+	Paused     bool                  `json:"paused"`
+	Conditions models.RuleConditions `json:"conditions"`
+	Actions    models.RuleActions    `json:"actions"`
+}
+
+func (s *RuleService) Create(ctx context.Context, in CreateRuleInput) (models.Rule, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	return s.rules.Create(ctx, models.Rule{
+		ID:         s.ids.NewID(),
+		Name:       strings.TrimSpace(in.Name),
+		Priority:   in.Priority,
+		Paused:     in.Paused,
+		Conditions: in.Conditions,
+		Actions:    in.Actions,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+}
+
+// UpdateRuleInput replaces conditions or actions as a whole when present.
+type UpdateRuleInput struct {
+	Name       *string                `json:"name"`
+	Priority   *int                   `json:"priority"`
+	Paused     *bool                  `json:"paused"`
+	Conditions *models.RuleConditions `json:"conditions"`
+	Actions    *models.RuleActions    `json:"actions"`
+}
+
+func (s *RuleService) Update(ctx context.Context, id string, in UpdateRuleInput) (models.Rule, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	patch := repositories.RulePatch{
+		Priority:   in.Priority,
+		Paused:     in.Paused,
+		Conditions: in.Conditions,
+		Actions:    in.Actions,
+		UpdatedAt:  &now,
+	}
+	if in.Name != nil {
+		trimmed := strings.TrimSpace(*in.Name)
+		patch.Name = &trimmed
+	}
+	return s.rules.Update(ctx, id, patch)
+}
+
+func (s *RuleService) Delete(ctx context.Context, id string) error {
+	return s.rules.Delete(ctx, id)
+}
+
+// Engine snapshots the current rules for categorizing new transactions.
+func (s *RuleService) Engine(ctx context.Context) (*RuleEngine, error) {
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.engine(ctx, rules)
+}
+
+func (s *RuleService) engine(ctx context.Context, rules []models.Rule) (*RuleEngine, error) {
+	cats, err := s.cats.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.tags.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newRuleEngine(rules, cats, tags), nil
+}
+
+// RunRuleInput limits a run over history to an inclusive YYYY-MM-DD range.
+type RunRuleInput struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Preview lists the transactions a rule would change, without changing them.
+func (s *RuleService) Preview(ctx context.Context, id string, in RunRuleInput) (models.RuleApplyResult, error) {
+	return s.run(ctx, id, in, true)
+}
+
+// ApplyToHistory runs a rule over existing transactions in one transaction,
+// paused or not. Unlike on new transactions, it replaces categories and notes.
+func (s *RuleService) ApplyToHistory(ctx context.Context, id string, in RunRuleInput) (models.RuleApplyResult, error) {
+	return s.run(ctx, id, in, false)
+}
+
+func (s *RuleService) run(ctx context.Context, id string, in RunRuleInput, dryRun bool) (models.RuleApplyResult, error) {
+	res := models.RuleApplyResult{RuleID: id, DryRun: dryRun, Changes: []models.RuleChange{}}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		rule, err := s.rules.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		eng, err := s.engine(ctx, []models.Rule{rule})
+		if err != nil {
+			return err
+		}
+		if len(eng.rules) == 0 {
+			return nil // unusable regex
+		}
+		r := eng.rules[0]
+
+		// Narrow in SQL where that is exact; the engine has the final say.
+		f := repositories.TxnFilter{
+			From:           in.From,
+			To:             in.To,
+			Kind:           rule.Conditions.Kind,
+			AccountID:      rule.Conditions.AccountID,
+			MinAmountCents: rule.Conditions.MinAmountCents,
+			MaxAmountCents: rule.Conditions.MaxAmountCents,
+		}
+		var matched []string
+		err = s.txns.Each(ctx, f, func(t models.Txn) error {
+			if r.matches(t) {
+				matched = append(matched, t.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		res.Matched = len(matched)
+
+		now := s.clk.Now().Format(time.RFC3339)
+		for _, txnID := range matched {
+			t, err := s.txns.Get(ctx, txnID)
+			if err != nil {
+				return err
+			}
+			next, _ := eng.applyRule(r, t)
+			change := models.RuleChange{
+				TxnID:         t.ID,
+				Date:          t.Date,
+				Note:          t.Note,
+				CategoryID:    t.CategoryID,
+				NewNote:       next.Note,
+				NewCategoryID: next.CategoryID,
+			}
+			for _, tagID := range next.TagIDs {
+				if !slices.Contains(t.TagIDs, tagID) {
+					change.AddedTagIDs = append(change.AddedTagIDs, tagID)
+				}
+			}
+			if change.NewNote == change.Note && change.NewCategoryID == change.CategoryID && len(change.AddedTagIDs) == 0 {
+				continue
+			}
+			res.Changes = append(res.Changes, change)
+			if dryRun {
+				continue
+			}
+			patch := repositories.TxnPatch{UpdatedAt: &now}
+			if change.NewCategoryID != change.CategoryID {
+				patch.CategoryID = &next.CategoryID
+			}
+			if change.NewNote != change.Note {
+				patch.Note = &next.Note
+			}
+			if len(change.AddedTagIDs) > 0 {
+				patch.TagIDs = &next.TagIDs
+			}
+			if _, err := s.txns.Update(ctx, t.ID, patch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return res, err
+}
@@ -2,20 +2,30 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	"personal-budgeting/be/internal/errs"
 	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/money"
 	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/validate"
 )
 
 type StateService struct {
-	cats    repositories.CategoryRepository
-	budgets repositories.BudgetRepository
-	txns    repositories.TxnRepository
+	tx       repositories.Transactor
+	accounts repositories.AccountRepository
+	cats     repositories.CategoryRepository
+	budgets  repositories.BudgetRepository
+	txns     repositories.TxnRepository
+	rules    repositories.RecurringRuleRepository
+	tags     repositories.TagRepository
+	payees   repositories.PayeeRepository
 }
 
-func NewStateService(cats repositories.CategoryRepository, budgets repositories.BudgetRepository, txns repositories.TxnRepository) *StateService {
-	return &StateService{cats: cats, budgets: budgets, txns: txns}
+func NewStateService(tx repositories.Transactor, accounts repositories.AccountRepository, cats repositories.CategoryRepository, budgets repositories.BudgetRepository, txns repositories.TxnRepository, rules repositories.RecurringRuleRepository, tags repositories.TagRepository, payees repositories.PayeeRepository) *StateService {
+	return &StateService{tx: tx, accounts: accounts, cats: cats, budgets: budgets, txns: txns, rules: rules, tags: tags, payees: payees}
 }
 
 func (s *StateService) Get(ctx context.Context) (models.AppStateV1, error) {
@@ -31,49 +41,479 @@ func (s *StateService) Get(ctx context.Context) (models.AppStateV1, error) {
 	if err != nil {
 		return models.AppStateV1{}, err
 	}
+	accounts, err := s.accounts.List(ctx, true)
+	if err != nil {
+		return models.AppStateV1{}, err
+	}
+	rules, err := s.rules.List(ctx)
+	if err != nil {
+		return models.AppStateV1{}, err
+	}
+	tags, err := s.tags.List(ctx)
+	if err != nil {
+		return models.AppStateV1{}, err
+	}
+	payees, err := s.payees.List(ctx)
+	if err != nil {
+		return models.AppStateV1{}, err
+	}
 	return models.AppStateV1{
-		Version:      1,
-		Categories:   cats,
-		Budgets:      budgets,
-		Transactions: txns,
+		Version:        1,
+		Categories:     cats,
+		Budgets:        budgets,
+		Transactions:   txns,
+		Accounts:       accounts,
+		RecurringRules: rules,
+		Tags:           tags,
+		Payees:         payees,
 	}, nil
 }
 
-// Replace replaces all stored data with the provided state.
-// This is intended for local/dev sync; production apps should use proper auth and per-user storage.
+// Replace replaces the current user's data with the provided state in one database transaction.
+// The payload is checked for referential integrity first; if anything is wrong an
+// *errs.ValidationError listing every problem is returned and nothing is touched.
+// Accounts, recurring rules, tags and payees are only replaced when the payload carries them;
+// otherwise the stored ones are kept and checked against the new state. Trashed transactions,
+// categories and budgets are dropped for good, so Get, which leaves them out, round-trips.
 func (s *StateService) Replace(ctx context.Context, st models.AppStateV1) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		replaceAccounts := st.Accounts != nil
+		accounts := st.Accounts
+		if !replaceAccounts {
+			var err error
+			if accounts, err = s.accounts.List(ctx, true); err != nil {
+				return err
+			}
+		}
+		replaceRules := st.RecurringRules != nil
+		rules := st.RecurringRules
+		if !replaceRules {
+			var err error
+			if rules, err = s.rules.List(ctx); err != nil {
+				return err
+			}
+		}
+		replaceTags := st.Tags != nil
+		tags := st.Tags
+		if !replaceTags {
+			var err error
+			if tags, err = s.tags.List(ctx); err != nil {
+				return err
+			}
+		}
+		replacePayees := st.Payees != nil
+		payees := st.Payees
+		if !replacePayees {
+			var err error
+			if payees, err = s.payees.List(ctx); err != nil {
+				return err
+			}
+		}
+		if problems := validateState(st, accounts, replaceAccounts, rules, replaceRules, tags, replaceTags, payees, replacePayees); len(problems) > 0 {
+			return &errs.ValidationError{Problems: problems}
+		}
+
+		// Children first on delete, parents first on insert (FKs are RESTRICT).
+		if err := s.txns.DeleteAll(ctx); err != nil {
+			return err
+		}
+		if err := s.budgets.DeleteAll(ctx); err != nil {
+			return err
+		}
+		if err := s.cats.DeleteAll(ctx); err != nil {
+			return err
+		}
+		if replaceAccounts {
+			if err := s.accounts.DeleteAll(ctx); err != nil {
+				return err
+			}
+			if err := s.accounts.CreateMany(ctx, st.Accounts); err != nil {
+				return err
+			}
+		}
+		if err := s.cats.CreateMany(ctx, st.Categories); err != nil {
+			return err
+		}
+		if err := s.budgets.CreateMany(ctx, st.Budgets); err != nil {
+			return err
+		}
+		if err := s.budgets.PruneTemplateLines(ctx); err != nil {
+			return err
+		}
+		if replaceTags {
+			if err := s.tags.DeleteAll(ctx); err != nil {
+				return err
+			}
+			if err := s.tags.CreateMany(ctx, st.Tags); err != nil {
+				return err
+			}
+		}
+		if replacePayees {
+			if err := s.payees.DeleteAll(ctx); err != nil {
+				return err
+			}
+			if err := s.payees.CreateMany(ctx, st.Payees); err != nil {
+				return err
+			}
+		}
+		if replaceRules {
+			if err := s.rules.DeleteAll(ctx); err != nil {
+				return err
+			}
+			if err := s.rules.CreateMany(ctx, st.RecurringRules); err != nil {
+				return err
+			}
+		}
+		return s.txns.CreateMany(ctx, st.Transactions)
+	})
+}
+
+// validateState applies the same rules the per-entity handlers enforce, plus
+// uniqueness and cross-references within the payload. Transactions may reference
+// any of accounts; those are only checked themselves when they come from the payload.
+// The same goes for rules, which must always find their category and account.
+func validateState(st models.AppStateV1, accounts []models.Account, checkAccounts bool, rules []models.RecurringRule, checkRules bool, tags []models.Tag, checkTags bool, payees []models.Payee, checkPayees bool) []errs.Problem {
+	var problems []errs.Problem
+	add := func(path, format string, args ...any) {
+		problems = append(problems, errs.Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	// checkAmount validates a currency (empty means the default) and that cents fits it.
+	checkAmount := func(path, code string, cents int64) {
+		if code == "" {
+			code = models.DefaultCurrency
+		}
+		if ccy, ok := money.LookupCurrency(code); !ok {
+			add(path+".currency", "unsupported currency %q", code)
+		} else if !ccy.ValidCents(cents) {
+			add(path+".amountCents", "has more decimals than %s allows", code)
+		}
+	}
+
 	if st.Version != 1 {
-		return errs.ErrValidation
+		add("version", "unsupported version %d", st.Version)
+	}
+
+	accts := make(map[string]models.Account, len(accounts))
+	for i, a := range accounts {
+		if !checkAccounts {
+			accts[a.ID] = a
+			continue
+		}
+		p := fmt.Sprintf("accounts[%d]", i)
+		switch {
+		case strings.TrimSpace(a.ID) == "":
+			add(p+".id", "is required")
+		case accts[a.ID].ID != "":
+			add(p+".id", "duplicate id %q", a.ID)
+		default:
+			if a.Currency == "" {
+				a.Currency = models.DefaultCurrency
+			}
+			accts[a.ID] = a
+		}
+		if strings.TrimSpace(a.Name) == "" {
+			add(p+".name", "is required")
+		}
+		if !a.Type.Valid() {
+			add(p+".type", "unknown account type %q", a.Type)
+		}
+		checkAmount(p, a.Currency, a.OpeningBalanceCents)
+	}
+
+	cats := make(map[string]models.Category, len(st.Categories))
+	for i, c := range st.Categories {
+		p := fmt.Sprintf("categories[%d]", i)
+		switch {
+		case strings.TrimSpace(c.ID) == "":
+			add(p+".id", "is required")
+		case cats[c.ID].ID != "":
+			add(p+".id", "duplicate id %q", c.ID)
+		default:
+			cats[c.ID] = c
+		}
+		if c.Type != models.CategoryIncome && c.Type != models.CategoryExpense {
+			add(p+".type", "must be income or expense")
+		}
+		if strings.TrimSpace(c.Name) == "" {
+			add(p+".name", "is required")
+		}
+		if c.RolloverPolicy != "" && !c.RolloverPolicy.Valid() {
+			add(p+".rolloverPolicy", "must be none, carry_positive or carry_both")
+		} else if c.RolloverPolicy != "" && c.RolloverPolicy != models.RolloverNone && c.Type != models.CategoryExpense {
+			add(p+".rolloverPolicy", "only expense categories roll over")
+		}
+	}
+	for i, c := range st.Categories {
+		if c.ParentID == "" {
+			continue
+		}
+		p := fmt.Sprintf("categories[%d].parentId", i)
+		parent, ok := cats[c.ParentID]
+		switch {
+		case !ok:
+			add(p, "unknown category %q", c.ParentID)
+		case parent.Type != c.Type:
+			add(p, "parent must have the same type")
+		case CreatesCycle(st.Categories, c.ID, c.ParentID):
+			add(p, "would make the category its own ancestor")
+		}
 	}
 
-	// Only supported when repos expose reset/bulk operations (memory repos do).
-	type catBulk interface {
-		Reset()
-		BulkUpsert([]models.Category)
+	tagIDs := make(map[string]bool, len(tags))
+	tagNames := map[string]bool{}
+	for i, t := range tags {
+		if !checkTags {
+			tagIDs[t.ID] = true
+			continue
+		}
+		p := fmt.Sprintf("tags[%d]", i)
+		switch {
+		case strings.TrimSpace(t.ID) == "":
+			add(p+".id", "is required")
+		case tagIDs[t.ID]:
+			add(p+".id", "duplicate id %q", t.ID)
+		default:
+			tagIDs[t.ID] = true
+		}
+		switch {
+		case strings.TrimSpace(t.Name) == "" || t.Name != strings.ToLower(strings.TrimSpace(t.Name)):
+			add(p+".name", "must be non-empty, trimmed and lowercase")
+		case tagNames[t.Name]:
+			add(p+".name", "duplicate name %q", t.Name)
+		default:
+			tagNames[t.Name] = true
+		}
 	}
-	type budgetBulk interface {
-		Reset()
-		BulkUpsert([]models.Budget)
+
+	payeeIDs := make(map[string]bool, len(payees))
+	payeeKeysSeen := map[string]bool{}
+	for i, py := range payees {
+		if !checkPayees {
+			payeeIDs[py.ID] = true
+			continue
+		}
+		p := fmt.Sprintf("payees[%d]", i)
+		switch {
+		case strings.TrimSpace(py.ID) == "":
+			add(p+".id", "is required")
+		case payeeIDs[py.ID]:
+			add(p+".id", "duplicate id %q", py.ID)
+		default:
+			payeeIDs[py.ID] = true
+		}
+		if strings.TrimSpace(py.Name) == "" {
+			add(p+".name", "is required")
+		}
+		for _, a := range py.Aliases {
+			if a == "" || a != NormalizePayee(a) {
+				add(p+".aliases", "alias %q is not normalized", a)
+			}
+		}
+		for _, k := range payeeKeys(py) {
+			if payeeKeysSeen[k] {
+				add(p, "name or alias %q is already used", k)
+			}
+			payeeKeysSeen[k] = true
+		}
 	}
-	type txnBulk interface {
-		Reset()
-		BulkUpsert([]models.Txn)
+
+	budgetIDs := map[string]bool{}
+	budgetKeys := map[string]bool{}
+	for i, b := range st.Budgets {
+		p := fmt.Sprintf("budgets[%d]", i)
+		switch {
+		case strings.TrimSpace(b.ID) == "":
+			add(p+".id", "is required")
+		case budgetIDs[b.ID]:
+			add(p+".id", "duplicate id %q", b.ID)
+		default:
+			budgetIDs[b.ID] = true
+		}
+		if !validate.MonthKey(b.Month) {
+			add(p+".month", "must be YYYY-MM")
+		}
+		if cat, ok := cats[b.CategoryID]; !ok {
+			add(p+".categoryId", "unknown category %q", b.CategoryID)
+		} else if cat.Type != models.CategoryExpense {
+			add(p+".categoryId", "budgets require an expense category")
+		}
+		if key := b.Month + "|" + b.CategoryID; budgetKeys[key] {
+			add(p, "duplicate budget for %s and category %q", b.Month, b.CategoryID)
+		} else {
+			budgetKeys[key] = true
+		}
+		if b.AmountCents < 0 {
+			add(p+".amountCents", "must not be negative")
+		}
+		checkAmount(p, b.Currency, b.AmountCents)
 	}
 
-	catR, ok1 := s.cats.(catBulk)
-	budgetR, ok2 := s.budgets.(budgetBulk)
-	txnR, ok3 := s.txns.(txnBulk)
-	if !ok1 || !ok2 || !ok3 {
-		return errs.ErrConflict
+	txnIDs := map[string]bool{}
+	extIDs := map[string]bool{}
+	transfers := map[string][]int{}
+	for i, t := range st.Transactions {
+		p := fmt.Sprintf("transactions[%d]", i)
+		switch {
+		case strings.TrimSpace(t.ID) == "":
+			add(p+".id", "is required")
+		case txnIDs[t.ID]:
+			add(p+".id", "duplicate id %q", t.ID)
+		default:
+			txnIDs[t.ID] = true
+		}
+		if !t.Kind.Valid() {
+			add(p+".kind", "must be income, expense or transfer")
+		}
+		if !validate.DateKey(t.Date) {
+			add(p+".date", "must be YYYY-MM-DD")
+		}
+		if t.Kind == models.KindTransfer {
+			if t.CategoryID != "" {
+				add(p+".categoryId", "transfers have no category")
+			}
+			if t.AccountID == "" {
+				add(p+".accountId", "is required for transfers")
+			}
+			if t.TransferDirection != models.TransferOut && t.TransferDirection != models.TransferIn {
+				add(p+".transferDirection", "must be out or in")
+			}
+			if t.TransferID == "" {
+				add(p+".transferId", "is required for transfers")
+			} else {
+				transfers[t.TransferID] = append(transfers[t.TransferID], i)
+			}
+		} else {
+			if cat, ok := cats[t.CategoryID]; !ok {
+				add(p+".categoryId", "unknown category %q", t.CategoryID)
+			} else if string(cat.Type) != string(t.Kind) {
+				add(p+".categoryId", "category type %s does not match kind %s", cat.Type, t.Kind)
+			}
+			if t.TransferID != "" || t.TransferDirection != "" {
+				add(p+".transferId", "only transfers may have a transfer id")
+			}
+		}
+		if t.AmountCents <= 0 {
+			add(p+".amountCents", "must be positive")
+		}
+		checkAmount(p, t.Currency, t.AmountCents)
+		if acct, ok := accts[t.AccountID]; t.AccountID != "" && !ok {
+			add(p+".accountId", "unknown account %q", t.AccountID)
+		} else if ok && models.CurrencyOrDefault(t.Currency) != models.CurrencyOrDefault(acct.Currency) {
+			add(p+".currency", "must match account currency %s", models.CurrencyOrDefault(acct.Currency))
+		}
+		if len(t.Splits) > 0 {
+			var sum int64
+			if t.Kind == models.KindTransfer || len(t.Splits) < 2 {
+				add(p+".splits", "only income and expenses may be split, into at least two lines")
+			}
+			for j, l := range t.Splits {
+				lp := fmt.Sprintf("%s.splits[%d]", p, j)
+				if cat, ok := cats[l.CategoryID]; !ok {
+					add(lp+".categoryId", "unknown category %q", l.CategoryID)
+				} else if string(cat.Type) != string(t.Kind) {
+					add(lp+".categoryId", "category type %s does not match kind %s", cat.Type, t.Kind)
+				}
+				if l.AmountCents <= 0 {
+					add(lp+".amountCents", "must be positive")
+				}
+				checkAmount(lp, t.Currency, l.AmountCents)
+				sum += l.AmountCents
+			}
+			if sum != t.AmountCents {
+				add(p+".splits", "lines add up to %d, not %d", sum, t.AmountCents)
+			} else if t.CategoryID != t.Splits[0].CategoryID {
+				add(p+".categoryId", "must be the first split line's category")
+			}
+		}
+		if t.PayeeID != "" {
+			if t.Kind == models.KindTransfer {
+				add(p+".payeeId", "transfers have no payee")
+			} else if !payeeIDs[t.PayeeID] {
+				add(p+".payeeId", "unknown payee %q", t.PayeeID)
+			}
+		}
+		seenTags := map[string]bool{}
+		for _, tagID := range t.TagIDs {
+			switch {
+			case !tagIDs[tagID]:
+				add(p+".tagIds", "unknown tag %q", tagID)
+			case seenTags[tagID]:
+				add(p+".tagIds", "duplicate tag %q", tagID)
+			}
+			seenTags[tagID] = true
+		}
+		if t.ExternalID != "" {
+			if extIDs[t.ExternalID] {
+				add(p+".externalId", "duplicate externalId %q", t.ExternalID)
+			}
+			extIDs[t.ExternalID] = true
+		}
 	}
 
-	_ = ctx // future: validate referential integrity using ctx
+	ruleIDs := map[string]bool{}
+	for i, r := range rules {
+		p := fmt.Sprintf("recurringRules[%d]", i)
+		if checkRules {
+			switch {
+			case strings.TrimSpace(r.ID) == "":
+				add(p+".id", "is required")
+			case ruleIDs[r.ID]:
+				add(p+".id", "duplicate id %q", r.ID)
+			default:
+				ruleIDs[r.ID] = true
+			}
+			if strings.TrimSpace(r.Name) == "" {
+				add(p+".name", "is required")
+			}
+			if r.Kind != models.KindIncome && r.Kind != models.KindExpense {
+				add(p+".kind", "must be income or expense")
+			}
+			if r.AmountCents <= 0 {
+				add(p+".amountCents", "must be positive")
+			}
+			checkAmount(p, r.Currency, r.AmountCents)
+			if !ValidRecurringSchedule(r) {
+				add(p+".frequency", "invalid schedule")
+			}
+		}
+		if cat, ok := cats[r.CategoryID]; !ok {
+			add(p+".categoryId", "recurring rule %q uses unknown category %q", r.ID, r.CategoryID)
+		} else if string(cat.Type) != string(r.Kind) {
+			add(p+".categoryId", "category type %s does not match kind %s", cat.Type, r.Kind)
+		}
+		if acct, ok := accts[r.AccountID]; r.AccountID != "" && !ok {
+			add(p+".accountId", "recurring rule %q uses unknown account %q", r.ID, r.AccountID)
+		} else if ok && models.CurrencyOrDefault(r.Currency) != models.CurrencyOrDefault(acct.Currency) {
+			add(p+".currency", "must match account currency %s", models.CurrencyOrDefault(acct.Currency))
+		}
+	}
 
-	catR.Reset()
-	budgetR.Reset()
-	txnR.Reset()
-	catR.BulkUpsert(st.Categories)
-	budgetR.BulkUpsert(st.Budgets)
-	txnR.BulkUpsert(st.Transactions)
-	return nil
+	// Each transfer needs exactly one leg out of and one into different accounts
+	// with the same currency, on the same date and for the same amount.
+	transferIDs := make([]string, 0, len(transfers))
+	for id := range transfers {
+		transferIDs = append(transferIDs, id)
+	}
+	sort.Strings(transferIDs)
+	for _, id := range transferIDs {
+		idx := transfers[id]
+		if len(idx) != 2 {
+			add(fmt.Sprintf("transactions[%d].transferId", idx[0]), "transfer %q needs exactly two legs, found %d", id, len(idx))
+			continue
+		}
+		a, b := st.Transactions[idx[0]], st.Transactions[idx[1]]
+		p := fmt.Sprintf("transactions[%d]", idx[1])
+		if a.TransferDirection == b.TransferDirection {
+			add(p+".transferDirection", "transfer %q needs one out and one in leg", id)
+		}
+		if a.Date != b.Date || a.AmountCents != b.AmountCents {
+			add(p, "transfer %q legs must share date and amount", id)
+		}
+		if a.AccountID == b.AccountID {
+			add(p+".accountId", "transfer %q must be between different accounts", id)
+		} else if accts[a.AccountID].Currency != accts[b.AccountID].Currency {
+			add(p+".accountId", "transfer %q accounts must share a currency", id)
+		}
+	}
+	return problems
 }
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/testutil"
+)
+
+func TestStateService_Replace(t *testing.T) {
+	ctx := context.Background()
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	budgetRepo := repositories.NewGormBudgetRepo(gdb)
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+	svc := NewStateService(repositories.NewGormTransactor(gdb), repositories.NewGormAccountRepo(gdb), catRepo, budgetRepo, txnRepo, repositories.NewGormRecurringRuleRepo(gdb), repositories.NewGormTagRepo(gdb), repositories.NewGormPayeeRepo(gdb))
+
+	_, _ = catRepo.Create(ctx, models.Category{ID: "old-cat", Type: models.CategoryExpense, Name: "Old"})
+	_, _ = txnRepo.Create(ctx, models.Txn{ID: "old-txn", Kind: models.KindExpense, Date: "2025-12-01", CategoryID: "old-cat", AmountCents: 1_00})
+
+	bad := models.AppStateV1{
+		Version: 1,
+		Categories: []models.Category{
+			{ID: "food", Type: models.CategoryExpense, Name: "Food"},
+			{ID: "salary", Type: models.CategoryIncome, Name: "Salary"},
+		},
+		Budgets: []models.Budget{
+			{ID: "b1", Month: "2026-13", CategoryID: "salary", AmountCents: 10_00},
+		},
+		Transactions: []models.Txn{
+			{ID: "t1", Kind: models.KindIncome, Date: "2026-01-05", CategoryID: "food", AmountCents: 10_00},
+			{ID: "t2", Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "missing", AmountCents: 0},
+		},
+	}
+	err := svc.Replace(ctx, bad)
+	var verr *errs.ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, errs.ErrValidation) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	// month, budget category type, txn kind mismatch, unknown category, non-positive amount
+	if len(verr.Problems) != 5 {
+		t.Fatalf("expected 5 problems, got %+v", verr.Problems)
+	}
+	st, _ := svc.Get(ctx)
+	if len(st.Categories) != 1 || len(st.Transactions) != 1 {
+		t.Fatalf("invalid replace must not touch stored data, got %+v", st)
+	}
+
+	good := models.AppStateV1{
+		Version:    1,
+		Categories: bad.Categories,
+		Budgets:    []models.Budget{{ID: "b1", Month: "2026-01", CategoryID: "food", AmountCents: 10_00}},
+		Transactions: []models.Txn{
+			{ID: "t1", Kind: models.KindIncome, Date: "2026-01-05", CategoryID: "salary", AmountCents: 10_00},
+		},
+	}
+	if err := svc.Replace(ctx, good); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	st, _ = svc.Get(ctx)
+	if len(st.Categories) != 2 || len(st.Budgets) != 1 || len(st.Transactions) != 1 || st.Transactions[0].ID != "t1" {
+		t.Fatalf("unexpected state after replace: %+v", st)
+	}
+}
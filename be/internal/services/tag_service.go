@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+type TagService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	tags repositories.TagRepository
+}
+
+func NewTagService(clk clock.Clock, ids id.Generator, tags repositories.TagRepository) *TagService {
+	return &TagService{clk: clk, ids: ids, tags: tags}
+}
+
+func (s *TagService) List(ctx context.Context) ([]models.Tag, error) {
+	return s.tags.List(ctx)
+}
+
+func (s *TagService) Get(ctx context.Context, id string) (models.Tag, error) {
+	return s.tags.Get(ctx, id)
+}
+
+type CreateTagInput struct {
+	Name string `json:"name"`
+}
+
+func (s *TagService) Create(ctx context.Context, in CreateTagInput) (models.Tag, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	return s.tags.Create(ctx, models.Tag{
+		ID:        s.ids.NewID(),
+		Name:      in.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+type UpdateTagInput struct {
+	Name *string `json:"name"`
+}
+
+func (s *TagService) Update(ctx context.Context, id string, in UpdateTagInput) (models.Tag, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	return s.tags.Update(ctx, id, repositories.TagPatch{Name: in.Name, UpdatedAt: &now})
+}
+
+func (s *TagService) Delete(ctx context.Context, id string) error {
+	return s.tags.Delete(ctx, id)
+}
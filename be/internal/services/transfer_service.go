@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/id"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// TransferService keeps the two transaction legs of a transfer in step: both are
+// created, edited and deleted together in one database transaction.
+type TransferService struct {
+	clk clock.Clock
+	ids id.Generator
+
+	tx   repositories.Transactor
+	txns repositories.TxnRepository
+}
+
+func NewTransferService(clk clock.Clock, ids id.Generator, tx repositories.Transactor, txns repositories.TxnRepository) *TransferService {
+	return &TransferService{clk: clk, ids: ids, tx: tx, txns: txns}
+}
+
+type CreateTransferInput struct {
+	FromAccountID string `json:"fromAccountId"`
+	ToAccountID   string `json:"toAccountId"`
+	Date          string `json:"date"`
+	AmountCents   int64  `json:"amountCents"`
+	Note          string `json:"note,omitempty"`
+	// Currency is the accounts' currency, filled in by the handler.
+	Currency string `json:"-"`
+}
+
+func (s *TransferService) Create(ctx context.Context, in CreateTransferInput) (models.Transfer, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	transferID := s.ids.NewID()
+	leg := func(accountID string, dir models.TransferDirection) models.Txn {
+		return models.Txn{
+			ID:                s.ids.NewID(),
+			Kind:              models.KindTransfer,
+			Date:              in.Date,
+			AccountID:         strings.TrimSpace(accountID),
+			AmountCents:       in.AmountCents,
+			Currency:          in.Currency,
+			Note:              strings.TrimSpace(in.Note),
+			TransferID:        transferID,
+			TransferDirection: dir,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+	}
+
+	var legs []models.Txn
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, t := range []models.Txn{leg(in.FromAccountID, models.TransferOut), leg(in.ToAccountID, models.TransferIn)} {
+			created, err := s.txns.Create(ctx, t)
+			if err != nil {
+				return err
+			}
+			legs = append(legs, created)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return toTransfer(legs)
+}
+
+func (s *TransferService) Get(ctx context.Context, transferID string) (models.Transfer, error) {
+	legs, err := s.txns.ListByTransfer(ctx, transferID)
+	if err != nil {
+		return models.Transfer{}, err
+	}
+	return toTransfer(legs)
+}
+
+type UpdateTransferInput struct {
+	FromAccountID *string `json:"fromAccountId"`
+	ToAccountID   *string `json:"toAccountId"`
+	Date          *string `json:"date"`
+	AmountCents   *int64  `json:"amountCents"`
+	Note          *string `json:"note"`
+	// Currency follows the accounts; set by the handler when they change it.
+	Currency *string `json:"-"`
+	// IfLegVersion, when set, fails the update with errs.ErrPreconditionFailed
+	// unless leg LegID is still at that version; set by the handler from the
+	// If-Match header of PATCH /transactions/:id.
+	LegID        string `json:"-"`
+	IfLegVersion int64  `json:"-"`
+}
+
+// Update applies shared fields (date, amount, note) to both legs and each
+// account to its own leg.
+func (s *TransferService) Update(ctx context.Context, transferID string, in UpdateTransferInput) (models.Transfer, error) {
+	now := s.clk.Now().Format(time.RFC3339)
+	var out models.Transfer
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		legs, err := s.txns.ListByTransfer(ctx, transferID)
+		if err != nil {
+			return err
+		}
+		if _, err := toTransfer(legs); err != nil {
+			return err
+		}
+		for i, t := range legs {
+			patch := repositories.TxnPatch{
+				Date:        in.Date,
+				AmountCents: in.AmountCents,
+				Currency:    in.Currency,
+				UpdatedAt:   &now,
+			}
+			if t.ID == in.LegID {
+				patch.IfVersion = in.IfLegVersion
+			}
+			if in.Note != nil {
+				trimmed := strings.TrimSpace(*in.Note)
+				patch.Note = &trimmed
+			}
+			account := in.ToAccountID
+			if t.TransferDirection == models.TransferOut {
+				account = in.FromAccountID
+			}
+			if account != nil {
+				trimmed := strings.TrimSpace(*account)
+				patch.AccountID = &trimmed
+			}
+			if legs[i], err = s.txns.Update(ctx, t.ID, patch); err != nil {
+				return err
+			}
+		}
+		out, err = toTransfer(legs)
+		return err
+	})
+	return out, err
+}
+
+func (s *TransferService) Delete(ctx context.Context, transferID string) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		legs, err := s.txns.ListByTransfer(ctx, transferID)
+		if err != nil {
+			return err
+		}
+		if len(legs) == 0 {
+			return errs.ErrNotFound
+		}
+		for _, t := range legs {
+			if err := s.txns.Delete(ctx, t.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// toTransfer assembles a transfer from its legs. Anything other than exactly one
+// outgoing and one incoming leg means the transfer does not exist.
+func toTransfer(legs []models.Txn) (models.Transfer, error) {
+	var out, in *models.Txn
+	for i := range legs {
+		switch legs[i].TransferDirection {
+		case models.TransferOut:
+			out = &legs[i]
+		case models.TransferIn:
+			in = &legs[i]
+		}
+	}
+	if len(legs) != 2 || out == nil || in == nil {
+		return models.Transfer{}, errs.ErrNotFound
+	}
+	return models.Transfer{
+		ID:            out.TransferID,
+		Date:          out.Date,
+		AmountCents:   out.AmountCents,
+		Currency:      out.Currency,
+		Note:          out.Note,
+		FromAccountID: out.AccountID,
+		ToAccountID:   in.AccountID,
+		OutTxnID:      out.ID,
+		InTxnID:       in.ID,
+	}, nil
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"personal-budgeting/be/internal/auth"
+	"personal-budgeting/be/internal/clock"
+	"personal-budgeting/be/internal/errs"
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+)
+
+// DefaultTrashRetention is how long deleted items stay restorable.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashService restores soft-deleted transactions, categories and budgets, and
+// purges them for good once they have been in the trash for the retention
+// period.
+type TrashService struct {
+	clk       clock.Clock
+	retention time.Duration
+
+	tx          repositories.Transactor
+	cats        repositories.CategoryRepository
+	budgets     repositories.BudgetRepository
+	txns        repositories.TxnRepository
+	attachments *AttachmentService
+}
+
+func NewTrashService(clk clock.Clock, retention time.Duration, tx repositories.Transactor, cats repositories.CategoryRepository, budgets repositories.BudgetRepository, txns repositories.TxnRepository, attachments *AttachmentService) *TrashService {
+	return &TrashService{clk: clk, retention: retention, tx: tx, cats: cats, budgets: budgets, txns: txns, attachments: attachments}
+}
+
+func (s *TrashService) List(ctx context.Context) (models.Trash, error) {
+	var out models.Trash
+	var err error
+	if out.Transactions, err = s.txns.ListDeleted(ctx); err != nil {
+		return models.Trash{}, err
+	}
+	if out.Categories, err = s.cats.ListDeleted(ctx); err != nil {
+		return models.Trash{}, err
+	}
+	if out.Budgets, err = s.budgets.ListDeleted(ctx); err != nil {
+		return models.Trash{}, err
+	}
+	return out, nil
+}
+
+// RestoreTxn takes a transaction out of the trash, with the other leg when it
+// is part of a transfer. It fails with errs.ErrConflict, leaving the
+// transaction in the trash, when its category or a split line's category is
+// deleted; restore that first.
+func (s *TrashService) RestoreTxn(ctx context.Context, id string) (models.Txn, error) {
+	var out models.Txn
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = s.txns.Restore(ctx, id); err != nil {
+			return err
+		}
+		if out.TransferID != "" {
+			return s.restoreLegs(ctx, out.TransferID)
+		}
+		ids := []string{out.CategoryID}
+		for _, sp := range out.Splits {
+			ids = append(ids, sp.CategoryID)
+		}
+		for _, catID := range ids {
+			if err := s.requireCategory(ctx, catID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return out, err
+}
+
+// restoreLegs restores whatever is still in the trash of a transfer.
+func (s *TrashService) restoreLegs(ctx context.Context, transferID string) error {
+	deleted, err := s.txns.ListDeleted(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range deleted {
+		if t.TransferID != transferID {
+			continue
+		}
+		if _, err := s.txns.Restore(ctx, t.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreCategory fails with errs.ErrConflict when the category's parent is
+// deleted.
+func (s *TrashService) RestoreCategory(ctx context.Context, id string) (models.Category, error) {
+	var out models.Category
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = s.cats.Restore(ctx, id); err != nil {
+			return err
+		}
+		if out.ParentID == "" {
+			return nil
+		}
+		return s.requireCategory(ctx, out.ParentID)
+	})
+	return out, err
+}
+
+// RestoreBudget fails with errs.ErrConflict when the budget's category is
+// deleted.
+func (s *TrashService) RestoreBudget(ctx context.Context, id string) (models.Budget, error) {
+	var out models.Budget
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if out, err = s.budgets.Restore(ctx, id); err != nil {
+			return err
+		}
+		return s.requireCategory(ctx, out.CategoryID)
+	})
+	return out, err
+}
+
+// requireCategory turns a category that is missing or in the trash into
+// errs.ErrConflict.
+func (s *TrashService) requireCategory(ctx context.Context, id string) error {
+	_, err := s.cats.Get(ctx, id)
+	if errors.Is(err, errs.ErrNotFound) {
+		return errs.ErrConflict
+	}
+	return err
+}
+
+// PurgeExpired permanently deletes, for every user, what has been in the trash
+// longer than the retention period, then prunes the attachments of purged
+// transactions. Categories still used by a row in the trash are kept until that
+// row is purged too.
+func (s *TrashService) PurgeExpired(ctx context.Context) (int, error) {
+	before := s.clk.Now().Add(-s.retention)
+	var owners []string
+	for _, list := range []func(context.Context, time.Time) ([]string, error){
+		s.txns.DeletedOwners, s.budgets.DeletedOwners, s.cats.DeletedOwners,
+	} {
+		uids, err := list(ctx, before)
+		if err != nil {
+			return 0, err
+		}
+		owners = append(owners, uids...)
+	}
+	slices.Sort(owners)
+	owners = slices.Compact(owners)
+
+	purged := 0
+	var errList []error
+	for _, uid := range owners {
+		n, err := s.purge(auth.WithUserID(ctx, uid), before)
+		purged += n
+		if err != nil {
+			errList = append(errList, err)
+		}
+	}
+	return purged, errors.Join(errList...)
+}
+
+// purge runs one user's purge. Transactions and budgets go first so that the
+// categories they used can follow in the same run.
+func (s *TrashService) purge(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		for _, del := range []func(context.Context, time.Time) (int, error){
+			s.txns.PurgeDeleted, s.budgets.PurgeDeleted, s.cats.PurgeDeleted,
+		} {
+			n, err := del(ctx, before)
+			if err != nil {
+				return err
+			}
+			purged += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	s.attachments.Prune(ctx)
+	return purged, nil
+}
+
+// Run calls PurgeExpired now and then every interval until ctx is cancelled. It
+// is meant to be started in its own goroutine by the API process.
+func (s *TrashService) Run(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if n, err := s.PurgeExpired(ctx); err != nil {
+			log.Printf("trash: %v", err)
+		} else if n > 0 {
+			log.Printf("trash: purged %d items", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"log"
 	"strings"
 	"time"
+	"unicode"
 
 	"personal-budgeting/be/internal/clock"
 	"personal-budgeting/be/internal/id"
@@ -14,32 +16,184 @@ import (
 type TxnService struct {
 	clk clock.Clock
 	ids id.Generator
+	tx  repositories.Transactor
 
 	txns repositories.TxnRepository
+
+	onNewMonth func(ctx context.Context, month string) error
+}
+
+func NewTxnService(clk clock.Clock, ids id.Generator, tx repositories.Transactor, txns repositories.TxnRepository) *TxnService {
+	return &TxnService{clk: clk, ids: ids, tx: tx, txns: txns}
 }
 
-func NewTxnService(clk clock.Clock, ids id.Generator, txns repositories.TxnRepository) *TxnService {
-	return &TxnService{clk: clk, ids: ids, txns: txns}
+// OnNewMonth registers fn to run when a month (YYYY-MM) gets its first income
+// or expense. fn runs in a savepoint, so when the transaction is created inside
+// a larger one (an import, a recurring run) a failing fn is logged and undone
+// without taking the rest down with it.
+func (s *TxnService) OnNewMonth(fn func(ctx context.Context, month string) error) {
+	s.onNewMonth = fn
 }
 
 func (s *TxnService) List(ctx context.Context) ([]models.Txn, error) {
 	return s.txns.List(ctx)
 }
 
+const (
+	DefaultTxnPageSize = 50
+	MaxTxnPageSize     = 500
+)
+
+// ListTxnInput is the query-string shape of GET /transactions.
+// Month (YYYY-MM) and From/To may be combined; the tighter bound wins.
+type ListTxnInput struct {
+	Month          string
+	From           string
+	To             string
+	Kind           models.TransactionKind
+	CategoryID     string
+	AccountID      string
+	PayeeID        string
+	MinAmountCents *int64
+	MaxAmountCents *int64
+	NoteContains   string
+	TagID          string
+	Sort           repositories.TxnSort
+	Cursor         string
+	Limit          int
+}
+
+func (s *TxnService) Query(ctx context.Context, in ListTxnInput) (repositories.TxnPage, error) {
+	q := repositories.TxnQuery{
+		TxnFilter: repositories.TxnFilter{
+			From:           in.From,
+			To:             in.To,
+			Kind:           in.Kind,
+			CategoryID:     strings.TrimSpace(in.CategoryID),
+			AccountID:      strings.TrimSpace(in.AccountID),
+			PayeeID:        strings.TrimSpace(in.PayeeID),
+			MinAmountCents: in.MinAmountCents,
+			MaxAmountCents: in.MaxAmountCents,
+			NoteContains:   strings.TrimSpace(in.NoteContains),
+			TagID:          strings.TrimSpace(in.TagID),
+		},
+		Sort:   in.Sort,
+		Cursor: in.Cursor,
+		Limit:  in.Limit,
+	}
+	if in.Month != "" {
+		start, end := monthBounds(in.Month)
+		if q.From < start {
+			q.From = start
+		}
+		if q.To == "" || q.To > end {
+			q.To = end
+		}
+	}
+	if q.Sort == "" {
+		q.Sort = repositories.TxnSortDateDesc
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultTxnPageSize
+	}
+	if q.Limit > MaxTxnPageSize {
+		q.Limit = MaxTxnPageSize
+	}
+	return s.txns.Query(ctx, q)
+}
+
+const (
+	DefaultTxnSearchSize = 20
+	MaxTxnSearchSize     = 100
+	maxTxnSearchTerms    = 8
+)
+
+// SearchTxnInput is the query-string shape of GET /transactions/search. Month
+// and From/To combine as in ListTxnInput.
+type SearchTxnInput struct {
+	Query string
+	Month string
+	From  string
+	To    string
+	Kind  models.TransactionKind
+	Limit int
+}
+
+func (s *TxnService) Search(ctx context.Context, in SearchTxnInput) (repositories.TxnSearchResult, error) {
+	q := repositories.TxnSearch{
+		TxnFilter: repositories.TxnFilter{From: in.From, To: in.To, Kind: in.Kind},
+		Terms:     SearchTerms(in.Query),
+		Limit:     in.Limit,
+	}
+	if in.Month != "" {
+		start, end := monthBounds(in.Month)
+		if q.From < start {
+			q.From = start
+		}
+		if q.To == "" || q.To > end {
+			q.To = end
+		}
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultTxnSearchSize
+	}
+	if q.Limit > MaxTxnSearchSize {
+		q.Limit = MaxTxnSearchSize
+	}
+	hits, err := s.txns.Search(ctx, q)
+	if err != nil {
+		return repositories.TxnSearchResult{}, err
+	}
+	return repositories.TxnSearchResult{Items: hits}, nil
+}
+
+// SearchTerms splits a search query into distinct lowercase words of letters and
+// digits, keeping the first few.
+func SearchTerms(query string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if seen[w] || len(out) == maxTxnSearchTerms {
+			continue
+		}
+		seen[w] = true
+		out = append(out, w)
+	}
+	return out
+}
+
 func (s *TxnService) Get(ctx context.Context, id string) (models.Txn, error) {
 	return s.txns.Get(ctx, id)
 }
 
+func (s *TxnService) ExistingExternalIDs(ctx context.Context, externalIDs []string) (map[string]bool, error) {
+	return s.txns.ExistingExternalIDs(ctx, externalIDs)
+}
+
 func (s *TxnService) CountByCategory(ctx context.Context, categoryID string) (int, error) {
 	return s.txns.CountByCategory(ctx, categoryID)
 }
 
+func (s *TxnService) CountByAccount(ctx context.Context, accountID string) (int, error) {
+	return s.txns.CountByAccount(ctx, accountID)
+}
+
 type CreateTxnInput struct {
 	Kind        models.TransactionKind `json:"kind"`
 	Date        string                 `json:"date"`
 	CategoryID  string                 `json:"categoryId"`
+	AccountID   string                 `json:"accountId,omitempty"`
+	PayeeID     string                 `json:"payeeId,omitempty"`
 	AmountCents int64                  `json:"amountCents"`
+	Currency    string                 `json:"currency,omitempty"`
 	Note        string                 `json:"note,omitempty"`
+	TagIDs      []string               `json:"tagIds,omitempty"`
+	Splits      []models.TxnSplit      `json:"splits,omitempty"`
+	// ExternalID is set by imports and the recurring job only; clients can't
+	// claim one.
+	ExternalID string `json:"-"`
 }
 
 func (s *TxnService) Create(ctx context.Context, in CreateTxnInput) (models.Txn, error) {
@@ -49,26 +203,74 @@ func (s *TxnService) Create(ctx context.Context, in CreateTxnInput) (models.Txn,
 		Kind:        in.Kind,
 		Date:        in.Date,
 		CategoryID:  strings.TrimSpace(in.CategoryID),
+		AccountID:   strings.TrimSpace(in.AccountID),
+		PayeeID:     strings.TrimSpace(in.PayeeID),
 		AmountCents: in.AmountCents,
+		Currency:    in.Currency,
 		Note:        strings.TrimSpace(in.Note),
+		ExternalID:  strings.TrimSpace(in.ExternalID),
+		TagIDs:      in.TagIDs,
+		Splits:      in.Splits,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
-	return s.txns.Create(ctx, t)
+	created, err := s.txns.Create(ctx, t)
+	if err != nil {
+		return models.Txn{}, err
+	}
+	if s.onNewMonth != nil && len(created.Date) >= 7 {
+		s.checkNewMonth(ctx, created.Date[:7])
+	}
+	return created, nil
+}
+
+// checkNewMonth runs the hook when month now holds exactly one income or
+// expense; transfer legs don't count.
+func (s *TxnService) checkNewMonth(ctx context.Context, month string) {
+	from, to := monthBounds(month)
+	err := s.tx.Savepoint(ctx, func(ctx context.Context) error {
+		n := 0
+		for _, kind := range []models.TransactionKind{models.KindIncome, models.KindExpense} {
+			page, err := s.txns.Query(ctx, repositories.TxnQuery{
+				TxnFilter: repositories.TxnFilter{From: from, To: to, Kind: kind},
+				Sort:      repositories.TxnSortDateAsc,
+				Limit:     2,
+			})
+			if err != nil {
+				return err
+			}
+			n += len(page.Items)
+		}
+		if n != 1 {
+			return nil
+		}
+		return s.onNewMonth(ctx, month)
+	})
+	if err != nil {
+		log.Printf("new month %s: %v", month, err)
+	}
 }
 
 type UpdateTxnInput struct {
 	Kind        *models.TransactionKind `json:"kind"`
 	Date        *string                 `json:"date"`
 	CategoryID  *string                 `json:"categoryId"`
+	AccountID   *string                 `json:"accountId"` // "" detaches
+	PayeeID     *string                 `json:"payeeId"`   // "" clears
 	AmountCents *int64                  `json:"amountCents"`
+	Currency    *string                 `json:"currency"`
 	Note        *string                 `json:"note"`
+	TagIDs      *[]string               `json:"tagIds"` // replaces all tags
+	Splits      *[]models.TxnSplit      `json:"splits"` // replaces all lines; empty un-splits
+	// IfVersion comes from the If-Match header; see repositories.TxnPatch.
+	IfVersion int64 `json:"-"`
 }
 
 func (s *TxnService) Update(ctx context.Context, id string, in UpdateTxnInput) (models.Txn, error) {
 	now := s.clk.Now().Format(time.RFC3339)
 	patch := repositories.TxnPatch{
 		UpdatedAt: &now,
+		IfVersion: in.IfVersion,
 	}
 	if in.Kind != nil {
 		patch.Kind = in.Kind
@@ -80,13 +282,26 @@ func (s *TxnService) Update(ctx context.Context, id string, in UpdateTxnInput) (
 		trimmed := strings.TrimSpace(*in.CategoryID)
 		patch.CategoryID = &trimmed
 	}
+	if in.AccountID != nil {
+		trimmed := strings.TrimSpace(*in.AccountID)
+		patch.AccountID = &trimmed
+	}
+	if in.PayeeID != nil {
+		trimmed := strings.TrimSpace(*in.PayeeID)
+		patch.PayeeID = &trimmed
+	}
 	if in.AmountCents != nil {
 		patch.AmountCents = in.AmountCents
 	}
+	if in.Currency != nil {
+		patch.Currency = in.Currency
+	}
 	if in.Note != nil {
 		trimmed := strings.TrimSpace(*in.Note)
 		patch.Note = &trimmed
 	}
+	patch.TagIDs = in.TagIDs
+	patch.Splits = in.Splits
 	return s.txns.Update(ctx, id, patch)
 }
 
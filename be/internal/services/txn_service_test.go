@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"personal-budgeting/be/internal/models"
+	"personal-budgeting/be/internal/repositories"
+	"personal-budgeting/be/internal/testutil"
+)
+
+func TestTxnService_Query_FiltersAndPaginates(t *testing.T) {
+	ctx := context.Background()
+	clk := testutil.FixedClock{T: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	ids := &testutil.SeqID{}
+
+	gdb := testutil.NewTestGormDB(t)
+	catRepo := repositories.NewGormCategoryRepo(gdb)
+	txnRepo := repositories.NewGormTxnRepo(gdb)
+
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-expense", Type: models.CategoryExpense, Name: "Groceries"})
+	_, _ = catRepo.Create(ctx, models.Category{ID: "cat-income", Type: models.CategoryIncome, Name: "Salary"})
+
+	svc := NewTxnService(clk, ids, repositories.NewGormTransactor(gdb), txnRepo)
+	for _, in := range []CreateTxnInput{
+		{Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "cat-expense", AmountCents: 100_00, Note: "Indomaret"},
+		{Kind: models.KindExpense, Date: "2026-01-05", CategoryID: "cat-expense", AmountCents: 250_00, Note: "Superindo"},
+		{Kind: models.KindExpense, Date: "2026-01-20", CategoryID: "cat-expense", AmountCents: 75_00, Note: "indomaret kemang"},
+		{Kind: models.KindIncome, Date: "2026-01-25", CategoryID: "cat-income", AmountCents: 5000_00},
+		{Kind: models.KindExpense, Date: "2026-02-01", CategoryID: "cat-expense", AmountCents: 30_00},
+	} {
+		if _, err := svc.Create(ctx, in); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	page, err := svc.Query(ctx, ListTxnInput{Month: "2026-01", Kind: models.KindExpense, NoteContains: "INDOMARET"})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(page.Items) != 2 || page.NextCursor != "" {
+		t.Fatalf("expected 2 items and no cursor, got %d (cursor %q)", len(page.Items), page.NextCursor)
+	}
+	if page.Items[0].Date != "2026-01-20" {
+		t.Fatalf("expected newest first, got %s", page.Items[0].Date)
+	}
+
+	var seen []string
+	in := ListTxnInput{Month: "2026-01", Sort: repositories.TxnSortDateDesc, Limit: 2}
+	for {
+		page, err := svc.Query(ctx, in)
+		if err != nil {
+			t.Fatalf("query page: %v", err)
+		}
+		for _, it := range page.Items {
+			seen = append(seen, it.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		in.Cursor = page.NextCursor
+	}
+	if len(seen) != 4 {
+		t.Fatalf("expected 4 January txns across pages, got %d", len(seen))
+	}
+	dup := map[string]bool{}
+	for _, id := range seen {
+		if dup[id] {
+			t.Fatalf("txn %s returned twice", id)
+		}
+		dup[id] = true
+	}
+
+	minAmt := int64(100_00)
+	page, err = svc.Query(ctx, ListTxnInput{MinAmountCents: &minAmt, Sort: repositories.TxnSortAmountAsc})
+	if err != nil {
+		t.Fatalf("query amount: %v", err)
+	}
+	if len(page.Items) != 3 || page.Items[0].AmountCents != 100_00 {
+		t.Fatalf("unexpected amount-filtered result: %+v", page.Items)
+	}
+
+	if _, err := svc.Query(ctx, ListTxnInput{Cursor: "not-a-cursor"}); err == nil {
+		t.Fatalf("expected invalid cursor to fail")
+	}
+}
@@ -1,6 +1,8 @@
 package testutil
 
 import (
+	"fmt"
+	"sync/atomic"
 	"testing"
 
 	"gorm.io/driver/sqlite"
@@ -9,10 +11,15 @@ import (
 	"personal-budgeting/be/internal/dbmodel"
 )
 
+var dbSeq int64
+
+// NewTestGormDB opens a fresh in-memory SQLite database per test.
+// Each call gets its own named shared-cache DB so tests don't see each other's rows.
 func NewTestGormDB(t *testing.T) *gorm.DB {
 	t.Helper()
 
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	dsn := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared", atomic.AddInt64(&dbSeq, 1))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("open sqlite: %v", err)
 	}
@@ -21,7 +28,10 @@ func NewTestGormDB(t *testing.T) *gorm.DB {
 	}
 	// Enable FK constraints in SQLite so behavior is closer to Postgres.
 	_ = db.Exec("PRAGMA foreign_keys = ON").Error
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
 	return db
 }
-
-
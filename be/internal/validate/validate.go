@@ -8,6 +8,7 @@ import (
 var (
 	monthRe = regexp.MustCompile(`^\d{4}-\d{2}$`)
 	dateRe  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	ccyRe   = regexp.MustCompile(`^[A-Z]{3}$`)
 )
 
 func MonthKey(v string) bool {
@@ -25,3 +26,8 @@ func DateKey(v string) bool {
 	_, err := time.Parse("2006-01-02", v)
 	return err == nil
 }
+
+// CurrencyCode reports whether v looks like an ISO 4217 code (three uppercase letters).
+func CurrencyCode(v string) bool {
+	return ccyRe.MatchString(v)
+}
@@ -0,0 +1,6 @@
+-- Indexes for filtered, keyset-paginated GET /api/v1/transactions
+
+CREATE INDEX IF NOT EXISTS transactions_date_id_idx ON transactions(date, id);
+CREATE INDEX IF NOT EXISTS transactions_amount_id_idx ON transactions(amount_cents, id);
+CREATE INDEX IF NOT EXISTS transactions_kind_date_idx ON transactions(kind, date);
+CREATE INDEX IF NOT EXISTS transactions_category_date_idx ON transactions(category_id, date);
@@ -0,0 +1,4 @@
+-- Source identifiers for imported transactions (e.g. OFX FITID), used to skip re-imports
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS external_id TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_external_id_uq ON transactions(external_id);
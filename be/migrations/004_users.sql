@@ -0,0 +1,36 @@
+-- User accounts, bearer-token sessions and per-user ownership of all data.
+-- Existing rows get user_id '' and are claimed by the first account that registers.
+
+CREATE TABLE IF NOT EXISTS users (
+  id TEXT PRIMARY KEY,
+  email TEXT NOT NULL,
+  password_hash TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email);
+
+CREATE TABLE IF NOT EXISTS sessions (
+  token_hash TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  expires_at TIMESTAMPTZ NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS user_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS user_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS user_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_categories_user_id ON categories(user_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets(user_id);
+
+-- Listing indexes now lead with the owner.
+DROP INDEX IF EXISTS transactions_date_id_idx;
+DROP INDEX IF EXISTS transactions_amount_id_idx;
+CREATE INDEX IF NOT EXISTS transactions_user_date_id_idx ON transactions(user_id, date, id);
+CREATE INDEX IF NOT EXISTS transactions_user_amount_id_idx ON transactions(user_id, amount_cents, id);
+
+-- Import de-duplication is per user.
+DROP INDEX IF EXISTS transactions_external_id_uq;
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_user_external_id_uq ON transactions(user_id, external_id);
@@ -0,0 +1,17 @@
+-- Accounts (wallets, bank accounts, cards) and the optional account on each transaction.
+
+CREATE TABLE IF NOT EXISTS accounts (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  type TEXT NOT NULL,
+  opening_balance_cents BIGINT NOT NULL DEFAULT 0,
+  currency TEXT NOT NULL DEFAULT 'IDR',
+  archived BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts(user_id);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS account_id TEXT REFERENCES accounts(id) ON DELETE RESTRICT;
+CREATE INDEX IF NOT EXISTS transactions_account_date_idx ON transactions(account_id, date);
@@ -0,0 +1,15 @@
+-- Transfers between accounts: a pair of transactions sharing transfer_id, one
+-- "out" and one "in". Transfer legs carry no category.
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_kind_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_kind_check CHECK (kind IN ('income', 'expense', 'transfer'));
+ALTER TABLE transactions ALTER COLUMN category_id DROP NOT NULL;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS transfer_id TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS transfer_direction TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id ON transactions(transfer_id);
+
+ALTER TABLE transactions ADD CONSTRAINT transactions_transfer_shape_check CHECK (
+  (kind = 'transfer' AND category_id IS NULL AND account_id IS NOT NULL AND transfer_id IS NOT NULL AND transfer_direction IN ('out', 'in'))
+  OR (kind <> 'transfer' AND category_id IS NOT NULL AND transfer_id IS NULL AND transfer_direction = '')
+);
@@ -0,0 +1,20 @@
+-- Multi-currency: every budget and transaction carries its currency (amounts stay
+-- in hundredths of a unit), and a per-user table of exchange rates lets reports
+-- convert into the home currency. Existing rows were all rupiah.
+
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'IDR';
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'IDR';
+
+-- One unit of base is worth rate units of quote from date onwards.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  base TEXT NOT NULL,
+  quote TEXT NOT NULL,
+  date TEXT NOT NULL,
+  rate DOUBLE PRECISION NOT NULL CHECK (rate > 0),
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS exchange_rates_pair_date_uq ON exchange_rates(user_id, base, quote, date);
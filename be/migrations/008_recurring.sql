@@ -0,0 +1,29 @@
+-- Recurring transaction rules. Occurrences become ordinary transactions with
+-- external_id 'recurring:<rule id>:<date>', which makes materializing idempotent.
+-- No foreign keys: PUT /state re-inserts categories and accounts, so the API
+-- validates these references instead.
+
+CREATE TABLE IF NOT EXISTS recurring_rules (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('income', 'expense')),
+  category_id TEXT NOT NULL,
+  account_id TEXT,
+  amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+  currency TEXT NOT NULL DEFAULT 'IDR',
+  note TEXT NOT NULL DEFAULT '',
+  frequency TEXT NOT NULL CHECK (frequency IN ('monthly', 'weekly', 'yearly', 'every_n_days', 'last_business_day')),
+  day_of_month INTEGER NOT NULL DEFAULT 0,
+  interval_days INTEGER NOT NULL DEFAULT 0,
+  start_date TEXT NOT NULL,
+  end_date TEXT NOT NULL DEFAULT '',
+  paused BOOLEAN NOT NULL DEFAULT FALSE,
+  last_run_date TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_rules_user_id ON recurring_rules(user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_rules_category_id ON recurring_rules(category_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_rules_account_id ON recurring_rules(account_id);
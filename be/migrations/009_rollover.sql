@@ -0,0 +1,4 @@
+-- Per-category budget rollover. The carried balance is derived from past budgets
+-- and spending when a report runs, so only the policy is stored.
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS rollover_policy TEXT NOT NULL DEFAULT 'none';
@@ -0,0 +1,23 @@
+-- Named budget templates. Lines have no foreign key to categories: PUT /state
+-- re-inserts categories and prunes lines whose category is gone instead.
+
+CREATE TABLE IF NOT EXISTS budget_templates (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  auto_apply BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS budget_templates_user_name_uq ON budget_templates(user_id, name);
+
+CREATE TABLE IF NOT EXISTS budget_template_lines (
+  template_id TEXT NOT NULL REFERENCES budget_templates(id) ON DELETE CASCADE,
+  category_id TEXT NOT NULL,
+  amount_cents BIGINT NOT NULL,
+  currency TEXT NOT NULL DEFAULT 'IDR',
+  PRIMARY KEY (template_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS budget_template_lines_category_idx ON budget_template_lines(category_id);
@@ -0,0 +1,6 @@
+-- Subcategories. No foreign key: PUT /state inserts categories in payload
+-- order, so the API checks the parent exists, has the same type and forms no cycle.
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id TEXT;
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories(parent_id);
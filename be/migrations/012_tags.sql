@@ -0,0 +1,19 @@
+-- Tags label transactions across categories. Names are stored lowercase.
+
+CREATE TABLE IF NOT EXISTS tags (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_user_name_uq ON tags(user_id, name);
+
+CREATE TABLE IF NOT EXISTS txn_tags (
+  txn_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  PRIMARY KEY (txn_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS txn_tags_tag_id_idx ON txn_tags(tag_id);
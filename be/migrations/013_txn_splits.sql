@@ -0,0 +1,14 @@
+-- Split transactions: lines that divide one transaction across categories.
+-- The lines add up to the transaction's amount, and its category_id mirrors the
+-- first line's, so unsplit reads stay correct.
+
+CREATE TABLE IF NOT EXISTS txn_splits (
+  txn_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+  amount_cents BIGINT NOT NULL,
+  note TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (txn_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS txn_splits_category_id_idx ON txn_splits(category_id);
@@ -0,0 +1,16 @@
+-- Attachments: receipt photos and invoices stored in the blob store under their
+-- id. There is no foreign key to transactions because PUT /state re-inserts them;
+-- the API prunes attachments of deleted transactions along with their files.
+
+CREATE TABLE IF NOT EXISTS attachments (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  txn_id TEXT NOT NULL,
+  file_name TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS attachments_user_id_idx ON attachments(user_id);
+CREATE INDEX IF NOT EXISTS attachments_txn_id_idx ON attachments(txn_id);
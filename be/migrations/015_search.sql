@@ -0,0 +1,6 @@
+-- Full-text search over transaction notes and category names. The expressions
+-- must match the ones GET /transactions/search queries with. 'simple' skips
+-- stemming and stop words, since notes mix languages.
+
+CREATE INDEX IF NOT EXISTS transactions_note_fts_idx ON transactions USING GIN (to_tsvector('simple', note));
+CREATE INDEX IF NOT EXISTS categories_name_fts_idx ON categories USING GIN (to_tsvector('simple', name));
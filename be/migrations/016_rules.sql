@@ -0,0 +1,26 @@
+-- Categorization rules, run in priority order over new and imported
+-- transactions. No foreign keys, like recurring_rules: actions pointing at a
+-- category or tag that is gone are skipped. tag_ids is a JSON array.
+
+CREATE TABLE IF NOT EXISTS rules (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  priority INTEGER NOT NULL DEFAULT 0,
+  paused BOOLEAN NOT NULL DEFAULT FALSE,
+  note_contains TEXT NOT NULL DEFAULT '',
+  note_regex TEXT NOT NULL DEFAULT '',
+  min_amount_cents BIGINT,
+  max_amount_cents BIGINT,
+  kind TEXT NOT NULL DEFAULT '' CHECK (kind IN ('', 'income', 'expense')),
+  account_id TEXT NOT NULL DEFAULT '',
+  category_id TEXT NOT NULL DEFAULT '',
+  tag_ids TEXT NOT NULL DEFAULT '[]',
+  set_note TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rules_user_id ON rules(user_id);
+CREATE INDEX IF NOT EXISTS idx_rules_account_id ON rules(account_id);
+CREATE INDEX IF NOT EXISTS idx_rules_category_id ON rules(category_id);
@@ -0,0 +1,18 @@
+-- Payees: the canonical merchant behind raw note text. aliases is a JSON array
+-- of normalized aliases; the API keeps them, and normalized names, unique per
+-- user. Deleting a payee leaves its transactions without one.
+
+CREATE TABLE IF NOT EXISTS payees (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  aliases TEXT NOT NULL DEFAULT '[]',
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_payees_user_id ON payees(user_id);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payee_id TEXT REFERENCES payees(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_payee_id ON transactions(payee_id);
@@ -0,0 +1,11 @@
+-- Soft delete: deleting a transaction, category or budget sets deleted_at and
+-- moves it to the trash, from where it can be restored. Every list and report
+-- skips trashed rows; a background job purges them after TRASH_RETENTION.
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE budgets ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_budgets_deleted_at ON budgets(deleted_at);
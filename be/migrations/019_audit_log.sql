@@ -0,0 +1,20 @@
+-- Audit log: an append-only entry for every create, update, delete and restore
+-- of a category, budget or transaction. before, after and diff are JSON; actor
+-- is the user, or the background job that made the change. Entries are never
+-- updated and outlive the rows they describe.
+
+CREATE TABLE IF NOT EXISTS audit_entries (
+  id BIGSERIAL PRIMARY KEY,
+  user_id TEXT NOT NULL DEFAULT '',
+  entity_type TEXT NOT NULL,
+  entity_id TEXT NOT NULL,
+  action TEXT NOT NULL,
+  actor TEXT NOT NULL DEFAULT '',
+  before TEXT,
+  after TEXT,
+  diff TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_entries_user_entity_idx ON audit_entries(user_id, entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_entries_user_created_idx ON audit_entries(user_id, created_at);
@@ -0,0 +1,6 @@
+-- Row versions for optimistic concurrency: every update of a transaction or
+-- category bumps version, which the API sends as the ETag. A PATCH with
+-- If-Match only applies while the row is still at that version.
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
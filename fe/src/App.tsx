@@ -1,6 +1,8 @@
 import { useMemo, useState } from 'react'
 import { getCurrentMonthKey, monthKeyToLabel } from './lib/month'
-import { useAppStore } from './store/AppStore'
+import { AppStoreProvider, useAppStore } from './store/AppStore'
+import { useSession } from './store/Session'
+import { Login } from './screens/Login'
 import { Dashboard } from './screens/Dashboard'
 import { Transactions } from './screens/Transactions'
 import { Budgets } from './screens/Budgets'
@@ -9,6 +11,19 @@ import { Categories } from './screens/Categories'
 type TabKey = 'dashboard' | 'transactions' | 'budgets' | 'categories'
 
 export default function App() {
+  const { session } = useSession()
+  if (!session) return <Login />
+
+  // Keyed by the token so that signing in as someone else starts from a fresh store.
+  return (
+    <AppStoreProvider key={session.token}>
+      <Workspace email={session.user.email} />
+    </AppStoreProvider>
+  )
+}
+
+function Workspace(props: { email: string }) {
+  const { signOut } = useSession()
   const { lastError, clearError } = useAppStore()
   const [tab, setTab] = useState<TabKey>('dashboard')
   const [month, setMonth] = useState(getCurrentMonthKey())
@@ -54,6 +69,10 @@ export default function App() {
 
         <div className="tabs__spacer" />
         <div className="tabs__meta">{title}</div>
+        <div className="tabs__meta">{props.email}</div>
+        <button className="btn btn--ghost" onClick={() => void signOut()}>
+          Sign out
+        </button>
       </nav>
 
       {lastError ? (
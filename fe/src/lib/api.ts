@@ -1,4 +1,5 @@
-import type { AppStateV1, Budget, Category, Id, Txn } from './types'
+import type { AppStateV1, Budget, Category, Id, Session, Txn, User } from './types'
+import { clearSession, loadSession } from './storage'
 
 type ApiError = {
   status: number
@@ -13,15 +14,35 @@ async function readJson<T>(res: Response): Promise<T> {
   return JSON.parse(text) as T
 }
 
+const unauthorizedListeners = new Set<() => void>()
+
+// onUnauthorized calls fn whenever the server rejects the saved session, after
+// it has been cleared. It returns a function that stops listening.
+export function onUnauthorized(fn: () => void): () => void {
+  unauthorizedListeners.add(fn)
+  return () => {
+    unauthorizedListeners.delete(fn)
+  }
+}
+
 async function request<T>(path: string, init?: RequestInit): Promise<T> {
+  const token = loadSession()?.token
   const res = await fetch(`${API_BASE}${path}`, {
     ...init,
     headers: {
       'Content-Type': 'application/json',
+      ...(token ? { Authorization: `Bearer ${token}` } : {}),
       ...(init?.headers ?? {}),
     },
   })
 
+  // A 401 on a request that carried a token means the session expired or was
+  // revoked; a failed login carries none and is left to the caller.
+  if (res.status === 401 && token) {
+    clearSession()
+    unauthorizedListeners.forEach((fn) => fn())
+  }
+
   if (!res.ok) {
     const body = await readJson<{ error?: string }>(res).catch(() => ({}) as { error?: string })
     const code = body.error ?? `http_${res.status}`
@@ -30,6 +51,18 @@ async function request<T>(path: string, init?: RequestInit): Promise<T> {
   return await readJson<T>(res)
 }
 
+export function register(input: { email: string; password: string }): Promise<User> {
+  return request<User>('/api/v1/auth/register', { method: 'POST', body: JSON.stringify(input) })
+}
+
+export function login(input: { email: string; password: string }): Promise<Session> {
+  return request<Session>('/api/v1/auth/login', { method: 'POST', body: JSON.stringify(input) })
+}
+
+export async function logout(): Promise<void> {
+  await request<unknown>('/api/v1/auth/logout', { method: 'POST' })
+}
+
 export function getState(): Promise<AppStateV1> {
   return request<AppStateV1>('/api/v1/state')
 }
@@ -85,6 +118,8 @@ export function prettyApiError(e: unknown): string {
       return 'Conflict. This item is in use or violates a rule.'
     case 'not_found':
       return 'Not found. It may have been deleted already.'
+    case 'unauthorized':
+      return 'Your session has ended. Please sign in again.'
     default:
       return 'Request failed. Please try again.'
   }
@@ -1,12 +1,16 @@
 import type { Cents } from './types'
 
-export function formatCents(cents: Cents): string {
+// Currencies without a usable minor unit; amounts are still stored in hundredths.
+const ZERO_DECIMAL = new Set(['IDR', 'JPY', 'KRW'])
+
+export function formatCents(cents: Cents, currency = 'IDR'): string {
   const amount = cents / 100
+  const digits = ZERO_DECIMAL.has(currency) ? 0 : 2
   return amount.toLocaleString('id-ID', {
     style: 'currency',
-    currency: 'IDR',
-    maximumFractionDigits: 2,
-    minimumFractionDigits: 2,
+    currency,
+    maximumFractionDigits: digits,
+    minimumFractionDigits: digits,
   })
 }
 
@@ -1,4 +1,4 @@
-import type { AppStateV1 } from './types'
+import type { AppStateV1, Session } from './types'
 
 const STORAGE_KEY = 'pb.appState.v1'
 
@@ -24,4 +24,24 @@ export function saveState(state: AppStateV1): void {
   localStorage.setItem(STORAGE_KEY, JSON.stringify(state))
 }
 
+const SESSION_KEY = 'pb.session.v1'
 
+export function loadSession(): Session | null {
+  try {
+    const raw = localStorage.getItem(SESSION_KEY)
+    if (!raw) return null
+    const session = JSON.parse(raw) as Session
+    if (!session || typeof session.token !== 'string' || !session.token) return null
+    return session
+  } catch {
+    return null
+  }
+}
+
+export function saveSession(session: Session): void {
+  localStorage.setItem(SESSION_KEY, JSON.stringify(session))
+}
+
+export function clearSession(): void {
+  localStorage.removeItem(SESSION_KEY)
+}
@@ -20,6 +20,9 @@ export type Category = {
   type: CategoryType
   name: string
   description?: string
+  parentId?: Id
+  rolloverPolicy?: 'none' | 'carry_positive' | 'carry_both'
+  version?: number // the server's ETag; send it back as If-Match
   createdAt: string
   updatedAt: string
 }
@@ -29,6 +32,7 @@ export type Budget = {
   month: MonthKey
   categoryId: Id // must be expense category
   amountCents: Cents
+  currency?: string // ISO 4217, defaults to IDR
   createdAt: string
   updatedAt: string
 }
@@ -39,9 +43,32 @@ export type Txn = {
   date: DateKey
   categoryId: Id // must match kind
   amountCents: Cents
+  currency?: string // ISO 4217, defaults to IDR
   note?: string
+  payeeId?: Id
+  tagIds?: Id[]
+  splits?: TxnSplit[] // lines add up to amountCents; categoryId is the first line's
+  version?: number // the server's ETag; send it back as If-Match
   createdAt: string
   updatedAt: string
 }
 
+export type TxnSplit = {
+  categoryId: Id // must match the transaction's kind
+  amountCents: Cents
+  note?: string
+}
+
+
 
+export type User = {
+  id: Id
+  email: string
+  createdAt: string
+}
+
+export type Session = {
+  token: string
+  expiresAt: string
+  user: User
+}
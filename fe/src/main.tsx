@@ -2,12 +2,12 @@ import { StrictMode } from 'react'
 import { createRoot } from 'react-dom/client'
 import './index.css'
 import App from './App.tsx'
-import { AppStoreProvider } from './store/AppStore.tsx'
+import { SessionProvider } from './store/Session.tsx'
 
 createRoot(document.getElementById('root')!).render(
   <StrictMode>
-    <AppStoreProvider>
+    <SessionProvider>
       <App />
-    </AppStoreProvider>
+    </SessionProvider>
   </StrictMode>,
 )
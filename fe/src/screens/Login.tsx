@@ -0,0 +1,108 @@
+import { useState } from 'react'
+import { prettyApiError } from '../lib/api'
+import { useSession } from '../store/Session'
+
+type Mode = 'login' | 'register'
+
+export function Login() {
+  const { signIn, signUp } = useSession()
+
+  const [mode, setMode] = useState<Mode>('login')
+  const [email, setEmail] = useState('')
+  const [password, setPassword] = useState('')
+  const [busy, setBusy] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+
+  const submit = async () => {
+    setBusy(true)
+    setError(null)
+    try {
+      if (mode === 'login') await signIn({ email, password })
+      else await signUp({ email, password })
+    } catch (e) {
+      setError(authErrorMessage(mode, e))
+    } finally {
+      setBusy(false)
+    }
+  }
+
+  return (
+    <div className="app app--narrow">
+      <header className="topbar">
+        <div className="brand">
+          <div className="brand__name">Personal Budget</div>
+        </div>
+      </header>
+
+      <main className="main">
+        <section className="card">
+          <div className="card__header">
+            <div className="card__title">{mode === 'login' ? 'Sign in' : 'Create account'}</div>
+            <div className="card__hint">
+              {mode === 'login' ? 'Your budgets are kept per account.' : 'Passwords need at least 8 characters.'}
+            </div>
+          </div>
+
+          {error ? <div className="inlineAlert">{error}</div> : null}
+
+          <form
+            className="form form--single"
+            onSubmit={(e) => {
+              e.preventDefault()
+              void submit()
+            }}
+          >
+            <label className="field">
+              <div className="field__label">Email</div>
+              <input
+                className="input"
+                type="email"
+                autoComplete="email"
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+                required
+              />
+            </label>
+
+            <label className="field">
+              <div className="field__label">Password</div>
+              <input
+                className="input"
+                type="password"
+                autoComplete={mode === 'login' ? 'current-password' : 'new-password'}
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+                minLength={mode === 'register' ? 8 : undefined}
+                required
+              />
+            </label>
+
+            <div className="form__actions">
+              <button
+                type="button"
+                className="btn btn--ghost"
+                onClick={() => {
+                  setMode(mode === 'login' ? 'register' : 'login')
+                  setError(null)
+                }}
+              >
+                {mode === 'login' ? 'Create an account' : 'I have an account'}
+              </button>
+              <button type="submit" className="btn" disabled={busy}>
+                {mode === 'login' ? 'Sign in' : 'Create account'}
+              </button>
+            </div>
+          </form>
+        </section>
+      </main>
+    </div>
+  )
+}
+
+function authErrorMessage(mode: Mode, e: unknown): string {
+  const code = (e as { code?: string }).code
+  if (mode === 'login' && code === 'unauthorized') return 'Wrong email or password.'
+  if (mode === 'register' && code === 'conflict') return 'That email is already registered.'
+  if (mode === 'register' && code === 'validation') return 'Enter an email and a password of at least 8 characters.'
+  return prettyApiError(e)
+}
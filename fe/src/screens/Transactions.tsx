@@ -163,7 +163,7 @@ export function Transactions(props: { month: string }) {
                         {t.kind === 'income' ? 'Income' : 'Expense'}
                       </span>
                       <span className="mono">{t.date}</span>
-                      <span className="row__amount">{formatCents(t.amountCents)}</span>
+                      <span className="row__amount">{formatCents(t.amountCents, t.currency)}</span>
                     </div>
                     <div className="row__sub">
                       {cat ? cat.name : 'Unknown category'}
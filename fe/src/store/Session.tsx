@@ -0,0 +1,53 @@
+import React, { createContext, useContext, useEffect, useMemo, useState } from 'react'
+import type { Session } from '../lib/types'
+import * as apiClient from '../lib/api'
+import { clearSession, loadSession, saveSession } from '../lib/storage'
+
+type SessionStore = {
+  session: Session | null
+  signIn: (input: { email: string; password: string }) => Promise<void>
+  signUp: (input: { email: string; password: string }) => Promise<void>
+  signOut: () => Promise<void>
+}
+
+const Ctx = createContext<SessionStore | null>(null)
+
+export function SessionProvider(props: { children: React.ReactNode }) {
+  const [session, setSession] = useState<Session | null>(() => loadSession())
+
+  // The API client clears the saved session on a 401; follow it back to login.
+  useEffect(() => apiClient.onUnauthorized(() => setSession(null)), [])
+
+  const store: SessionStore = useMemo(() => {
+    const signIn: SessionStore['signIn'] = async (input) => {
+      const next = await apiClient.login(input)
+      saveSession(next)
+      setSession(next)
+    }
+
+    const signUp: SessionStore['signUp'] = async (input) => {
+      await apiClient.register(input)
+      await signIn(input)
+    }
+
+    const signOut: SessionStore['signOut'] = async () => {
+      try {
+        await apiClient.logout()
+      } catch {
+        // The session is dropped locally either way.
+      }
+      clearSession()
+      setSession(null)
+    }
+
+    return { session, signIn, signUp, signOut }
+  }, [session])
+
+  return <Ctx.Provider value={store}>{props.children}</Ctx.Provider>
+}
+
+export function useSession(): SessionStore {
+  const ctx = useContext(Ctx)
+  if (!ctx) throw new Error('useSession must be used within SessionProvider.')
+  return ctx
+}